@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
 	"os"
 
@@ -19,6 +20,12 @@ func main() {
 	}
 
 	if err := cmd.Run(program); err != nil {
+		var exitCodeError *cmd.ExitCodeError
+		if errors.As(err, &exitCodeError) {
+			slog.Info(exitCodeError.Error())
+			os.Exit(exitCodeError.Code)
+		}
+
 		slog.Error(err.Error())
 		os.Exit(1)
 	}
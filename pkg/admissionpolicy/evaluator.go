@@ -0,0 +1,428 @@
+// Package admissionpolicy evaluates ValidatingAdmissionPolicies against Kubernetes objects without a cluster.
+// Policies are compiled and evaluated with the same CEL environment as the API server, so the policies behave
+// the same at build time and in the cluster.
+package admissionpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/util"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	plugincel "k8s.io/apiserver/pkg/admission/plugin/cel"
+	"k8s.io/apiserver/pkg/admission/plugin/policy/validating"
+	"k8s.io/apiserver/pkg/admission/plugin/webhook/matchconditions"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/cel/environment"
+)
+
+const (
+	apiGroup                 = "admissionregistration.k8s.io"
+	policyKind               = "ValidatingAdmissionPolicy"
+	bindingKind              = "ValidatingAdmissionPolicyBinding"
+	namespaceNameLabel       = "kubernetes.io/metadata.name"
+	defaultValidationActions = admissionregistrationv1.Deny
+)
+
+// Object is a Kubernetes object that is evaluated against the policies.
+type Object struct {
+	// Content of the object, e.g. a document unmarshaled from YAML.
+	Content map[string]any
+	// True if the kind of the object is namespaced.
+	IsNamespaced bool
+}
+
+// Returns the objects with the given API version and kind. Used to find the parameters of the policies and
+// the namespaces of the evaluated objects.
+type ObjectLookup func(apiVersion string, kind string) []map[string]any
+
+// Violation is a validation of a policy that failed for an object.
+type Violation struct {
+	Policy  string
+	Binding string
+	Message string
+	// Reason of the failure, e.g. "Invalid" or "Forbidden".
+	Reason string
+	// Validation actions of the binding, e.g. "Deny" or "Warn".
+	ValidationActions []string
+}
+
+// Evaluator evaluates the added policies and bindings against objects.
+type Evaluator struct {
+	policies map[string]*policy
+	bindings []*admissionregistrationv1.ValidatingAdmissionPolicyBinding
+}
+
+type policy struct {
+	definition *admissionregistrationv1.ValidatingAdmissionPolicy
+	validator  validating.Validator
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{
+		policies: map[string]*policy{},
+	}
+}
+
+// Returns true if the given object is a ValidatingAdmissionPolicy or a ValidatingAdmissionPolicyBinding.
+func IsPolicyObject(object map[string]any) bool {
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+
+	return strings.HasPrefix(apiVersion, apiGroup+"/") && (kind == policyKind || kind == bindingKind)
+}
+
+// Adds the given ValidatingAdmissionPolicy or ValidatingAdmissionPolicyBinding. Other objects are ignored.
+func (evaluator *Evaluator) Add(object map[string]any) error {
+	if !IsPolicyObject(object) {
+		return nil
+	}
+
+	switch object["kind"] {
+	case policyKind:
+		definition := &admissionregistrationv1.ValidatingAdmissionPolicy{}
+		if err := util.ConvertWithJson(object, definition); err != nil {
+			return fmt.Errorf("can't parse ValidatingAdmissionPolicy: %w", err)
+		}
+
+		evaluator.policies[definition.Name] = &policy{
+			definition: definition,
+		}
+	case bindingKind:
+		binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{}
+		if err := util.ConvertWithJson(object, binding); err != nil {
+			return fmt.Errorf("can't parse ValidatingAdmissionPolicyBinding: %w", err)
+		}
+
+		evaluator.bindings = append(evaluator.bindings, binding)
+	}
+
+	return nil
+}
+
+// Returns true if there is at least one binding, i.e. if evaluating the objects can cause violations.
+func (evaluator *Evaluator) HasBindings() bool {
+	return len(evaluator.bindings) > 0
+}
+
+// Evaluates the bound policies against the given object as if the object is created in the cluster.
+func (evaluator *Evaluator) Evaluate(object *Object, lookup ObjectLookup) ([]*Violation, error) {
+	request, err := newRequest(object, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []*Violation{}
+
+	for _, binding := range evaluator.bindings {
+		policy := evaluator.policies[binding.Spec.PolicyName]
+
+		// API server ignores the bindings of the missing policies.
+		if policy == nil {
+			continue
+		}
+
+		if !request.matches(policy.definition.Spec.MatchConstraints) || !request.matches(binding.Spec.MatchResources) {
+			continue
+		}
+
+		newViolation := func(message string, reason string) *Violation {
+			actions := []string{}
+			for _, action := range binding.Spec.ValidationActions {
+				actions = append(actions, string(action))
+			}
+
+			if len(actions) == 0 {
+				actions = append(actions, string(defaultValidationActions))
+			}
+
+			return &Violation{
+				Policy:            policy.definition.Name,
+				Binding:           binding.Name,
+				Message:           message,
+				Reason:            reason,
+				ValidationActions: actions,
+			}
+		}
+
+		params, err := request.getParams(policy.definition, binding, lookup)
+		if err != nil {
+			if isFailurePolicyIgnore(policy.definition) {
+				continue
+			}
+
+			violations = append(violations, newViolation(err.Error(), string(admissionregistrationv1.Fail)))
+			continue
+		}
+
+		validator, err := policy.getValidator()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, param := range params {
+			result := validator.Validate(
+				context.Background(),
+				request.resource,
+				request.attributes,
+				param,
+				request.namespaceObject,
+				celconfig.RuntimeCELCostBudget,
+				nil)
+
+			for _, decision := range result.Decisions {
+				if decision.Action != validating.ActionDeny {
+					continue
+				}
+
+				message := decision.Message
+				if decision.Evaluation == validating.EvalError {
+					message = fmt.Sprintf("policy evaluation failed: %s", message)
+				}
+
+				violations = append(violations, newViolation(message, string(decision.Reason)))
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// Compiles the policy the same way as the API server on the first use.
+func (policy *policy) getValidator() (validating.Validator, error) {
+	if policy.validator != nil {
+		return policy.validator, nil
+	}
+
+	spec := policy.definition.Spec
+	hasParams := spec.ParamKind != nil
+
+	// Authorizer is not available offline, policies that use it fail to compile.
+	optionalVars := plugincel.OptionalVariableDeclarations{HasParams: hasParams, HasAuthorizer: false, StrictCost: true}
+
+	compositionEnv, err := plugincel.NewCompositionEnv(
+		plugincel.VariablesTypeName, environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion(), true))
+	if err != nil {
+		return nil, fmt.Errorf("can't create CEL environment for policy %s: %w", policy.definition.Name, err)
+	}
+
+	compiler := plugincel.NewCompositedCompilerFromTemplate(compositionEnv)
+
+	variables := []plugincel.NamedExpressionAccessor{}
+	for _, variable := range spec.Variables {
+		variables = append(variables, &validating.Variable{Name: variable.Name, Expression: variable.Expression})
+	}
+
+	compiler.CompileAndStoreVariables(variables, optionalVars, environment.StoredExpressions)
+
+	var matcher matchconditions.Matcher
+	if len(spec.MatchConditions) > 0 {
+		conditions := []plugincel.ExpressionAccessor{}
+		for i := range spec.MatchConditions {
+			conditions = append(conditions, (*matchconditions.MatchCondition)(&spec.MatchConditions[i]))
+		}
+
+		matcher = matchconditions.NewMatcher(
+			compiler.CompileCondition(conditions, optionalVars, environment.StoredExpressions),
+			spec.FailurePolicy,
+			"policy",
+			"validate",
+			policy.definition.Name)
+	}
+
+	validations := []plugincel.ExpressionAccessor{}
+	messageExpressions := []plugincel.ExpressionAccessor{}
+
+	for _, validation := range spec.Validations {
+		validations = append(validations, &validating.ValidationCondition{
+			Expression: validation.Expression,
+			Message:    validation.Message,
+			Reason:     validation.Reason,
+		})
+
+		var messageExpression plugincel.ExpressionAccessor
+		if validation.MessageExpression != "" {
+			messageExpression = &validating.MessageExpressionCondition{MessageExpression: validation.MessageExpression}
+		}
+
+		messageExpressions = append(messageExpressions, messageExpression)
+	}
+
+	policy.validator = validating.NewValidator(
+		compiler.CompileCondition(validations, optionalVars, environment.StoredExpressions),
+		matcher,
+		compiler.CompileCondition(nil, optionalVars, environment.StoredExpressions),
+		compiler.CompileCondition(messageExpressions, optionalVars, environment.StoredExpressions),
+		spec.FailurePolicy)
+
+	return policy.validator, nil
+}
+
+func isFailurePolicyIgnore(definition *admissionregistrationv1.ValidatingAdmissionPolicy) bool {
+	failurePolicy := definition.Spec.FailurePolicy
+	return failurePolicy != nil && *failurePolicy == admissionregistrationv1.Ignore
+}
+
+// Request contains the admission attributes of an object that is created in the cluster.
+type request struct {
+	object     *unstructured.Unstructured
+	kind       schema.GroupVersionKind
+	resource   schema.GroupVersionResource
+	attributes *admission.VersionedAttributes
+	// Namespace of the request, empty for cluster scoped objects.
+	namespace       string
+	namespaceObject *corev1.Namespace
+}
+
+func newRequest(object *Object, lookup ObjectLookup) (*request, error) {
+	content := map[string]any{}
+	if err := util.ConvertWithJson(object.Content, &content); err != nil {
+		return nil, fmt.Errorf("can't convert object for policy evaluation: %w", err)
+	}
+
+	unstructuredObject := &unstructured.Unstructured{Object: content}
+
+	kind := unstructuredObject.GroupVersionKind()
+	resource, _ := meta.UnsafeGuessKindToResource(kind)
+
+	// Namespace objects have their own names as the namespace of the request. Namespaced objects without
+	// a namespace are assumed to be created in the default namespace.
+	namespaceName := ""
+	if resource == namespaceResource {
+		namespaceName = unstructuredObject.GetName()
+	} else if object.IsNamespaced {
+		namespaceName = unstructuredObject.GetNamespace()
+		if namespaceName == "" {
+			namespaceName = metav1.NamespaceDefault
+		}
+	}
+
+	request := &request{
+		object:    unstructuredObject,
+		kind:      kind,
+		resource:  resource,
+		namespace: namespaceName,
+	}
+
+	attributes := admission.NewAttributesRecord(
+		unstructuredObject,
+		nil,
+		kind,
+		namespaceName,
+		unstructuredObject.GetName(),
+		resource,
+		"",
+		admission.Create,
+		nil,
+		false,
+		&user.DefaultInfo{})
+
+	request.attributes = &admission.VersionedAttributes{
+		Attributes:      attributes,
+		VersionedObject: unstructuredObject,
+		VersionedKind:   kind,
+	}
+
+	if object.IsNamespaced {
+		request.namespaceObject = getNamespace(namespaceName, lookup)
+	}
+
+	return request, nil
+}
+
+// Returns the namespace of the object from the objects in the build. Namespaces that are not in the build
+// only have the name label that the API server sets on all namespaces.
+func getNamespace(name string, lookup ObjectLookup) *corev1.Namespace {
+	for _, object := range lookup("v1", "Namespace") {
+		namespace := &corev1.Namespace{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, namespace); err != nil {
+			continue
+		}
+
+		if namespace.Name != name {
+			continue
+		}
+
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+
+		namespace.Labels[namespaceNameLabel] = name
+
+		return namespace
+	}
+
+	namespace := &corev1.Namespace{}
+	namespace.Name = name
+	namespace.Labels = map[string]string{namespaceNameLabel: name}
+
+	return namespace
+}
+
+// Returns the parameters of the policy for the binding. Returns a single nil parameter if the policy doesn't
+// have parameters.
+func (request *request) getParams(
+	definition *admissionregistrationv1.ValidatingAdmissionPolicy,
+	binding *admissionregistrationv1.ValidatingAdmissionPolicyBinding,
+	lookup ObjectLookup,
+) ([]runtime.Object, error) {
+	paramKind := definition.Spec.ParamKind
+	if paramKind == nil {
+		return []runtime.Object{nil}, nil
+	}
+
+	paramRef := binding.Spec.ParamRef
+	if paramRef == nil {
+		return nil, fmt.Errorf("policy %s has a paramKind but binding %s doesn't have a paramRef", definition.Name, binding.Name)
+	}
+
+	namespace := paramRef.Namespace
+	if namespace == "" {
+		namespace = request.namespace
+	}
+
+	params := []runtime.Object{}
+
+	for _, object := range lookup(paramKind.APIVersion, paramKind.Kind) {
+		param := &unstructured.Unstructured{}
+		if err := util.ConvertWithJson(object, &param.Object); err != nil {
+			continue
+		}
+
+		// Cluster scoped parameters don't have a namespace.
+		if param.GetNamespace() != "" && param.GetNamespace() != namespace {
+			continue
+		}
+
+		if paramRef.Name != "" {
+			if param.GetName() == paramRef.Name {
+				params = append(params, param)
+			}
+
+			continue
+		}
+
+		if matchesLabelSelector(paramRef.Selector, param.GetLabels()) {
+			params = append(params, param)
+		}
+	}
+
+	if len(params) > 0 {
+		return params, nil
+	}
+
+	if paramRef.ParameterNotFoundAction != nil && *paramRef.ParameterNotFoundAction == admissionregistrationv1.AllowAction {
+		return []runtime.Object{}, nil
+	}
+
+	return nil, fmt.Errorf("no params found for policy binding %s with Deny parameterNotFoundAction", binding.Name)
+}
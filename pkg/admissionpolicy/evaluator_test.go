@@ -0,0 +1,228 @@
+package admissionpolicy
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const policies = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: replica-limit
+spec:
+  failurePolicy: Fail
+  paramKind:
+    apiVersion: v1
+    kind: ConfigMap
+  matchConstraints:
+    resourceRules:
+      - apiGroups: ["apps"]
+        apiVersions: ["v1"]
+        operations: ["CREATE", "UPDATE"]
+        resources: ["deployments"]
+  validations:
+    - expression: object.spec.replicas <= int(params.data.maxReplicas)
+      messageExpression: "'replicas must be at most ' + params.data.maxReplicas"
+      reason: Invalid
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: replica-limit-production
+spec:
+  policyName: replica-limit
+  validationActions: ["Deny"]
+  paramRef:
+    name: replica-limit
+    namespace: policies
+  matchResources:
+    namespaceSelector:
+      matchLabels:
+        environment: production
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: require-team-label
+spec:
+  matchConstraints:
+    resourceRules:
+      - apiGroups: ["*"]
+        apiVersions: ["*"]
+        operations: ["*"]
+        resources: ["*"]
+  matchConditions:
+    - name: exclude-namespaces
+      expression: request.kind.kind != 'Namespace'
+  variables:
+    - name: labels
+      expression: "has(object.metadata.labels) ? object.metadata.labels : {}"
+  validations:
+    - expression: "'team' in variables.labels"
+      message: team label is required
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: require-team-label
+spec:
+  policyName: require-team-label
+  validationActions: ["Warn"]
+`
+
+const objects = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: replica-limit
+  namespace: policies
+  labels:
+    team: platform
+data:
+  maxReplicas: "3"
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: production
+  labels:
+    environment: production
+`
+
+func TestEvaluate(t *testing.T) {
+	evaluator := NewEvaluator()
+	for _, policy := range parseObjects(t, policies) {
+		if err := evaluator.Add(policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lookupObjects := parseObjects(t, objects)
+	lookup := func(apiVersion string, kind string) []map[string]any {
+		result := []map[string]any{}
+		for _, object := range lookupObjects {
+			if object["apiVersion"] == apiVersion && object["kind"] == kind {
+				result = append(result, object)
+			}
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		object   string
+		expected []Violation
+	}{
+		{
+			name: "valid deployment",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: production
+  labels:
+    team: web
+spec:
+  replicas: 2
+`,
+			expected: []Violation{},
+		},
+		{
+			name: "too many replicas",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: production
+  labels:
+    team: web
+spec:
+  replicas: 5
+`,
+			expected: []Violation{
+				{Policy: "replica-limit", Binding: "replica-limit-production", Message: "replicas must be at most 3", Reason: "Invalid", ValidationActions: []string{"Deny"}},
+			},
+		},
+		{
+			name: "namespace selector doesn't match",
+			object: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: staging
+spec:
+  replicas: 5
+`,
+			expected: []Violation{
+				{Policy: "require-team-label", Binding: "require-team-label", Message: "team label is required", Reason: "Invalid", ValidationActions: []string{"Warn"}},
+			},
+		},
+		{
+			name: "match condition excludes namespaces",
+			object: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: staging
+`,
+			expected: []Violation{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object := parseObjects(t, test.object)[0]
+			isNamespaced := object["kind"] != "Namespace"
+
+			violations, err := evaluator.Evaluate(&Object{Content: object, IsNamespaced: isNamespaced}, lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(violations) != len(test.expected) {
+				t.Fatalf("expected %d violations, got %d: %+v", len(test.expected), len(violations), violations)
+			}
+
+			for i, expected := range test.expected {
+				actual := violations[i]
+				if actual.Policy != expected.Policy ||
+					actual.Binding != expected.Binding ||
+					actual.Message != expected.Message ||
+					actual.Reason != expected.Reason ||
+					len(actual.ValidationActions) != 1 ||
+					actual.ValidationActions[0] != expected.ValidationActions[0] {
+					t.Errorf("expected violation %+v, got %+v", expected, *actual)
+				}
+			}
+		})
+	}
+}
+
+func parseObjects(t *testing.T, content string) []map[string]any {
+	result := []map[string]any{}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+
+	for {
+		object := map[string]any{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result = append(result, object)
+	}
+
+	return result
+}
@@ -0,0 +1,94 @@
+package admissionpolicy
+
+import (
+	"slices"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission/plugin/webhook/predicates/rules"
+)
+
+var namespaceResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+// Returns true if the request matches the given match resources of a policy or a binding. Missing match
+// resources and empty resource rules match all requests.
+func (request *request) matches(matchResources *admissionregistrationv1.MatchResources) bool {
+	// API server never applies the policies to the policy and webhook configuration objects.
+	if rules.IsExemptAdmissionConfigurationResource(request.attributes) {
+		return false
+	}
+
+	if matchResources == nil {
+		return true
+	}
+
+	if len(matchResources.ResourceRules) > 0 && !request.matchesAnyRule(matchResources.ResourceRules) {
+		return false
+	}
+
+	if request.matchesAnyRule(matchResources.ExcludeResourceRules) {
+		return false
+	}
+
+	if !matchesLabelSelector(matchResources.ObjectSelector, request.object.GetLabels()) {
+		return false
+	}
+
+	return request.matchesNamespaceSelector(matchResources.NamespaceSelector)
+}
+
+func (request *request) matchesAnyRule(namedRules []admissionregistrationv1.NamedRuleWithOperations) bool {
+	for _, namedRule := range namedRules {
+		if len(namedRule.ResourceNames) > 0 && !slices.Contains(namedRule.ResourceNames, request.object.GetName()) {
+			continue
+		}
+
+		matcher := &rules.Matcher{
+			Rule: namedRule.RuleWithOperations,
+			Attr: request.attributes,
+		}
+
+		if matcher.Matches() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Namespace selector is matched against the labels of the namespace of the object. Cluster scoped objects
+// always match except the namespaces, which are matched using their own labels.
+func (request *request) matchesNamespaceSelector(selector *metav1.LabelSelector) bool {
+	if request.resource == namespaceResource {
+		objectLabels := map[string]string{}
+		for key, value := range request.object.GetLabels() {
+			objectLabels[key] = value
+		}
+
+		objectLabels[namespaceNameLabel] = request.object.GetName()
+
+		return matchesLabelSelector(selector, objectLabels)
+	}
+
+	if request.namespaceObject == nil {
+		return true
+	}
+
+	return matchesLabelSelector(selector, request.namespaceObject.Labels)
+}
+
+// Returns true if the given labels match the selector. Missing selectors match all labels.
+func matchesLabelSelector(selector *metav1.LabelSelector, objectLabels map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return labelSelector.Matches(labels.Set(objectLabels))
+}
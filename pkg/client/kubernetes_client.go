@@ -31,6 +31,9 @@ type KubernetesClient struct {
 	Mapper        meta.RESTMapper
 	CoreClient    kubernetes.Interface
 	DynamicClient dynamic.Interface
+
+	// Logger is used to report the progress of the apply and wait operations. Uses the default logger if nil.
+	Logger *slog.Logger
 }
 
 type ClusterInfo struct {
@@ -65,6 +68,22 @@ func NewKubernetesClient() (*KubernetesClient, error) {
 	}, nil
 }
 
+// WithLogger returns a copy of the client that reports the progress of the operations to the given logger.
+func (client *KubernetesClient) WithLogger(logger *slog.Logger) *KubernetesClient {
+	copy := *client
+	copy.Logger = logger
+
+	return &copy
+}
+
+func (client *KubernetesClient) logger() *slog.Logger {
+	if client.Logger == nil {
+		return slog.Default()
+	}
+
+	return client.Logger
+}
+
 func (client *KubernetesClient) GetClusterInfo() (*ClusterInfo, error) {
 	config, err := client.Factory.ToRESTConfig()
 	if err != nil {
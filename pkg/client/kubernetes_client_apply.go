@@ -10,6 +10,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/hexops/gotextdiff/myers"
 	"github.com/ohayocorp/anemos/pkg/core"
 	"github.com/ohayocorp/anemos/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -59,6 +61,45 @@ func (e NoChangesError) Error() string {
 	return "no changes were made"
 }
 
+type applyOperation struct {
+	applyOptions      *apply.ApplyOptions
+	applySetParentRef *apply.ApplySetParentRef
+	restClient        resource.RESTClient
+	infos             []*resource.Info
+	extraLabels       map[string]string
+}
+
+var (
+	applySetFeatureLock  sync.Mutex
+	applySetFeatureUsers int
+)
+
+// Enables kubectl's apply set feature gate and returns a function that disables it again. Apply operations
+// can run concurrently, so the environment variable is only removed after the last one completes.
+func enableApplySetFeature() (func(), error) {
+	applySetFeatureLock.Lock()
+	defer applySetFeatureLock.Unlock()
+
+	if applySetFeatureUsers == 0 {
+		err := os.Setenv("KUBECTL_APPLYSET", "true")
+		if err != nil {
+			return nil, fmt.Errorf("failed to set KUBECTL_APPLYSET environment variable: %w", err)
+		}
+	}
+
+	applySetFeatureUsers++
+
+	return func() {
+		applySetFeatureLock.Lock()
+		defer applySetFeatureLock.Unlock()
+
+		applySetFeatureUsers--
+		if applySetFeatureUsers == 0 {
+			os.Unsetenv("KUBECTL_APPLYSET")
+		}
+	}, nil
+}
+
 func (client *KubernetesClient) Apply(
 	documents []string,
 	applySetParentName string,
@@ -67,21 +108,93 @@ func (client *KubernetesClient) Apply(
 	forceConflicts bool,
 	timeout time.Duration,
 ) error {
-	applySetParentRef, err := client.getApplySetParentRef(applySetParentName, applySetParentNamespace)
+	operation, err := client.newApplyOperation(documents, applySetParentName, applySetParentNamespace, forceConflicts, timeout)
+	if err != nil {
+		return err
+	}
+
+	applyOptions := operation.applyOptions
+
+	// Add custom pre-processor to compute diffs and confirm changes.
+	applyOptions.PreProcessorFn = func() error {
+		return client.preprocess(operation.infos, applyOptions, skipConfirmation)
+	}
+
+	// Kubectl's prune post-processor will handle the deletion of objects not present in the apply set.
+	applyOptions.PostProcessorFn = applyOptions.PrintAndPrunePostProcessor()
+
+	// Apply sets are currently behind a feature gate, so we need to set the environment variable to enable them.
+	// This is a temporary workaround until the feature is stable.
+	disableApplySetFeature, err := enableApplySetFeature()
 	if err != nil {
 		return err
 	}
 
+	defer disableApplySetFeature()
+
+	// Run the apply operation.
+	if err := applyOptions.Run(); err != nil {
+		if _, ok := err.(NoChangesError); ok {
+			return err
+		}
+
+		return err
+	}
+
+	// Add managed-by label to the apply set parent resource. This label will be used when listing the apply sets.
+	return updateApplySetParentLabels(operation.restClient, operation.applySetParentRef, operation.extraLabels, applyOptions.FieldManager)
+}
+
+// Diff computes the changes that applying the given documents would make using server-side dry-run and
+// prints them. Objects that would be pruned from the apply set are included as deletions. Nothing is
+// changed on the cluster. Returns an empty slice if there are no changes.
+func (client *KubernetesClient) Diff(
+	documents []string,
+	applySetParentName string,
+	applySetParentNamespace string,
+	forceConflicts bool,
+) ([]Diff, error) {
+	operation, err := client.newApplyOperation(documents, applySetParentName, applySetParentNamespace, forceConflicts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, _, err := client.computeDiffs(operation.infos, operation.applyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diffs) == 0 {
+		return diffs, nil
+	}
+
+	printChanges(client.logger(), diffs)
+
+	return diffs, nil
+}
+
+func (client *KubernetesClient) newApplyOperation(
+	documents []string,
+	applySetParentName string,
+	applySetParentNamespace string,
+	forceConflicts bool,
+	timeout time.Duration,
+) (*applyOperation, error) {
+	applySetParentRef, err := client.getApplySetParentRef(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
 	validationDirective := metav1.FieldValidationIgnore
 	schema, err := client.Factory.Validator(validationDirective)
 	if err != nil {
-		return fmt.Errorf("failed to get schema validator: %w", err)
+		return nil, fmt.Errorf("failed to get schema validator: %w", err)
 	}
 
 	tooling := getTooling()
 	restClient, err := client.getApplySetRestClient(applySetParentRef)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	applySet := apply.NewApplySet(applySetParentRef, tooling, client.Mapper, restClient)
@@ -144,7 +257,7 @@ func (client *KubernetesClient) Apply(
 	// Get the resources to apply.
 	infos, err := builder.Do().Infos()
 	if err != nil {
-		return fmt.Errorf("failed to build resource infos: %w", err)
+		return nil, fmt.Errorf("failed to build resource infos: %w", err)
 	}
 
 	// Add managed-by label to all resources.
@@ -161,45 +274,60 @@ func (client *KubernetesClient) Apply(
 	// Add apply set labels to the resources.
 	err = applySet.AddLabels(infos...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Add custom pre-processor to compute diffs and confirm changes.
-	applyOptions.PreProcessorFn = func() error {
-		return client.preprocess(infos, applyOptions, skipConfirmation)
-	}
-
-	// Kubectl's prune post-processor will handle the deletion of objects not present in the apply set.
-	applyOptions.PostProcessorFn = applyOptions.PrintAndPrunePostProcessor()
+	return &applyOperation{
+		applyOptions:      applyOptions,
+		applySetParentRef: applySetParentRef,
+		restClient:        restClient,
+		infos:             infos,
+		extraLabels:       extraLabels,
+	}, nil
+}
 
-	// Apply sets are currently behind a feature gate, so we need to set the environment variable to enable them.
-	// This is a temporary workaround until the feature is stable.
-	err = os.Setenv("KUBECTL_APPLYSET", "true")
+func (client *KubernetesClient) preprocess(
+	infos []*resource.Info,
+	applyOptions *apply.ApplyOptions,
+	skipConfirmation bool,
+) error {
+	diffs, objectsToRecreate, err := client.computeDiffs(infos, applyOptions)
 	if err != nil {
-		return fmt.Errorf("failed to set KUBECTL_APPLYSET environment variable: %w", err)
+		return err
 	}
 
-	defer os.Unsetenv("KUBECTL_APPLYSET")
+	if len(diffs) == 0 {
+		// Return a specific type of error to indicate no changes were made.
+		return NoChangesError{}
+	}
 
-	// Run the apply operation.
-	if err := applyOptions.Run(); err != nil {
-		if _, ok := err.(NoChangesError); ok {
+	printChanges(client.logger(), diffs)
+
+	// Lastly, we need to confirm the changes with the user.
+	if !skipConfirmation {
+		confirmed, err := confirmChanges()
+		if err != nil {
 			return err
 		}
+		if !confirmed {
+			return fmt.Errorf("aborting apply operation due to user confirmation")
+		}
+	}
 
+	if err := client.recreateObjectsIfNeeded(context.TODO(), objectsToRecreate, applyOptions.DeleteOptions.Timeout); err != nil {
 		return err
 	}
 
-	// Add managed-by label to the apply set parent resource. This label will be used when listing the apply sets.
-	return updateApplySetParentLabels(restClient, applySetParentRef, extraLabels, applyOptions.FieldManager)
+	return nil
 }
 
-func (client *KubernetesClient) preprocess(
+// Computes the diffs between the live objects and the objects that will be applied using server-side dry-run.
+// Also finds the objects that will be pruned from the apply set and the objects that need to be recreated.
+func (client *KubernetesClient) computeDiffs(
 	infos []*resource.Info,
 	applyOptions *apply.ApplyOptions,
-	skipConfirmation bool,
-) error {
-	jobsToRecreate := newApplyJobRecreateSet()
+) ([]Diff, *applyRecreateSet, error) {
+	objectsToRecreate := newApplyRecreateSet()
 	visitedUids := sets.New[types.UID]()
 	diffs := []Diff{}
 
@@ -216,52 +344,62 @@ func (client *KubernetesClient) preprocess(
 		live, err := helper.Get(info.Namespace, info.Name)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
-				return err
+				return nil, nil, err
 			}
 
 			// Object does not exist, treat it as a new object.
 			live = nil
 		}
 
-		data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, local)
-		if err != nil {
-			return err
+		uid := getUID(live)
+		if uid != nil {
+			visitedUids.Insert(*uid)
 		}
 
-		options := metav1.PatchOptions{
-			Force:        core.Pointer(true),
-			FieldManager: applyOptions.FieldManager,
-		}
+		var merged runtime.Object
 
-		// Get the merged object by applying the patch on server-side with dry-run.
-		merged, err := helper.Patch(
-			info.Namespace,
-			info.Name,
-			types.ApplyPatchType,
-			data,
-			&options,
-		)
-		if err != nil {
-			if isJobResourceInfo(info) && isImmutableFieldError(err) {
-				if isJobRecreateOnImmutableEnabled(info) {
-					jobsToRecreate.Add(info)
-					// If we're going to recreate the Job, treat the local object as the merged object
-					// for diff/confirmation purposes.
-					merged = local
+		if live != nil && hasHookDeletePolicy(info, release.HookBeforeHookCreation) {
+			// Helm hooks are deleted and created again on each apply so that they run again, e.g. database
+			// migration Jobs. Show them as new objects.
+			objectsToRecreate.Add(info, release.HookDeleteAnnotation)
+			live = nil
+			merged = local
+		} else {
+			data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, local)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			options := metav1.PatchOptions{
+				Force:        core.Pointer(true),
+				FieldManager: applyOptions.FieldManager,
+			}
+
+			// Get the merged object by applying the patch on server-side with dry-run.
+			merged, err = helper.Patch(
+				info.Namespace,
+				info.Name,
+				types.ApplyPatchType,
+				data,
+				&options,
+			)
+			if err != nil {
+				if isJobResourceInfo(info) && isImmutableFieldError(err) {
+					if isJobRecreateOnImmutableEnabled(info) {
+						objectsToRecreate.Add(info, JobRecreateOnImmutableFieldsAnnotation)
+						// If we're going to recreate the Job, treat the local object as the merged object
+						// for diff/confirmation purposes.
+						merged = local
+					} else {
+						// Only Jobs can be recreated, and only when explicitly enabled.
+						return nil, nil, err
+					}
 				} else {
-					// Only Jobs can be recreated, and only when explicitly enabled.
-					return err
+					return nil, nil, err
 				}
-			} else {
-				return err
 			}
 		}
 
-		uid := getUID(live)
-		if uid != nil {
-			visitedUids.Insert(*uid)
-		}
-
 		// No need to compare managed fields, as they are not part of the manifests.
 		omitManagedFields(live)
 		omitManagedFields(merged)
@@ -274,7 +412,7 @@ func (client *KubernetesClient) preprocess(
 		if gvk := merged.GetObjectKind().GroupVersionKind(); gvk.Version == "v1" && gvk.Kind == "Secret" {
 			m, err := diff.NewMasker(live, merged)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 
 			liveIsNil := live == nil
@@ -295,12 +433,12 @@ func (client *KubernetesClient) preprocess(
 		if live != nil {
 			liveYaml, err := yaml.Marshal(live)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 
 			mergedYaml, err := yaml.Marshal(merged)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 
 			liveYamlString = string(liveYaml)
@@ -311,7 +449,7 @@ func (client *KubernetesClient) preprocess(
 			// such as UID and creation timestamp.
 			localYaml, err := yaml.Marshal(local)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 
 			mergedYamlString = string(localYaml)
@@ -320,11 +458,11 @@ func (client *KubernetesClient) preprocess(
 		// Get the diff text between the live and merged objects.
 		diff, err := getDiffText(liveYamlString, mergedYamlString)
 		if err != nil {
-			return fmt.Errorf("failed to compute diff for %s/%s: %w", info.Namespace, info.Name, err)
+			return nil, nil, fmt.Errorf("failed to compute diff for %s/%s: %w", info.Namespace, info.Name, err)
 		}
 
 		if diff == "" {
-			slog.Info(fmt.Sprintf(
+			client.logger().Info(fmt.Sprintf(
 				"No changes for %s",
 				getDiffColored(fmt.Sprintf("%s/%s", info.Mapping.Resource.Resource, info.Name), DiffTypeAdded)))
 
@@ -350,7 +488,7 @@ func (client *KubernetesClient) preprocess(
 	applyOptions.ApplySet.BeforeApply(nil, cmdutil.DryRunClient, applyOptions.ValidationDirective)
 	objectsToPrune, err := applyOptions.ApplySet.FindAllObjectsToPrune(context.TODO(), client.DynamicClient, visitedUids)
 	if err != nil {
-		return fmt.Errorf("failed to find objects to prune: %w", err)
+		return nil, nil, fmt.Errorf("failed to find objects to prune: %w", err)
 	}
 
 	for _, object := range objectsToPrune {
@@ -358,13 +496,13 @@ func (client *KubernetesClient) preprocess(
 
 		objectYaml, err := yaml.Marshal(object.Object)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		// We don't show the diff for deleted objects, same as for new objects.
 		diff, err := getDiffText(string(objectYaml), "")
 		if err != nil {
-			return fmt.Errorf("failed to compute diff for %s/%s: %w", object.Namespace, object.Name, err)
+			return nil, nil, fmt.Errorf("failed to compute diff for %s/%s: %w", object.Namespace, object.Name, err)
 		}
 
 		diffs = append(diffs, Diff{
@@ -375,55 +513,35 @@ func (client *KubernetesClient) preprocess(
 		})
 	}
 
-	if len(diffs) == 0 {
-		// Return a specific type of error to indicate no changes were made.
-		return NoChangesError{}
-	}
-
-	printChanges(diffs)
-
-	// Lastly, we need to confirm the changes with the user.
-	if !skipConfirmation {
-		confirmed, err := confirmChanges()
-		if err != nil {
-			return err
-		}
-		if !confirmed {
-			return fmt.Errorf("aborting apply operation due to user confirmation")
-		}
-	}
-
-	if err := client.recreateJobsIfNeeded(context.TODO(), jobsToRecreate, applyOptions.DeleteOptions.Timeout); err != nil {
-		return err
-	}
-
-	return nil
+	return diffs, objectsToRecreate, nil
 }
 
 const (
 	JobRecreateOnImmutableFieldsAnnotation = "anemos.sh/recreate-on-immutable-fields-change"
 )
 
-type applyJobRecreateKey struct {
+type applyRecreateKey struct {
 	gvr       schema.GroupVersionResource
 	name      string
 	namespace string
 }
 
-type applyJobRecreateSet struct {
-	items map[applyJobRecreateKey]struct{}
+// Objects that are deleted before the apply so that they are created again. Values are the annotations
+// that caused the recreation.
+type applyRecreateSet struct {
+	items map[applyRecreateKey]string
 }
 
-func newApplyJobRecreateSet() *applyJobRecreateSet {
-	return &applyJobRecreateSet{items: map[applyJobRecreateKey]struct{}{}}
+func newApplyRecreateSet() *applyRecreateSet {
+	return &applyRecreateSet{items: map[applyRecreateKey]string{}}
 }
 
-func (s *applyJobRecreateSet) Add(info *resource.Info) {
+func (s *applyRecreateSet) Add(info *resource.Info, annotation string) {
 	if info == nil || info.Mapping == nil {
 		return
 	}
 
-	key := applyJobRecreateKey{
+	key := applyRecreateKey{
 		gvr:       info.Mapping.Resource,
 		name:      info.Name,
 		namespace: info.Namespace,
@@ -432,10 +550,10 @@ func (s *applyJobRecreateSet) Add(info *resource.Info) {
 		return
 	}
 
-	s.items[key] = struct{}{}
+	s.items[key] = annotation
 }
 
-func (s *applyJobRecreateSet) Len() int {
+func (s *applyRecreateSet) Len() int {
 	return len(s.items)
 }
 
@@ -482,18 +600,28 @@ func isImmutableFieldError(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "field is immutable")
 }
 
-func (client *KubernetesClient) recreateJobsIfNeeded(ctx context.Context, jobsToRecreate *applyJobRecreateSet, timeout time.Duration) error {
-	if jobsToRecreate == nil || jobsToRecreate.Len() == 0 {
+func (client *KubernetesClient) recreateObjectsIfNeeded(ctx context.Context, objectsToRecreate *applyRecreateSet, timeout time.Duration) error {
+	if objectsToRecreate == nil || objectsToRecreate.Len() == 0 {
 		return nil
 	}
 
-	for key := range jobsToRecreate.items {
-		slog.Warn(
-			`Recreating Job due to immutable field change: "${namespace}/${name}" since annotation "${annotation}" is set to true`,
-			slog.String("namespace", key.namespace),
-			slog.String("name", key.name),
-			slog.String("annotation", JobRecreateOnImmutableFieldsAnnotation),
-		)
+	for key, annotation := range objectsToRecreate.items {
+		if annotation == release.HookDeleteAnnotation {
+			client.logger().Info(
+				`Recreating hook ${resource} "${namespace}/${name}" since its delete policy is ${policy}`,
+				slog.String("resource", key.gvr.Resource),
+				slog.String("namespace", key.namespace),
+				slog.String("name", key.name),
+				slog.String("policy", string(release.HookBeforeHookCreation)),
+			)
+		} else {
+			client.logger().Warn(
+				`Recreating Job due to immutable field change: "${namespace}/${name}" since annotation "${annotation}" is set to true`,
+				slog.String("namespace", key.namespace),
+				slog.String("name", key.name),
+				slog.String("annotation", JobRecreateOnImmutableFieldsAnnotation),
+			)
+		}
 
 		if err := client.deleteAndWaitForResourceGone(ctx, key.gvr, key.namespace, key.name, timeout); err != nil {
 			return err
@@ -527,7 +655,7 @@ func (client *KubernetesClient) deleteAndWaitForResourceGone(
 	})
 }
 
-func printChanges(diffs []Diff) {
+func printChanges(logger *slog.Logger, diffs []Diff) {
 	sort.Slice(diffs, func(i, j int) bool {
 		// Sort by diff type first. The order is: Modified, Deleted, Added.
 		if diffs[i].DiffType != diffs[j].DiffType {
@@ -549,17 +677,17 @@ func printChanges(diffs []Diff) {
 		// Print the diff for modified resources.
 		if diff.DiffType == DiffTypeModified {
 			if !printedLabel {
-				slog.Info("Changes to be applied:")
+				logger.Info("Changes to be applied:")
 				printedLabel = true
 			}
 
-			slog.Info(fmt.Sprintf(
+			logger.Info(fmt.Sprintf(
 				"%s:\n  %s",
 				getDiffColored(diff.Resource, diff.DiffType), util.Indent(diff.DiffText, 2)))
 		}
 	}
 
-	slog.Info("Summary of changes:")
+	logger.Info("Summary of changes:")
 
 	builder := &strings.Builder{}
 	w := tabwriter.NewWriter(builder, 1, 1, 2, ' ', 0)
@@ -592,7 +720,7 @@ func printChanges(diffs []Diff) {
 			continue
 		}
 
-		slog.Info(line)
+		logger.Info(line)
 	}
 }
 
@@ -144,5 +144,6 @@ func (client *KubernetesClient) Delete(
 			applySetParentRef.Name, err)
 	}
 
-	return nil
+	// Revisions can't be rolled back without the apply set parent.
+	return client.DeleteRevisions(applySetParentName, applySetParentNamespace)
 }
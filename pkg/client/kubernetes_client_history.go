@@ -0,0 +1,405 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ohayocorp/anemos/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Default number of revisions that are kept for each apply set.
+	DefaultRevisionHistoryLimit = 10
+
+	RevisionApplySetLabel   = "anemos.sh/apply-set"
+	RevisionNumberLabel     = "anemos.sh/revision"
+	RevisionTimestampKey    = "anemos.sh/applied-at"
+	revisionManifestsKey    = "manifests"
+	revisionMetadataKey     = "metadata"
+	revisionSecretType      = "anemos.sh/revision.v1"
+	revisionDocumentDivider = "\n---\n"
+)
+
+// RevisionMetadata contains information about the build that produced an applied revision.
+type RevisionMetadata struct {
+	AnemosVersion   string `json:"anemosVersion,omitempty"`
+	Source          string `json:"source,omitempty"`
+	DocumentGroup   string `json:"documentGroup,omitempty"`
+	Environment     string `json:"environment,omitempty"`
+	EnvironmentType string `json:"environmentType,omitempty"`
+	Description     string `json:"description,omitempty"`
+}
+
+// Revision is a snapshot of the manifests that were applied for an apply set.
+type Revision struct {
+	ApplySetName string
+	Number       int
+	Timestamp    time.Time
+	Metadata     RevisionMetadata
+	Documents    []string
+}
+
+// SaveRevision stores the given documents as a new revision of the apply set. Revisions are stored as
+// Secrets next to the apply set parent. Oldest revisions are deleted so that at most historyLimit revisions
+// are kept. A non-positive historyLimit uses [DefaultRevisionHistoryLimit].
+func (client *KubernetesClient) SaveRevision(
+	applySetParentName string,
+	applySetParentNamespace string,
+	documents []string,
+	metadata *RevisionMetadata,
+	historyLimit int,
+) (*Revision, error) {
+	if historyLimit <= 0 {
+		historyLimit = DefaultRevisionHistoryLimit
+	}
+
+	namespace, err := client.getRevisionNamespace(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := client.listRevisionSecrets(applySetParentName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	number := 1
+	if len(existing) > 0 {
+		number = getRevisionNumber(&existing[len(existing)-1]) + 1
+	}
+
+	if metadata == nil {
+		metadata = &RevisionMetadata{}
+	}
+
+	if metadata.AnemosVersion == "" {
+		metadata.AnemosVersion = util.AppVersion
+	}
+
+	revision := &Revision{
+		ApplySetName: applySetParentName,
+		Number:       number,
+		Timestamp:    time.Now().UTC(),
+		Metadata:     *metadata,
+		Documents:    documents,
+	}
+
+	secret, err := revisionToSecret(revision, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.CoreClient.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store revision %d of apply set %s: %w", number, applySetParentName, err)
+	}
+
+	// Existing secrets don't contain the new revision, so one less than the limit is kept.
+	for len(existing) > historyLimit-1 {
+		oldest := existing[0]
+		existing = existing[1:]
+
+		err := client.CoreClient.CoreV1().Secrets(namespace).Delete(context.TODO(), oldest.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete old revision %s: %w", oldest.Name, err)
+		}
+	}
+
+	return revision, nil
+}
+
+// GetRevisions returns the stored revisions of the apply set sorted by their numbers.
+func (client *KubernetesClient) GetRevisions(applySetParentName string, applySetParentNamespace string) ([]*Revision, error) {
+	namespace, err := client.getRevisionNamespace(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := client.listRevisionSecrets(applySetParentName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []*Revision{}
+	for _, secret := range secrets {
+		revision, err := secretToRevision(&secret)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns the revision with the given number. If number is 0, returns the revision that was
+// applied before the latest one.
+func (client *KubernetesClient) GetRevision(applySetParentName string, applySetParentNamespace string, number int) (*Revision, error) {
+	revisions, err := client.GetRevisions(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if number == 0 {
+		if len(revisions) < 2 {
+			return nil, fmt.Errorf("apply set %s has no previous revision to roll back to", applySetParentName)
+		}
+
+		return revisions[len(revisions)-2], nil
+	}
+
+	for _, revision := range revisions {
+		if revision.Number == number {
+			return revision, nil
+		}
+	}
+
+	return nil, fmt.Errorf("revision %d of apply set %s is not found", number, applySetParentName)
+}
+
+// DeleteRevisions deletes all stored revisions of the apply set.
+func (client *KubernetesClient) DeleteRevisions(applySetParentName string, applySetParentNamespace string) error {
+	namespace, err := client.getRevisionNamespace(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return err
+	}
+
+	err = client.CoreClient.CoreV1().Secrets(namespace).DeleteCollection(
+		context.TODO(),
+		metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: getRevisionLabelSelector(applySetParentName)})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete revisions of apply set %s: %w", applySetParentName, err)
+	}
+
+	return nil
+}
+
+// History prints the stored revisions of the apply set.
+func (client *KubernetesClient) History(applySetParentName string, applySetParentNamespace string) error {
+	revisions, err := client.GetRevisions(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) == 0 {
+		slog.Info("No revisions found for apply set ${name}.", slog.String("name", applySetParentName))
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+	fmt.Fprintln(writer, "REVISION\tAPPLIED AT\tVERSION\tENVIRONMENT\tSOURCE\tDESCRIPTION")
+	fmt.Fprintln(writer, "--------\t----------\t-------\t-----------\t------\t-----------")
+
+	for _, revision := range revisions {
+		metadata := revision.Metadata
+
+		environment := metadata.Environment
+		if environment == "" {
+			environment = metadata.EnvironmentType
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%d\t%s\t%s\t%s\t%s\t%s\n",
+			revision.Number,
+			revision.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			metadata.AnemosVersion,
+			environment,
+			metadata.Source,
+			metadata.Description)
+	}
+
+	writer.Flush()
+
+	return nil
+}
+
+// Rollback applies the documents of a stored revision using server-side apply and prunes the objects
+// that are not part of it. If number is 0, rolls back to the revision before the latest one. A new revision
+// is recorded after a successful rollback.
+func (client *KubernetesClient) Rollback(
+	applySetParentName string,
+	applySetParentNamespace string,
+	number int,
+	skipConfirmation bool,
+	forceConflicts bool,
+	timeout time.Duration,
+) error {
+	revision, err := client.GetRevision(applySetParentName, applySetParentNamespace, number)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Rolling back apply set ${name} to revision ${revision} applied at ${timestamp}",
+		slog.String("name", applySetParentName),
+		slog.Int("revision", revision.Number),
+		slog.String("timestamp", revision.Timestamp.Local().Format("2006-01-02 15:04:05")))
+
+	err = client.Apply(
+		revision.Documents,
+		applySetParentName,
+		applySetParentNamespace,
+		skipConfirmation,
+		forceConflicts,
+		timeout)
+
+	if err != nil {
+		if _, ok := err.(NoChangesError); ok {
+			slog.Info("Apply set ${name} is already at the state of revision ${revision}",
+				slog.String("name", applySetParentName),
+				slog.Int("revision", revision.Number))
+
+			return nil
+		}
+
+		return err
+	}
+
+	metadata := revision.Metadata
+	metadata.AnemosVersion = util.AppVersion
+	metadata.Description = fmt.Sprintf("Rollback to revision %d", revision.Number)
+
+	_, err = client.SaveRevision(applySetParentName, applySetParentNamespace, revision.Documents, &metadata, 0)
+
+	return err
+}
+
+func (client *KubernetesClient) getRevisionNamespace(applySetParentName string, applySetParentNamespace string) (string, error) {
+	applySetParentRef, err := client.getApplySetParentRef(applySetParentName, applySetParentNamespace)
+	if err != nil {
+		return "", err
+	}
+
+	return applySetParentRef.Namespace, nil
+}
+
+func (client *KubernetesClient) listRevisionSecrets(applySetParentName string, namespace string) ([]corev1.Secret, error) {
+	list, err := client.CoreClient.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: getRevisionLabelSelector(applySetParentName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions of apply set %s: %w", applySetParentName, err)
+	}
+
+	secrets := list.Items
+	sort.Slice(secrets, func(i, j int) bool {
+		return getRevisionNumber(&secrets[i]) < getRevisionNumber(&secrets[j])
+	})
+
+	return secrets, nil
+}
+
+func getRevisionLabelSelector(applySetParentName string) string {
+	return fmt.Sprintf("%s=%s", RevisionApplySetLabel, applySetParentName)
+}
+
+func getRevisionSecretName(applySetParentName string, number int) string {
+	return fmt.Sprintf("anemos.revision.%s.v%d", applySetParentName, number)
+}
+
+func getRevisionNumber(secret *corev1.Secret) int {
+	number, err := strconv.Atoi(secret.Labels[RevisionNumberLabel])
+	if err != nil {
+		return 0
+	}
+
+	return number
+}
+
+func revisionToSecret(revision *Revision, namespace string) (*corev1.Secret, error) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+
+	for i, document := range revision.Documents {
+		if i > 0 {
+			writer.Write([]byte(revisionDocumentDivider))
+		}
+
+		writer.Write([]byte(document))
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress revision manifests: %w", err)
+	}
+
+	metadata, err := json.Marshal(revision.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision metadata: %w", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getRevisionSecretName(revision.ApplySetName, revision.Number),
+			Namespace: namespace,
+			Labels: map[string]string{
+				RevisionApplySetLabel: revision.ApplySetName,
+				RevisionNumberLabel:   strconv.Itoa(revision.Number),
+			},
+			Annotations: map[string]string{
+				RevisionTimestampKey: revision.Timestamp.Format(time.RFC3339),
+			},
+		},
+		Type: revisionSecretType,
+		Data: map[string][]byte{
+			revisionManifestsKey: buffer.Bytes(),
+			revisionMetadataKey:  metadata,
+		},
+	}, nil
+}
+
+func secretToRevision(secret *corev1.Secret) (*Revision, error) {
+	revision := &Revision{
+		ApplySetName: secret.Labels[RevisionApplySetLabel],
+		Number:       getRevisionNumber(secret),
+		Timestamp:    secret.CreationTimestamp.UTC(),
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, secret.Annotations[RevisionTimestampKey])
+	if err == nil {
+		revision.Timestamp = timestamp
+	}
+
+	if data := secret.Data[revisionMetadataKey]; len(data) > 0 {
+		if err := json.Unmarshal(data, &revision.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of revision %s: %w", secret.Name, err)
+		}
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(secret.Data[revisionManifestsKey]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress manifests of revision %s: %w", secret.Name, err)
+	}
+	defer reader.Close()
+
+	manifests, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests of revision %s: %w", secret.Name, err)
+	}
+
+	for _, document := range bytes.Split(manifests, []byte(revisionDocumentDivider)) {
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+
+		revision.Documents = append(revision.Documents, string(document))
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Deletes the Helm hooks in the given documents that have the given policy in their "helm.sh/hook-delete-policy"
+// annotation, e.g. the hooks with the hook-succeeded policy after they are completed.
+func (client *KubernetesClient) DeleteHooks(documents []string, policy release.HookDeletePolicy, timeout time.Duration) error {
+	// Only the hooks with an explicit delete policy can be deleted after they run, skip parsing the
+	// documents if there are none.
+	buffer := bytes.NewBuffer(nil)
+	for _, document := range documents {
+		if strings.Contains(document, release.HookDeleteAnnotation) {
+			fmt.Fprintf(buffer, "---\n%s\n", document)
+		}
+	}
+
+	if buffer.Len() == 0 {
+		return nil
+	}
+
+	namespace, _, _ := client.Factory.ToRawKubeConfigLoader().Namespace()
+
+	infos, err := client.Factory.NewBuilder().
+		ContinueOnError().
+		Flatten().
+		NamespaceParam(namespace).
+		DefaultNamespace().
+		Unstructured().
+		Stream(buffer, "").
+		Do().
+		Infos()
+
+	if err != nil {
+		return fmt.Errorf("failed to build resource infos: %w", err)
+	}
+
+	for _, info := range infos {
+		if !hasHookDeletePolicy(info, policy) {
+			continue
+		}
+
+		client.logger().Info(
+			`Deleting hook ${resource} "${namespace}/${name}" since its delete policy is ${policy}`,
+			slog.String("resource", info.Mapping.Resource.Resource),
+			slog.String("namespace", info.Namespace),
+			slog.String("name", info.Name),
+			slog.String("policy", string(policy)),
+		)
+
+		if err := client.deleteAndWaitForResourceGone(context.TODO(), info.Mapping.Resource, info.Namespace, info.Name, timeout); err != nil {
+			return fmt.Errorf("failed to delete hook %s/%s: %w", info.Namespace, info.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Returns true if the object is a Helm hook and its "helm.sh/hook-delete-policy" annotation contains the given
+// policy. Hooks without the annotation have the before-hook-creation policy, same as Helm.
+func hasHookDeletePolicy(info *resource.Info, policy release.HookDeletePolicy) bool {
+	u, ok := info.Object.(*unstructured.Unstructured)
+	if !ok || u == nil {
+		return false
+	}
+
+	annotations := u.GetAnnotations()
+	if strings.TrimSpace(annotations[release.HookAnnotation]) == "" {
+		return false
+	}
+
+	policies := []release.HookDeletePolicy{}
+	for _, value := range strings.Split(annotations[release.HookDeleteAnnotation], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			policies = append(policies, release.HookDeletePolicy(value))
+		}
+	}
+
+	if len(policies) == 0 {
+		policies = append(policies, release.HookBeforeHookCreation)
+	}
+
+	return slices.Contains(policies, policy)
+}
@@ -46,7 +46,7 @@ func (client *KubernetesClient) Wait(objects object.ObjMetadataSet, status statu
 
 	initialObjects := append(make([]object.ObjMetadata, 0, len(objects)), objects...)
 	statusCollector := collector.NewResourceStatusCollector(objects)
-	done := statusCollector.ListenWithObserver(eventsChannel, statusObserver(initialObjects, cancel, status))
+	done := statusCollector.ListenWithObserver(eventsChannel, statusObserver(client.logger(), initialObjects, cancel, status))
 	<-done
 
 	if ctx.Err() == context.DeadlineExceeded {
@@ -60,7 +60,7 @@ func (client *KubernetesClient) Wait(objects object.ObjMetadataSet, status statu
 	return nil
 }
 
-func statusObserver(initialObjects object.ObjMetadataSet, cancel context.CancelFunc, desired status.Status) collector.ObserverFunc {
+func statusObserver(logger *slog.Logger, initialObjects object.ObjMetadataSet, cancel context.CancelFunc, desired status.Status) collector.ObserverFunc {
 	successfulResources := make(map[string]bool)
 
 	return func(statusCollector *collector.ResourceStatusCollector, _ event.Event) {
@@ -89,7 +89,7 @@ func statusObserver(initialObjects object.ObjMetadataSet, cancel context.CancelF
 			} else if !successfulResources[rs.Identifier.String()] {
 				// Deletion is already waited for with foreground propagation, no need to log again.
 				if rs.Status != status.NotFoundStatus {
-					slog.Info(
+					logger.Info(
 						"Resource ${kind}/${name} is in desired state: ${message}",
 						slog.String("name", rs.Identifier.Name),
 						slog.String("kind", rs.Identifier.GroupKind.Kind),
@@ -116,7 +116,7 @@ func statusObserver(initialObjects object.ObjMetadataSet, cancel context.CancelF
 			for _, key := range core.SortedKeys(nonDesiredResources) {
 				value := nonDesiredResources[key]
 
-				slog.Info(
+				logger.Info(
 					"Waiting for resource ${kind}/${name}, expected=${expectedStatus}, actual=${actualStatus}: ${message}",
 					slog.String("name", value.Identifier.Name),
 					slog.String("kind", value.Identifier.GroupKind.Kind),
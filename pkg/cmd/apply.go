@@ -39,6 +39,11 @@ type applyContext struct {
 	distribution     core.KubernetesDistribution
 	environmentType  core.EnvironmentType
 	documentGroups   []string
+	maxConcurrency   int
+	profile          bool
+	failOn           string
+	failOnCategories []string
+	offline          bool
 	options          map[string]any
 }
 
@@ -64,9 +69,14 @@ func getApplyCommand(program *AnemosProgram) *cobra.Command {
 	command.Flags().BoolP("yes", "y", false, "Skip confirmation prompt and apply changes directly")
 	command.Flags().Bool("force-conflicts", false, "Forcefully apply changes even if there are conflicts")
 	command.Flags().StringArrayP("document-groups", "d", nil, "Document groups to apply, other groups will be skipped")
+	command.Flags().Bool("profile", false, "Measure the time spent in each step and component, write a Chrome trace file to anemos-trace.json")
+	command.Flags().Int("max-concurrency", 0, "Maximum number of document groups that are applied at the same time, defaults to 4")
 	command.Flags().StringP("options-file", "f", "", "Path to YAML file containing options to pass to the package")
 	command.Flags().String("distribution", "", "Distribution of the target Kubernetes cluster, e.g., minikube, openshift, etc. If not set, it will be determined based on the cluster version.")
 	command.Flags().String("environment-type", "", "Environment type such as dev, test or prod. If not set, it will be determined based on the cluster distribution.")
+	command.Flags().String("fail-on", "", "Fail before applying if there is a diagnostic with this severity or above: info, warning or error.")
+	command.Flags().StringArray("fail-on-category", nil, "Only fail on the diagnostics with these categories, e.g. security.")
+	command.Flags().Bool("offline", false, "Only use the vendored and cached remote charts and modules that are recorded in anemos.lock.")
 
 	return command
 }
@@ -77,8 +87,17 @@ func runApplyCommand(cmd *cobra.Command, args []string, program *AnemosProgram)
 	namespace := cmdutil.GetFlagString(cmd, "namespace")
 	optionsFile := cmdutil.GetFlagString(cmd, "options-file")
 	documentGroups := cmdutil.GetFlagStringArray(cmd, "document-groups")
+	maxConcurrency := cmdutil.GetFlagInt(cmd, "max-concurrency")
+	profile := cmdutil.GetFlagBool(cmd, "profile")
 	distribution := cmdutil.GetFlagString(cmd, "distribution")
 	environmentType := cmdutil.GetFlagString(cmd, "environment-type")
+	failOn := cmdutil.GetFlagString(cmd, "fail-on")
+	failOnCategories := cmdutil.GetFlagStringArray(cmd, "fail-on-category")
+	offline := cmdutil.GetFlagBool(cmd, "offline")
+
+	if err := validateFailOn(failOn); err != nil {
+		return err
+	}
 
 	// Check if we should skip confirmation from environment variable.
 	if cmd.Flags().Lookup("yes") == nil {
@@ -109,6 +128,11 @@ func runApplyCommand(cmd *cobra.Command, args []string, program *AnemosProgram)
 		distribution:     core.KubernetesDistribution(distribution),
 		environmentType:  core.EnvironmentType(environmentType),
 		documentGroups:   documentGroups,
+		maxConcurrency:   maxConcurrency,
+		profile:          profile,
+		failOn:           failOn,
+		failOnCategories: failOnCategories,
+		offline:          offline,
 		options:          yamlOptions,
 	}
 
@@ -164,14 +188,15 @@ func applyURL(context *applyContext) error {
 			return err
 		}
 
-		return applyJavaScriptFile(context, contents)
+		return applyJavaScriptFile(context, contents, ".")
 	}
 
 	// For other types of URLs, we assume it's a package URL that Bun can handle.
 	return applyPackage(context)
 }
 
-func applyJavaScriptFile(context *applyContext, script string) error {
+// Applies the given script. Remote charts and modules are locked in the given project directory.
+func applyJavaScriptFile(context *applyContext, script string, projectDirectory string) error {
 	jsRuntime, err := InitializeNewRuntime(context.program)
 	if err != nil {
 		return err
@@ -188,10 +213,10 @@ func applyJavaScriptFile(context *applyContext, script string) error {
 
 	setVariables(jsRuntime, context)
 
-	return jsRuntime.Run(&js.JsScript{
+	return runWithFetcher(jsRuntime, &js.JsScript{
 		Contents: applyJsFileScript,
 		FilePath: "index.js",
-	}, nil)
+	}, nil, projectDirectory, context.offline)
 }
 
 func applyPackage(context *applyContext) error {
@@ -204,7 +229,7 @@ func applyPackage(context *applyContext) error {
 
 		contents, err := os.ReadFile(jsFile)
 		if err == nil {
-			return applyJavaScriptFile(context, string(contents))
+			return applyJavaScriptFile(context, string(contents), filepath.Dir(jsFile))
 		}
 	}
 
@@ -277,10 +302,11 @@ func applyPackageIdentifier(context *applyContext) error {
 
 	setVariables(jsRuntime, context)
 
-	return jsRuntime.Run(&js.JsScript{
+	// Remote charts and modules are locked in the current directory since the temporary directory is removed.
+	return runWithFetcher(jsRuntime, &js.JsScript{
 		Contents: applyJsFileScript,
 		FilePath: filePath,
-	}, nil)
+	}, nil, ".", context.offline)
 }
 
 // downloadContents downloads the content from a URL as a string.
@@ -338,10 +364,29 @@ func setVariables(jsRuntime *js.JsRuntime, context *applyContext) {
 	jsRuntime.Runtime.Set("namespace", context.namespace)
 	jsRuntime.Runtime.Set("skipConfirmation", context.skipConfirmation)
 	jsRuntime.Runtime.Set("forceConflicts", context.forceConflicts)
+	jsRuntime.Runtime.Set("maxConcurrency", context.maxConcurrency)
+	jsRuntime.Runtime.Set("profileTraceFilePath", getProfileTraceFilePath(context))
+	jsRuntime.Runtime.Set("failOn", context.failOn)
+	jsRuntime.Runtime.Set("failOnCategories", context.failOnCategories)
 	jsRuntime.Runtime.Set("clusterInfo", clusterInfo)
 	jsRuntime.Runtime.Set("environmentType", getEnvironmentType(clusterInfo, context))
 }
 
+// Returns the path of the trace file in the working directory since packages are built in a temporary
+// directory that is deleted after apply. Returns an empty string if profiling is not enabled.
+func getProfileTraceFilePath(context *applyContext) string {
+	if !context.profile {
+		return ""
+	}
+
+	path, err := filepath.Abs("anemos-trace.json")
+	if err != nil {
+		js.Throw(fmt.Errorf("failed to get trace file path: %w", err))
+	}
+
+	return path
+}
+
 func getClusterInfo(context *applyContext) (*client.ClusterInfo, error) {
 	kubernetesClient, err := client.NewKubernetesClient()
 	if err != nil {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"github.com/ohayocorp/anemos/pkg/core"
 	"github.com/ohayocorp/anemos/pkg/js"
 	"github.com/ohayocorp/anemos/pkg/k8s"
+	"github.com/ohayocorp/anemos/pkg/remote"
 	"github.com/ohayocorp/anemos/pkg/util"
 	"github.com/spf13/cobra"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -29,27 +31,118 @@ func getBuildCommand(program *AnemosProgram) *cobra.Command {
 	command.Flags().Bool("yes", false, "Skip confirmation prompt and apply changes directly")
 	command.Flags().Bool("force-conflicts", false, "Forcefully apply changes even if there are conflicts")
 	command.Flags().StringArrayP("document-groups", "d", nil, "Document groups to apply, other groups will be skipped")
+	command.Flags().Int("max-concurrency", 0, "Maximum number of document groups that are applied at the same time, defaults to 4")
+	command.Flags().Bool("diff-only", false, "Show the changes that would be applied to the cluster without applying them.")
+	command.Flags().Bool("profile", false, "Measure the time spent in each step and component, write a report and a Chrome trace file.")
+	command.Flags().Bool("compare-output", false, "Compare the generated manifests with the previous output and report the changes.")
+	command.Flags().String("fail-on", "", "Fail before writing the output if there is a diagnostic with this severity or above: info, warning or error.")
+	command.Flags().StringArray("fail-on-category", nil, "Only fail on the diagnostics with these categories, e.g. security.")
+	command.Flags().StringArray("diagnostics-format", nil, "Also write the diagnostics in the given formats to the diagnostics directory of the output: sarif or junit.")
+	command.Flags().Bool("fix", false, "Apply the fixes of the diagnostics to the documents before writing the output and report the applied fixes.")
+	command.Flags().String("diagnostics-baseline", "", "Don't report or fail on the diagnostics that are accepted in the given baseline file.")
+	command.Flags().Bool("update-diagnostics-baseline", false, "Write the current diagnostics into the file given with --diagnostics-baseline.")
+	command.Flags().StringArray("admission-policy", nil, "Evaluate the ValidatingAdmissionPolicies and bindings in the given files against the documents and report the violations.")
+	command.Flags().String("matrix", "", "Build the project once for each target in the given matrix file and write a summary of the differences.")
+	command.Flags().Bool("offline", false, "Only use the vendored and cached remote charts and modules that are recorded in anemos.lock.")
 
 	return command
 }
 
+type buildOptions struct {
+	apply              bool
+	skipConfirmation   bool
+	forceConflicts     bool
+	diffOnly           bool
+	compareOutput      bool
+	documentGroups     []string
+	maxConcurrency     int
+	profile            bool
+	matrixFile         string
+	failOn             string
+	failOnCategories   []string
+	diagnosticsFormats []string
+	fix                bool
+	offline            bool
+
+	admissionPolicyFiles []string
+
+	diagnosticsBaseline       string
+	updateDiagnosticsBaseline bool
+}
+
 func build(cmd *cobra.Command, args []string, program *AnemosProgram) error {
-	apply := cmdutil.GetFlagBool(cmd, "apply")
-	skipConfirmation := cmdutil.GetFlagBool(cmd, "yes")
-	forceConflicts := cmdutil.GetFlagBool(cmd, "force-conflicts")
-	documentGroups := cmdutil.GetFlagStringArray(cmd, "document-groups")
+	options := &buildOptions{
+		apply:              cmdutil.GetFlagBool(cmd, "apply"),
+		skipConfirmation:   cmdutil.GetFlagBool(cmd, "yes"),
+		forceConflicts:     cmdutil.GetFlagBool(cmd, "force-conflicts"),
+		diffOnly:           cmdutil.GetFlagBool(cmd, "diff-only"),
+		compareOutput:      cmdutil.GetFlagBool(cmd, "compare-output"),
+		documentGroups:     cmdutil.GetFlagStringArray(cmd, "document-groups"),
+		maxConcurrency:     cmdutil.GetFlagInt(cmd, "max-concurrency"),
+		profile:            cmdutil.GetFlagBool(cmd, "profile"),
+		matrixFile:         cmdutil.GetFlagString(cmd, "matrix"),
+		failOn:             cmdutil.GetFlagString(cmd, "fail-on"),
+		failOnCategories:   cmdutil.GetFlagStringArray(cmd, "fail-on-category"),
+		diagnosticsFormats: cmdutil.GetFlagStringArray(cmd, "diagnostics-format"),
+		fix:                cmdutil.GetFlagBool(cmd, "fix"),
+		offline:            cmdutil.GetFlagBool(cmd, "offline"),
+
+		admissionPolicyFiles: cmdutil.GetFlagStringArray(cmd, "admission-policy"),
+
+		diagnosticsBaseline:       cmdutil.GetFlagString(cmd, "diagnostics-baseline"),
+		updateDiagnosticsBaseline: cmdutil.GetFlagBool(cmd, "update-diagnostics-baseline"),
+	}
+
+	if err := validateFailOn(options.failOn); err != nil {
+		return err
+	}
+
+	if options.updateDiagnosticsBaseline && options.diagnosticsBaseline == "" {
+		return fmt.Errorf("--update-diagnostics-baseline requires --diagnostics-baseline")
+	}
+
+	return runBuild(args, program, options)
+}
+
+func runBuild(args []string, program *AnemosProgram, options *buildOptions) error {
+	script, args, err := prepareScript(args, program)
+	if err != nil {
+		return err
+	}
 
+	if options.matrixFile != "" {
+		return runBuildMatrix(script, args, program, options)
+	}
+
+	numberOfChanges, err := runScript(script, args, program, options, nil)
+	if err != nil {
+		return err
+	}
+
+	if options.diffOnly && numberOfChanges > 0 {
+		return &ExitCodeError{
+			Code:    ExitCodeChangesDetected,
+			Message: fmt.Sprintf("%d changes detected", numberOfChanges),
+		}
+	}
+
+	return nil
+}
+
+// Resolves the main script from the first argument, compiles it if it is a TypeScript file and returns it
+// along with the remaining arguments.
+func prepareScript(args []string, program *AnemosProgram) (*js.JsScript, []string, error) {
 	var jsFile string
 	if len(args) > 0 {
 		jsFile = args[0]
 		args = args[1:]
 	} else {
-		return fmt.Errorf("no JS file provided")
+		return nil, nil, fmt.Errorf("no JS file provided")
 	}
 
 	jsFile, err := js.ResolvePath(jsFile, true)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	mainScriptPath := jsFile
@@ -60,7 +153,7 @@ func build(cmd *cobra.Command, args []string, program *AnemosProgram) error {
 
 		err = compileTypeScript(program, tsFile)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		compiledJsFile := fmt.Sprintf("%s.js", strings.TrimSuffix(filepath.Base(tsFile), filepath.Ext(tsFile)))
@@ -69,26 +162,87 @@ func build(cmd *cobra.Command, args []string, program *AnemosProgram) error {
 
 	scriptContents, err := os.ReadFile(jsFile)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %s, %w", jsFile, err)
+		return nil, nil, fmt.Errorf("failed to read file: %s, %w", jsFile, err)
 	}
 
+	script := &js.JsScript{
+		Contents:       string(scriptContents),
+		FilePath:       jsFile,
+		MainScriptPath: mainScriptPath,
+	}
+
+	return script, args, nil
+}
+
+// Runs the script in a new runtime and returns the number of changes that are detected by the diff.
+// Builders that are created by the script use the given target if it is not nil.
+func runScript(script *js.JsScript, args []string, program *AnemosProgram, options *buildOptions, target *core.BuildTarget) (int, error) {
 	runtime, err := InitializeNewRuntime(program)
+	if err != nil {
+		return 0, err
+	}
+
+	numberOfChanges := 0
+
+	runtime.BuilderDefaultsContext.Set("apply", options.apply && !options.diffOnly)
+	runtime.BuilderDefaultsContext.Set("skipConfirmation", options.skipConfirmation)
+	runtime.BuilderDefaultsContext.Set("forceConflicts", options.forceConflicts)
+	runtime.BuilderDefaultsContext.Set("documentGroups", options.documentGroups)
+	runtime.BuilderDefaultsContext.Set("maxConcurrency", options.maxConcurrency)
+	runtime.BuilderDefaultsContext.Set("diffOnly", options.diffOnly)
+	runtime.BuilderDefaultsContext.Set("compareOutput", options.compareOutput)
+	runtime.BuilderDefaultsContext.Set("profile", options.profile)
+	runtime.BuilderDefaultsContext.Set("failOn", options.failOn)
+	runtime.BuilderDefaultsContext.Set("failOnCategories", options.failOnCategories)
+	runtime.BuilderDefaultsContext.Set("diagnosticsFormats", options.diagnosticsFormats)
+	runtime.BuilderDefaultsContext.Set("fix", options.fix)
+	runtime.BuilderDefaultsContext.Set("admissionPolicyFiles", options.admissionPolicyFiles)
+	runtime.BuilderDefaultsContext.Set("diagnosticsBaseline", options.diagnosticsBaseline)
+	runtime.BuilderDefaultsContext.Set("updateDiagnosticsBaseline", options.updateDiagnosticsBaseline)
+	runtime.BuilderDefaultsContext.Set("onDiffCompleted", func(changes int) {
+		numberOfChanges += changes
+	})
+
+	if target != nil {
+		core.SetBuildTarget(runtime, target)
+	}
+
+	// Remote charts and modules are locked next to the main script.
+	err = runWithFetcher(runtime, script, args, filepath.Dir(script.MainScriptPath), options.offline)
+	if err != nil {
+		return 0, err
+	}
+
+	return numberOfChanges, nil
+}
+
+// Runs the script with a fetcher that uses the lockfile and the vendor directory in the given project directory,
+// so that the remote charts and modules are locked and verified by every command that runs a script.
+func runWithFetcher(runtime *js.JsRuntime, script *js.JsScript, args []string, projectDirectory string, offline bool) error {
+	fetcher, err := remote.NewFetcher(projectDirectory, offline)
 	if err != nil {
 		return err
 	}
 
-	runtime.BuilderDefaultsContext.Set("apply", apply)
-	runtime.BuilderDefaultsContext.Set("skipConfirmation", skipConfirmation)
-	runtime.BuilderDefaultsContext.Set("forceConflicts", forceConflicts)
-	runtime.BuilderDefaultsContext.Set("documentGroups", documentGroups)
+	runtime.Fetcher = fetcher
 
-	script := &js.JsScript{
-		Contents:       string(scriptContents),
-		FilePath:       jsFile,
-		MainScriptPath: mainScriptPath,
+	err = runtime.Run(script, args)
+
+	// Resources that are downloaded before a failure are valid, lock them anyway.
+	if saveErr := fetcher.Save(); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+
+	return err
+}
+
+// Returns an error if the given value of the --fail-on flag is not a diagnostic severity.
+func validateFailOn(failOn string) error {
+	if failOn != "" && core.DiagnosticSeverity(failOn).Level() < 0 {
+		return fmt.Errorf("invalid value for --fail-on: %s, must be one of info, warning or error", failOn)
 	}
 
-	return runtime.Run(script, args)
+	return nil
 }
 
 func InitializeNewRuntime(program *AnemosProgram) (*js.JsRuntime, error) {
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/components/writereports"
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"gopkg.in/yaml.v3"
+)
+
+const buildMatrixSummaryFileName = "matrix-summary"
+
+// buildMatrix is the contents of the file that is passed with the --matrix flag.
+type buildMatrix struct {
+	Targets []*core.BuildTarget `yaml:"targets"`
+}
+
+type buildMatrixResult struct {
+	target    *core.BuildTarget
+	manifests []*core.Manifest
+	err       error
+}
+
+func readBuildMatrix(path string) (*buildMatrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read matrix file %s: %w", path, err)
+	}
+
+	matrix := &buildMatrix{}
+	if err := yaml.Unmarshal(data, matrix); err != nil {
+		return nil, fmt.Errorf("failed to parse matrix file %s: %w", path, err)
+	}
+
+	if len(matrix.Targets) == 0 {
+		return nil, fmt.Errorf("matrix file %s doesn't contain any targets", path)
+	}
+
+	names := map[string]bool{}
+
+	for i, target := range matrix.Targets {
+		if target == nil || target.Name == "" {
+			return nil, fmt.Errorf("target %d in matrix file %s doesn't have a name", i, path)
+		}
+
+		// Names are used as output directory names.
+		if target.Name == "." || target.Name == ".." || strings.ContainsAny(target.Name, `/\`) {
+			return nil, fmt.Errorf("target name %s in matrix file %s is not a valid directory name", target.Name, path)
+		}
+
+		if names[target.Name] {
+			return nil, fmt.Errorf("target name %s in matrix file %s is not unique", target.Name, path)
+		}
+
+		names[target.Name] = true
+	}
+
+	return matrix, nil
+}
+
+// Builds the script once for each target in a new runtime, then writes a summary that compares the outputs of
+// the targets with the output of the first target.
+func runBuildMatrix(script *js.JsScript, args []string, program *AnemosProgram, options *buildOptions) error {
+	if options.apply || options.diffOnly {
+		return fmt.Errorf("matrix builds can't be applied, build the targets separately to apply them")
+	}
+
+	matrix, err := readBuildMatrix(options.matrixFile)
+	if err != nil {
+		return err
+	}
+
+	results := []*buildMatrixResult{}
+
+	for _, target := range matrix.Targets {
+		slog.Info("Building matrix target ${target}", slog.String("target", target.Name))
+
+		result := &buildMatrixResult{target: target}
+		results = append(results, result)
+
+		if _, err := runScript(script, args, program, options, target); err != nil {
+			result.err = err
+			continue
+		}
+
+		if target.BuilderOptions == nil {
+			result.err = fmt.Errorf("script didn't create a builder")
+			continue
+		}
+
+		manifestsPath := filepath.Join(target.BuilderOptions.OutputConfiguration.OutputPath, core.DocumentsDir)
+		result.manifests, result.err = core.ReadManifestsDirectory(manifestsPath)
+	}
+
+	errs := []error{}
+	var summaryDirectory string
+
+	for _, result := range results {
+		if result.err != nil {
+			slog.Error("Matrix target ${target} failed: ${error}",
+				slog.String("target", result.target.Name),
+				slog.String("error", result.err.Error()))
+
+			errs = append(errs, fmt.Errorf("target %s failed: %w", result.target.Name, result.err))
+			continue
+		}
+
+		if summaryDirectory == "" {
+			summaryDirectory = filepath.Dir(result.target.BuilderOptions.OutputConfiguration.OutputPath)
+		}
+	}
+
+	if summaryDirectory != "" {
+		if err := writeBuildMatrixSummary(summaryDirectory, createBuildMatrixSummary(results)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func writeBuildMatrixSummary(directory string, summary string) error {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return fmt.Errorf("can't create directory %s, %w", directory, err)
+	}
+
+	markdownPath := filepath.Join(directory, buildMatrixSummaryFileName+".md")
+	if err := os.WriteFile(markdownPath, []byte(summary), 0644); err != nil {
+		return fmt.Errorf("can't write matrix summary %s, %w", markdownPath, err)
+	}
+
+	htmlPath := filepath.Join(directory, buildMatrixSummaryFileName+".html")
+	if err := os.WriteFile(htmlPath, []byte(writereports.RenderMarkdown(summary, "Build Matrix Summary")), 0644); err != nil {
+		return fmt.Errorf("can't write matrix summary %s, %w", htmlPath, err)
+	}
+
+	slog.Info("Wrote build matrix summary to ${path}", slog.String("path", markdownPath))
+
+	return nil
+}
+
+func createBuildMatrixSummary(results []*buildMatrixResult) string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "# Build Matrix Summary\n\n")
+	fmt.Fprintf(builder, "| Target | Kubernetes Version | Distribution | Environment | Resources | Output |\n")
+	fmt.Fprintf(builder, "| --- | --- | --- | --- | ---: | --- |\n")
+
+	var baseline *buildMatrixResult
+
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(builder, "| %s | - | - | - | - | failed |\n", result.target.Name)
+			continue
+		}
+
+		if baseline == nil {
+			baseline = result
+		}
+
+		options := result.target.BuilderOptions
+
+		fmt.Fprintf(
+			builder,
+			"| %s | %s | %s | %s (%s) | %d | `%s` |\n",
+			result.target.Name,
+			options.KubernetesCluster.Version.String(),
+			options.KubernetesCluster.Distribution,
+			options.Environment.Name,
+			options.Environment.Type,
+			len(result.manifests),
+			filepath.ToSlash(options.OutputConfiguration.OutputPath))
+	}
+
+	fmt.Fprintf(builder, "\n")
+
+	if baseline == nil {
+		return builder.String()
+	}
+
+	for _, result := range results {
+		if result == baseline || result.err != nil {
+			continue
+		}
+
+		writeBuildMatrixDifferences(builder, baseline, result)
+	}
+
+	return builder.String()
+}
+
+func writeBuildMatrixDifferences(builder *strings.Builder, baseline *buildMatrixResult, result *buildMatrixResult) {
+	changes := core.CompareManifests(baseline.manifests, result.manifests)
+
+	fmt.Fprintf(builder, "## %s compared to %s\n\n", result.target.Name, baseline.target.Name)
+
+	if len(changes) == 0 {
+		fmt.Fprintf(builder, "Resources are identical.\n\n")
+		return
+	}
+
+	counts := map[core.ManifestChangeType]int{}
+	for _, change := range changes {
+		counts[change.Type]++
+	}
+
+	fmt.Fprintf(
+		builder,
+		"%d only in %s, %d only in %s, %d different.\n\n",
+		counts[core.ManifestChangeTypeAdded],
+		result.target.Name,
+		counts[core.ManifestChangeTypeRemoved],
+		baseline.target.Name,
+		counts[core.ManifestChangeTypeModified])
+
+	fmt.Fprintf(builder, "| Change | Resource |\n")
+	fmt.Fprintf(builder, "| --- | --- |\n")
+
+	for _, change := range changes {
+		fmt.Fprintf(builder, "| %s | `%s` |\n", change.Type, change.Identity)
+	}
+
+	fmt.Fprintf(builder, "\n")
+
+	for _, change := range changes {
+		if change.Type != core.ManifestChangeTypeModified {
+			continue
+		}
+
+		fmt.Fprintf(builder, "### `%s`\n\n", change.Identity)
+
+		for _, fieldChange := range change.FieldChanges {
+			fmt.Fprintf(builder, "- `%s`\n", fieldChange.String())
+		}
+
+		fmt.Fprintf(builder, "\n")
+	}
+}
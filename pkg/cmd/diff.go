@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/ohayocorp/anemos/pkg/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func getDiffCommand(program *AnemosProgram) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "diff [js_file|ts_file]",
+		Short: "Shows the changes that would be applied to the cluster without applying them.",
+		Long: util.Dedent(`
+			Builds the project and compares the generated manifests with the cluster state using
+			server-side dry-run. Lists the resources to be added, modified and pruned for each
+			document group. Nothing is changed on the cluster.
+
+			Exit codes:
+			  0: No changes
+			  1: An error occurred
+			  2: Changes detected
+			`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diff(cmd, args, program)
+		},
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	command.Flags().Bool("force-conflicts", false, "Compute the changes as if conflicts were forcefully resolved")
+	command.Flags().StringArrayP("document-groups", "d", nil, "Document groups to compare, other groups will be skipped")
+	command.Flags().Bool("offline", false, "Only use the vendored and cached remote charts and modules that are recorded in anemos.lock.")
+
+	return command
+}
+
+func diff(cmd *cobra.Command, args []string, program *AnemosProgram) error {
+	options := &buildOptions{
+		forceConflicts: cmdutil.GetFlagBool(cmd, "force-conflicts"),
+		documentGroups: cmdutil.GetFlagStringArray(cmd, "document-groups"),
+		diffOnly:       true,
+		offline:        cmdutil.GetFlagBool(cmd, "offline"),
+	}
+
+	return runBuild(args, program, options)
+}
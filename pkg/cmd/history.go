@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/ohayocorp/anemos/pkg/client"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type historyContext struct {
+	program      *AnemosProgram
+	applySetName string
+	namespace    string
+}
+
+func getHistoryCommand(program *AnemosProgram) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "history [apply-set-name]",
+		Short: "List applied revisions of an apply set",
+		Long: util.Dedent(`
+			List the revisions that were applied to the Kubernetes cluster for an apply set.
+			Revisions can be rolled back using the rollback command.
+			`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryCommand(cmd, args, program)
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	command.Flags().StringP("namespace", "", "", "Namespace of the apply set")
+
+	return command
+}
+
+func runHistoryCommand(cmd *cobra.Command, args []string, program *AnemosProgram) error {
+	namespace := cmdutil.GetFlagString(cmd, "namespace")
+
+	historyContext := &historyContext{
+		program:      program,
+		applySetName: args[0],
+		namespace:    namespace,
+	}
+
+	return listHistory(historyContext)
+}
+
+func listHistory(context *historyContext) error {
+	kubernetesClient, err := client.NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	return kubernetesClient.History(context.applySetName, context.namespace)
+}
@@ -12,6 +12,22 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+const (
+	// Exit code returned when the diff command detects changes on the cluster.
+	ExitCodeChangesDetected = 2
+)
+
+// ExitCodeError is returned from the commands that need to exit with a specific exit code
+// to signal a result instead of a failure, e.g. changes detected by the diff command.
+type ExitCodeError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Message
+}
+
 type AnemosProgram struct {
 	RootCommand               *cobra.Command
 	RegisterRuntimeCallback   func(runtime *js.JsRuntime) error
@@ -48,10 +64,15 @@ func Run(program *AnemosProgram) error {
 		getNewProjectCommand(program),
 		getWriteDeclarationsCommand(program),
 		getBuildCommand(program),
+		getDiffCommand(program),
+		getTestCommand(program),
 		getPackageCommand(program),
+		getVendorCommand(program),
 		getApplyCommand(program),
 		getDeleteCommand(program),
 		getListCommand(program),
+		getHistoryCommand(program),
+		getRollbackCommand(program),
 	)
 
 	return rootCmd.Execute()
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ohayocorp/anemos/pkg/client"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type rollbackContext struct {
+	program          *AnemosProgram
+	applySetName     string
+	namespace        string
+	revision         int
+	skipConfirmation bool
+	forceConflicts   bool
+	timeout          time.Duration
+}
+
+func getRollbackCommand(program *AnemosProgram) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "rollback [apply-set-name]",
+		Short: "Roll back an apply set to a previously applied revision",
+		Long: util.Dedent(`
+			Roll back an apply set to a previously applied revision. The manifests of the revision
+			are applied using server-side apply and the objects that are not part of the revision are pruned.
+			If no revision is specified, the apply set is rolled back to the revision before the latest one.
+			`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRollbackCommand(cmd, args, program)
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	command.Flags().StringP("namespace", "", "", "Namespace of the apply set")
+	command.Flags().IntP("revision", "r", 0, "Revision to roll back to, defaults to the previous revision")
+	command.Flags().BoolP("yes", "y", false, "Skip confirmation prompt and apply changes directly")
+	command.Flags().BoolP("force-conflicts", "", false, "Forcefully apply changes even if there are conflicts on server side apply")
+	command.Flags().StringP("timeout", "t", "5m0s", "Timeout for the rollback operation")
+
+	return command
+}
+
+func runRollbackCommand(cmd *cobra.Command, args []string, program *AnemosProgram) error {
+	skipConfirmation := cmdutil.GetFlagBool(cmd, "yes")
+	forceConflicts := cmdutil.GetFlagBool(cmd, "force-conflicts")
+	namespace := cmdutil.GetFlagString(cmd, "namespace")
+	revision := cmdutil.GetFlagInt(cmd, "revision")
+	timeoutString := cmdutil.GetFlagString(cmd, "timeout")
+	if timeoutString == "" {
+		timeoutString = "5m0s"
+	}
+
+	timeout, err := time.ParseDuration(timeoutString)
+	if err != nil {
+		return fmt.Errorf("invalid timeout value: %w", err)
+	}
+
+	if revision < 0 {
+		return fmt.Errorf("invalid revision value: %d", revision)
+	}
+
+	// Check if we should skip confirmation from environment variable.
+	if cmd.Flags().Lookup("yes") == nil {
+		_, skipConfirmation = os.LookupEnv("ANEMOS_APPLY_YES")
+	}
+
+	rollbackContext := &rollbackContext{
+		program:          program,
+		applySetName:     args[0],
+		namespace:        namespace,
+		revision:         revision,
+		skipConfirmation: skipConfirmation,
+		forceConflicts:   forceConflicts,
+		timeout:          timeout,
+	}
+
+	return rollback(rollbackContext)
+}
+
+func rollback(context *rollbackContext) error {
+	kubernetesClient, err := client.NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	return kubernetesClient.Rollback(
+		context.applySetName,
+		context.namespace,
+		context.revision,
+		context.skipConfirmation,
+		context.forceConflicts,
+		context.timeout)
+}
@@ -20,12 +20,29 @@ for (let i = builder.components.length - 1; i >= 0; i--) {
 // Options is passed from the native code.
 package.add(builder, options);
 
+// Trace file path is passed from the native code when profiling is enabled.
+if (profileTraceFilePath) {
+    builder.profile({
+        traceFilePath: profileTraceFilePath,
+    });
+}
+
+// Diagnostics are checked before applying only when a severity to fail on is passed from the native code.
+if (failOn) {
+    anemos.diagnostics.addDefaultDiagnostics(builder);
+    builder.reportDiagnostics({
+        failOn: failOn,
+        failOnCategories: failOnCategories,
+    });
+}
+
 // Add the apply component to the builder. Option variables are passed from the native code.
 builder.apply({
     skipConfirmation: skipConfirmation,
     forceConflicts: forceConflicts,
     namespace: namespace,
     documentGroups: documentGroups,
+    maxConcurrency: maxConcurrency,
 });
 
 builder.build();
\ No newline at end of file
@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	testSnapshotsDir            = "__snapshots__"
+	testDiagnosticsSnapshotFile = "diagnostics.yaml"
+)
+
+// Directories that are skipped while discovering the test files.
+var testSkippedDirs = []string{"node_modules", "dist", "output", testSnapshotsDir}
+
+func getTestCommand(program *AnemosProgram) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "test [paths...]",
+		Short: "Runs the snapshot tests of a project.",
+		Long: util.Dedent(`
+			Runs the snapshot tests of a project.
+
+			Test files are the files that end with .test.js or .test.ts under the given paths, which default to the
+			current directory. Each test file runs in a new runtime and the documents and diagnostics of each builder
+			it builds are compared with the snapshot under the __snapshots__ directory next to the test file.
+			Use --update to create or update the snapshots.
+			`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := &testOptions{
+				update:    cmdutil.GetFlagBool(cmd, "update"),
+				junitFile: cmdutil.GetFlagString(cmd, "junit"),
+				offline:   cmdutil.GetFlagBool(cmd, "offline"),
+			}
+
+			return runTests(args, program, options)
+		},
+	}
+
+	command.Flags().Bool("update", false, "Create or update the snapshots instead of comparing with them.")
+	command.Flags().String("junit", "", "Write the test results to the given file in JUnit XML format.")
+	command.Flags().Bool("offline", false, "Only use the vendored and cached remote charts and modules that are recorded in anemos.lock.")
+
+	return command
+}
+
+type testOptions struct {
+	update    bool
+	junitFile string
+	offline   bool
+}
+
+type testResult struct {
+	name     string
+	duration time.Duration
+	failures []string
+}
+
+// testSnapshot is the output of a single builder that is compared with the snapshot directory.
+type testSnapshot struct {
+	// Serialized documents keyed by their full paths.
+	documents   map[string]string
+	diagnostics []*testDiagnostic
+}
+
+type testDiagnostic struct {
+	Id       string                  `yaml:"id"`
+	Severity core.DiagnosticSeverity `yaml:"severity"`
+	Document string                  `yaml:"document,omitempty"`
+	Message  string                  `yaml:"message,omitempty"`
+}
+
+func (diagnostic *testDiagnostic) String() string {
+	if diagnostic.Document == "" {
+		return fmt.Sprintf("%s %s: %s", diagnostic.Severity, diagnostic.Id, diagnostic.Message)
+	}
+
+	return fmt.Sprintf("%s %s (%s): %s", diagnostic.Severity, diagnostic.Id, diagnostic.Document, diagnostic.Message)
+}
+
+func runTests(paths []string, program *AnemosProgram, options *testOptions) error {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	testFiles, err := findTestFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	if len(testFiles) == 0 {
+		return fmt.Errorf("no test files found under %s", strings.Join(paths, ", "))
+	}
+
+	results := []*testResult{}
+	failed := 0
+
+	for _, testFile := range testFiles {
+		result := runTestFile(testFile, program, options)
+		results = append(results, result)
+
+		if len(result.failures) == 0 {
+			slog.Info("PASS ${test} (${duration})",
+				slog.String("test", result.name),
+				slog.String("duration", result.duration.Round(time.Millisecond).String()))
+
+			continue
+		}
+
+		failed++
+
+		slog.Error("FAIL ${test} (${duration})\n${failures}",
+			slog.String("test", result.name),
+			slog.String("duration", result.duration.Round(time.Millisecond).String()),
+			slog.String("failures", strings.Join(result.failures, "\n")))
+	}
+
+	if options.junitFile != "" {
+		if err := writeJUnitReport(options.junitFile, results); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("${passed} passed, ${failed} failed",
+		slog.Int("passed", len(results)-failed),
+		slog.Int("failed", failed))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// Returns the test files under the given paths sorted by their paths. Paths that point to files are returned as is.
+func findTestFiles(paths []string) ([]string, error) {
+	testFiles := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't read test path %s, %w", path, err)
+		}
+
+		if !info.IsDir() {
+			testFiles = append(testFiles, path)
+			continue
+		}
+
+		root := path
+
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			name := entry.Name()
+
+			if entry.IsDir() {
+				if path != root && (strings.HasPrefix(name, ".") || slices.Contains(testSkippedDirs, name)) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if strings.HasSuffix(name, ".test.js") || strings.HasSuffix(name, ".test.ts") {
+				testFiles = append(testFiles, path)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.Sort(testFiles)
+
+	return slices.Compact(testFiles), nil
+}
+
+func runTestFile(testFile string, program *AnemosProgram, options *testOptions) *testResult {
+	result := &testResult{
+		name: filepath.ToSlash(testFile),
+	}
+
+	start := time.Now()
+	defer func() {
+		result.duration = time.Since(start)
+	}()
+
+	script, args, err := prepareScript([]string{testFile}, program)
+	if err != nil {
+		result.failures = append(result.failures, err.Error())
+		return result
+	}
+
+	snapshots, err := runTestScript(script, args, program, options)
+	if err != nil {
+		result.failures = append(result.failures, err.Error())
+		return result
+	}
+
+	if len(snapshots) == 0 {
+		result.failures = append(result.failures, "test didn't build any builders")
+		return result
+	}
+
+	name := filepath.Base(script.MainScriptPath)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".test.js"), ".test.ts")
+
+	for i, snapshot := range snapshots {
+		snapshotName := name
+		if i > 0 {
+			snapshotName = fmt.Sprintf("%s-%d", name, i+1)
+		}
+
+		snapshotDirectory := filepath.Join(filepath.Dir(script.MainScriptPath), testSnapshotsDir, snapshotName)
+
+		if options.update {
+			if err := writeTestSnapshot(snapshotDirectory, snapshot); err != nil {
+				result.failures = append(result.failures, err.Error())
+			}
+
+			continue
+		}
+
+		result.failures = append(result.failures, compareTestSnapshot(snapshotDirectory, snapshot)...)
+	}
+
+	return result
+}
+
+// Runs the script in a new runtime without writing any output and returns the output of the builders that are built.
+func runTestScript(script *js.JsScript, args []string, program *AnemosProgram, options *testOptions) ([]*testSnapshot, error) {
+	runtime, err := InitializeNewRuntime(program)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []*testSnapshot{}
+
+	runtime.BuilderDefaultsContext.Set("test", true)
+
+	core.SetBuilderObserver(runtime, &core.BuilderObserver{
+		OnBuilderCreated: func(builder *core.Builder) {
+			builder.OnStep(core.StepOutput, func(context *core.BuildContext) {
+				snapshots = append(snapshots, captureTestSnapshot(context))
+			})
+		},
+	})
+
+	// Remote charts and modules are locked next to the main script, the same way as the build command does.
+	if err := runWithFetcher(runtime, script, args, filepath.Dir(script.MainScriptPath), options.offline); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func captureTestSnapshot(context *core.BuildContext) *testSnapshot {
+	snapshot := &testSnapshot{
+		documents:   map[string]string{},
+		diagnostics: []*testDiagnostic{},
+	}
+
+	for _, document := range context.GetAllDocuments() {
+		yamlText, err := core.SerializeSobekObjectToYaml(context.JsRuntime, document.Object)
+		if err != nil {
+			js.Throw(fmt.Errorf("can't serialize document %s, %w", document.FullPath(), err))
+		}
+
+		snapshot.documents[document.FullPath()] = yamlText
+	}
+
+	for _, diagnostic := range context.GetAllDiagnostics() {
+		testDiagnostic := &testDiagnostic{
+			Id:       diagnostic.Metadata.Id,
+			Severity: diagnostic.Metadata.Severity,
+			Message:  diagnostic.Message,
+		}
+
+		if diagnostic.Document != nil {
+			testDiagnostic.Document = diagnostic.Document.FullPath()
+		}
+
+		snapshot.diagnostics = append(snapshot.diagnostics, testDiagnostic)
+	}
+
+	slices.SortStableFunc(snapshot.diagnostics, func(a, b *testDiagnostic) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return snapshot
+}
+
+func writeTestSnapshot(directory string, snapshot *testSnapshot) error {
+	if err := os.RemoveAll(directory); err != nil {
+		return fmt.Errorf("can't remove snapshot directory %s, %w", directory, err)
+	}
+
+	for path, yamlText := range snapshot.documents {
+		filePath := filepath.Join(directory, core.DocumentsDir, path)
+
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return fmt.Errorf("can't create directory %s, %w", filepath.Dir(filePath), err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(yamlText), 0644); err != nil {
+			return fmt.Errorf("can't write snapshot %s, %w", filePath, err)
+		}
+	}
+
+	if len(snapshot.diagnostics) == 0 {
+		return nil
+	}
+
+	data, err := yaml.Marshal(snapshot.diagnostics)
+	if err != nil {
+		return fmt.Errorf("can't serialize diagnostics, %w", err)
+	}
+
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return fmt.Errorf("can't create directory %s, %w", directory, err)
+	}
+
+	filePath := filepath.Join(directory, testDiagnosticsSnapshotFile)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("can't write snapshot %s, %w", filePath, err)
+	}
+
+	return nil
+}
+
+// Compares the snapshot with the snapshot directory and returns the differences as failure messages.
+func compareTestSnapshot(directory string, snapshot *testSnapshot) []string {
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		return []string{fmt.Sprintf("snapshot %s doesn't exist, run the tests with --update to create it", filepath.ToSlash(directory))}
+	}
+
+	failures := []string{}
+
+	previous, err := core.ReadManifestsDirectory(filepath.Join(directory, core.DocumentsDir))
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	current := []*core.Manifest{}
+	for _, path := range core.SortedKeys(snapshot.documents) {
+		manifests, err := core.ParseManifests(path, []byte(snapshot.documents[path]))
+		if err != nil {
+			return []string{err.Error()}
+		}
+
+		current = append(current, manifests...)
+	}
+
+	for _, change := range core.CompareManifests(previous, current) {
+		switch change.Type {
+		case core.ManifestChangeTypeAdded:
+			failures = append(failures, fmt.Sprintf("  + %s (%s) is not in the snapshot", change.Identity, change.Path))
+		case core.ManifestChangeTypeRemoved:
+			failures = append(failures, fmt.Sprintf("  - %s (%s) is in the snapshot but not generated", change.Identity, change.PreviousPath))
+		case core.ManifestChangeTypeModified:
+			lines := []string{fmt.Sprintf("  ~ %s (%s) differs from the snapshot", change.Identity, change.Path)}
+			for _, fieldChange := range change.FieldChanges {
+				lines = append(lines, fmt.Sprintf("      %s", fieldChange.String()))
+			}
+
+			failures = append(failures, strings.Join(lines, "\n"))
+		}
+	}
+
+	expectedDiagnostics := []*testDiagnostic{}
+
+	data, err := os.ReadFile(filepath.Join(directory, testDiagnosticsSnapshotFile))
+	if err != nil && !os.IsNotExist(err) {
+		return append(failures, fmt.Sprintf("can't read diagnostics snapshot, %v", err))
+	}
+
+	if err := yaml.Unmarshal(data, &expectedDiagnostics); err != nil {
+		return append(failures, fmt.Sprintf("can't parse diagnostics snapshot, %v", err))
+	}
+
+	expected := diagnosticStrings(expectedDiagnostics)
+	actual := diagnosticStrings(snapshot.diagnostics)
+
+	for _, diagnostic := range actual {
+		if !slices.Contains(expected, diagnostic) {
+			failures = append(failures, fmt.Sprintf("  + diagnostic is not in the snapshot: %s", diagnostic))
+		}
+	}
+
+	for _, diagnostic := range expected {
+		if !slices.Contains(actual, diagnostic) {
+			failures = append(failures, fmt.Sprintf("  - diagnostic is in the snapshot but not reported: %s", diagnostic))
+		}
+	}
+
+	return failures
+}
+
+func diagnosticStrings(diagnostics []*testDiagnostic) []string {
+	result := []string{}
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic.String())
+	}
+
+	return result
+}
+
+func writeJUnitReport(path string, results []*testResult) error {
+	suite := &util.JUnitTestSuite{
+		Name: "anemos",
+	}
+
+	for _, result := range results {
+		testCase := &util.JUnitTestCase{
+			Name:      result.name,
+			ClassName: "anemos",
+			Time:      util.FormatJUnitDuration(result.duration),
+		}
+
+		if len(result.failures) > 0 {
+			testCase.Failure = &util.JUnitFailure{
+				Message: strings.TrimSpace(strings.SplitN(result.failures[0], "\n", 2)[0]),
+				Content: strings.Join(result.failures, "\n"),
+			}
+		}
+
+		suite.AddTestCase(testCase)
+		suite.Duration += result.duration
+	}
+
+	return util.WriteJUnitReport(path, suite)
+}
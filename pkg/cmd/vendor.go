@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/remote"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func getVendorCommand(program *AnemosProgram) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "vendor [js_file|ts_file|directory]",
+		Short: "Copies the remote charts and modules of a project into the project.",
+		Long: util.Dedent(`
+			Copies the remote charts and modules that are recorded in anemos.lock into the vendor
+			directory next to the main script and removes the vendored files that are no longer
+			in the lockfile. Builds read the vendored files before the cache, so the project can
+			be built with --offline on machines that don't have access to the remote sources.
+
+			The lockfile is updated by the builds, build the project before vendoring to record
+			the remote charts and modules that it uses.
+			`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return vendor(cmd, args)
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	command.Flags().Bool("offline", false, "Only copy from the cache, fail if a resource is not in the cache.")
+
+	return command
+}
+
+func vendor(cmd *cobra.Command, args []string) error {
+	path, err := js.ResolvePath(args[0], false)
+	if err != nil {
+		return err
+	}
+
+	projectDirectory := path
+	if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+		projectDirectory = filepath.Dir(path)
+	}
+
+	fetcher, err := remote.NewFetcher(projectDirectory, cmdutil.GetFlagBool(cmd, "offline"))
+	if err != nil {
+		return err
+	}
+
+	entries := fetcher.LockFile.Entries()
+	if len(entries) == 0 {
+		slog.Info("No remote charts or modules in ${lockFile}, build the project first to record them",
+			slog.String("lockFile", fetcher.LockFile.Path))
+	}
+
+	err = fetcher.Vendor(func(entry *remote.LockEntry) ([]byte, error) {
+		slog.Info("Downloading ${url}", slog.String("url", entry.Resolved))
+
+		switch entry.Kind {
+		case remote.KindHelmChart:
+			return core.DownloadLockedChart(entry)
+		case remote.KindModule:
+			return remote.DownloadUrl(entry.Resolved)
+		default:
+			return nil, fmt.Errorf("unknown kind %s in %s", entry.Kind, fetcher.LockFile.Path)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Vendored ${count} remote charts and modules into ${directory}",
+		slog.Int("count", len(entries)),
+		slog.String("directory", fetcher.VendorDirectory))
+
+	return nil
+}
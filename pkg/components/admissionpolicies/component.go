@@ -0,0 +1,174 @@
+package admissionpolicies
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/admissionpolicy"
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/util"
+)
+
+const componentType = "admission-policies"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	if options == nil {
+		options = NewOptions()
+	}
+
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepDiagnose, component.evaluatePolicies)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+type buildObject struct {
+	document   *core.Document
+	content    map[string]any
+	apiVersion string
+	kind       string
+}
+
+func (component *component) evaluatePolicies(context *core.BuildContext) {
+	// Converting all documents is expensive, skip it if there is nothing to evaluate.
+	if len(component.options.Files) == 0 && !hasBindingDocument(context) {
+		return
+	}
+
+	evaluator := admissionpolicy.NewEvaluator()
+
+	for _, path := range component.options.Files {
+		if err := addPoliciesFromFile(evaluator, path); err != nil {
+			js.Throw(err)
+		}
+	}
+
+	objects := []*buildObject{}
+	for _, document := range context.GetAllDocuments() {
+		content := context.DocumentToMap(document)
+		apiVersion, _ := content["apiVersion"].(string)
+		kind, _ := content["kind"].(string)
+
+		if apiVersion == "" || kind == "" {
+			continue
+		}
+
+		if err := evaluator.Add(content); err != nil {
+			js.Throw(fmt.Errorf("invalid policy %s: %w", document.FullPath(), err))
+		}
+
+		objects = append(objects, &buildObject{
+			document:   document,
+			content:    content,
+			apiVersion: apiVersion,
+			kind:       kind,
+		})
+	}
+
+	if !evaluator.HasBindings() {
+		return
+	}
+
+	lookup := func(apiVersion string, kind string) []map[string]any {
+		result := []map[string]any{}
+		for _, object := range objects {
+			if object.apiVersion == apiVersion && object.kind == kind {
+				result = append(result, object.content)
+			}
+		}
+
+		return result
+	}
+
+	for _, object := range objects {
+		if admissionpolicy.IsPolicyObject(object.content) {
+			continue
+		}
+
+		// Custom resources are not in the resource info unless they are added explicitly.
+		metadata, _ := object.content["metadata"].(map[string]any)
+		_, hasNamespace := metadata["namespace"]
+		isNamespaced := hasNamespace || context.KubernetesResourceInfo.IsNamespaced(object.apiVersion, object.kind)
+
+		violations, err := evaluator.Evaluate(&admissionpolicy.Object{Content: object.content, IsNamespaced: isNamespaced}, lookup)
+		if err != nil {
+			js.Throw(fmt.Errorf("can't evaluate policies for %s: %w", object.document.FullPath(), err))
+		}
+
+		for _, violation := range violations {
+			context.AddDiagnostic(core.NewDiagnosticWithDocument(newDiagnosticMetadata(violation), violation.Message, object.document))
+		}
+	}
+}
+
+func hasBindingDocument(context *core.BuildContext) bool {
+	for _, document := range context.GetAllDocuments() {
+		kind := core.SobekObjectGetString(document.Object, "kind")
+		if kind != nil && *kind == "ValidatingAdmissionPolicyBinding" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policies don't have a severity, so the severity is derived from the validation actions of the binding.
+// Deny fails the request in the cluster, Warn returns a warning to the client and Audit only adds
+// an audit event.
+func newDiagnosticMetadata(violation *admissionpolicy.Violation) *core.DiagnosticMetadata {
+	severity := core.DiagnosticSeverityInfo
+
+	for _, action := range violation.ValidationActions {
+		actionSeverity := core.DiagnosticSeverityInfo
+
+		switch action {
+		case "Deny":
+			actionSeverity = core.DiagnosticSeverityError
+		case "Warn":
+			actionSeverity = core.DiagnosticSeverityWarning
+		}
+
+		if actionSeverity.IsAtLeast(severity) {
+			severity = actionSeverity
+		}
+	}
+
+	return core.NewDiagnosticMetadata(
+		violation.Policy,
+		violation.Policy,
+		fmt.Sprintf(
+			"Violation of the ValidatingAdmissionPolicy %s bound by %s with %s actions.",
+			violation.Policy, violation.Binding, strings.Join(violation.ValidationActions, ", ")),
+		severity,
+		[]core.DiagnosticCategory{core.DiagnosticCategoryPolicy})
+}
+
+// Adds the policies and bindings in the given YAML file. The file may contain multiple documents or a list,
+// e.g. the output of "kubectl get validatingadmissionpolicies -o yaml".
+func addPoliciesFromFile(evaluator *admissionpolicy.Evaluator, path string) error {
+	objects, err := util.ReadYamlObjects(path)
+	if err != nil {
+		return fmt.Errorf("can't read policy file %s: %w", path, err)
+	}
+
+	for _, object := range objects {
+		if err := evaluator.Add(object); err != nil {
+			return fmt.Errorf("invalid policy in file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package admissionpolicies
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("admissionPolicies", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"admissionPolicies",
+	).Fields(
+		js.Field("Files"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+		js.Constructor(reflect.ValueOf(NewOptionsWithFiles)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("checkAdmissionPolicies"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("checkAdmissionPolicies"),
+	)
+}
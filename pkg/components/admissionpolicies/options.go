@@ -0,0 +1,18 @@
+package admissionpolicies
+
+type Options struct {
+	// YAML files that contain ValidatingAdmissionPolicy and ValidatingAdmissionPolicyBinding objects, e.g. the
+	// policies that are already installed in the cluster. Policies in these files are used only for evaluation
+	// and are not added to the output.
+	Files []string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func NewOptionsWithFiles(files []string) *Options {
+	return &Options{
+		Files: files,
+	}
+}
@@ -1,6 +1,7 @@
 package apply
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
@@ -13,11 +14,17 @@ import (
 	"github.com/ohayocorp/anemos/pkg/core"
 	"github.com/ohayocorp/anemos/pkg/js"
 	"github.com/ohayocorp/anemos/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 )
 
-const componentType = "apply"
+const (
+	componentType = "apply"
+
+	// Default number of provisioners that can run at the same time.
+	defaultMaxConcurrency = 4
+)
 
 type component struct {
 	*core.Component
@@ -50,6 +57,14 @@ func (component *component) sanitizeOptions(context *core.BuildContext) {
 	if options.Timeout == 0 {
 		options.Timeout, _ = time.ParseDuration("5m0s")
 	}
+
+	if options.MaxConcurrency <= 0 {
+		options.MaxConcurrency = defaultMaxConcurrency
+	}
+
+	if options.RevisionHistoryLimit <= 0 {
+		options.RevisionHistoryLimit = client.DefaultRevisionHistoryLimit
+	}
 }
 
 func (component *component) apply(context *core.BuildContext) {
@@ -65,7 +80,6 @@ func (component *component) apply(context *core.BuildContext) {
 		js.Throw(fmt.Errorf("failed to get regex list: %w", err))
 	}
 
-	numberOfAppliedChanges := 0
 	documentGroups := context.GetDocumentGroups()
 
 	provisioners := []*core.Provisioner{}
@@ -84,6 +98,11 @@ func (component *component) apply(context *core.BuildContext) {
 
 	provisioners = getSortedProvisioners(provisioners)
 
+	if options.DiffOnly {
+		component.diff(context, kubernetesClient, provisioners)
+		return
+	}
+
 	slog.Info("Provision plan:")
 	for _, provisioner := range provisioners {
 		slog.Info(
@@ -92,70 +111,249 @@ func (component *component) apply(context *core.BuildContext) {
 			slog.String("applySetName", getApplySetName(provisioner.DocumentGroup)))
 	}
 
+	// Documents are sorted and serialized up front since the JavaScript runtime can't be used
+	// from the goroutines that run the provisioners.
+	tasks := []*provisionerTask{}
 	for _, provisioner := range provisioners {
 		documentGroup := provisioner.DocumentGroup
 		documents := getSortedDocuments(documentGroup)
 
-		if len(documents) == 0 {
-			slog.Info("No documents to apply in document group: ${path}", slog.String("path", documentGroup.Path))
-			continue
-		}
+		tasks = append(tasks, &provisionerTask{
+			provisioner:   provisioner,
+			applySetName:  getApplySetName(documentGroup),
+			documents:     documents,
+			documentYamls: serializeDocuments(context, documents),
+		})
+	}
 
-		documentYamls := make([]string, 0, len(documents))
-		for _, document := range documents {
-			yaml, err := core.SerializeSobekObjectToYaml(context.JsRuntime, document.Object)
-			if err != nil {
-				js.Throw(fmt.Errorf("failed to serialize document to YAML: %w", err))
-			}
+	maxConcurrency := options.MaxConcurrency
+	if !options.SkipConfirmation {
+		// Confirmation prompts read from the standard input, provisioners must run one by one.
+		maxConcurrency = 1
+	}
+
+	revisionMetadata := component.getRevisionMetadata(context)
+
+	results := runProvisionerTasks(tasks, maxConcurrency, func(task *provisionerTask, logger *slog.Logger) error {
+		return component.runProvisionerTask(kubernetesClient.WithLogger(logger), task, revisionMetadata, logger)
+	})
 
-			documentYamls = append(documentYamls, yaml)
+	numberOfAppliedChanges := 0
+	errs := []error{}
+
+	for _, result := range results {
+		task := result.task
+
+		switch {
+		case result.skipped:
+			slog.Warn(
+				"Skipped ${type} -> ${applySetName} since one of its prerequisites has failed",
+				slog.Any("type", task.provisioner.Type),
+				slog.String("applySetName", task.applySetName))
+		case result.err != nil:
+			errs = append(errs, result.err)
+		case task.provisioner.Type == core.ProvisionerTypeApply && len(task.documents) > 0:
+			numberOfAppliedChanges++
 		}
+	}
 
-		switch provisioner.Type {
-		case core.ProvisionerTypeApply:
-			applySetName := getApplySetName(documentGroup)
+	if len(errs) > 0 {
+		js.Throw(errors.Join(errs...))
+	}
 
-			slog.Info("")
-			slog.Info("Applying document group: ${applySetName}", slog.String("applySetName", applySetName))
+	if numberOfAppliedChanges == 0 {
+		return
+	}
+
+	slog.Info("Successfully applied Kubernetes manifests")
+}
+
+// Runs a single provisioner. Called concurrently for the provisioners that don't depend on each other,
+// so all output must go through the given logger.
+func (component *component) runProvisionerTask(
+	kubernetesClient *client.KubernetesClient,
+	task *provisionerTask,
+	revisionMetadata client.RevisionMetadata,
+	logger *slog.Logger,
+) error {
+	options := component.options
+	documentGroup := task.provisioner.DocumentGroup
+	applySetName := task.applySetName
+
+	if len(task.documents) == 0 {
+		logger.Info("No documents to apply in document group: ${path}", slog.String("path", documentGroup.Path))
+		return nil
+	}
+
+	switch task.provisioner.Type {
+	case core.ProvisionerTypeApply:
+		logger.Info("")
+		logger.Info("Applying document group: ${applySetName}", slog.String("applySetName", applySetName))
+
+		logger.Debug("Document apply order:")
+
+		for _, document := range task.documents {
+			logger.Debug("  ${path}", slog.String("path", document.GetPath()))
+		}
 
-			slog.Debug("Document apply order:")
+		err := kubernetesClient.Apply(
+			task.documentYamls,
+			applySetName,
+			"",
+			options.SkipConfirmation,
+			options.ForceConflicts,
+			options.Timeout)
 
-			for _, document := range documents {
-				slog.Debug("  ${path}", slog.String("path", document.GetPath()))
+		if err != nil {
+			if _, ok := err.(client.NoChangesError); !ok {
+				return fmt.Errorf("failed to apply document group '%s': %w", applySetName, err)
 			}
 
-			err := kubernetesClient.Apply(
-				documentYamls,
-				applySetName,
-				"",
-				options.SkipConfirmation,
-				options.ForceConflicts,
-				options.Timeout)
+			logger.Info("No changes to apply for document group ${applySetName}", slog.String("applySetName", applySetName))
+		} else {
+			revisionMetadata.DocumentGroup = documentGroup.Path
 
+			err := component.saveRevision(kubernetesClient, applySetName, task.documentYamls, &revisionMetadata, logger)
 			if err != nil {
-				if _, ok := err.(client.NoChangesError); ok {
-					slog.Info("No changes to apply for document group ${applySetName}", slog.String("applySetName", applySetName))
-				} else {
-					js.Throw(fmt.Errorf("failed to apply document group '%s': %w", applySetName, err))
-				}
+				return err
 			}
+		}
 
-			slog.Info("Successfully applied document group: ${applySetName}", slog.String("applySetName", applySetName))
+		logger.Info("Successfully applied document group: ${applySetName}", slog.String("applySetName", applySetName))
+	case core.ProvisionerTypeWait:
+		logger.Info("")
+		logger.Info("Waiting for document group: ${applySetName}", slog.String("applySetName", applySetName))
 
-			numberOfAppliedChanges++
-		case core.ProvisionerTypeWait:
-			err = kubernetesClient.WaitDocuments(documentYamls, status.CurrentStatus, options.Timeout)
-			if err != nil {
-				js.Throw(fmt.Errorf("failed to wait for Kubernetes manifests: %w", err))
+		err := kubernetesClient.WaitDocuments(task.documentYamls, status.CurrentStatus, options.Timeout)
+		if err != nil {
+			if hookErr := kubernetesClient.DeleteHooks(task.documentYamls, release.HookFailed, options.Timeout); hookErr != nil {
+				err = errors.Join(err, hookErr)
 			}
+
+			return fmt.Errorf("failed to wait for document group '%s': %w", applySetName, err)
+		}
+
+		// Helm hooks with the hook-succeeded delete policy are removed once they are completed, they are
+		// created again on the next apply.
+		if err := kubernetesClient.DeleteHooks(task.documentYamls, release.HookSucceeded, options.Timeout); err != nil {
+			return fmt.Errorf("failed to delete hooks of document group '%s': %w", applySetName, err)
 		}
 	}
 
-	if numberOfAppliedChanges == 0 {
-		return
+	return nil
+}
+
+// Returns the metadata that is stored with each revision that is applied by this component.
+func (component *component) getRevisionMetadata(context *core.BuildContext) client.RevisionMetadata {
+	metadata := client.RevisionMetadata{
+		AnemosVersion: util.AppVersion,
+		Source:        context.JsRuntime.MainScriptPath,
 	}
 
-	slog.Info("Successfully applied Kubernetes manifests")
+	if environment := context.BuilderOptions.Environment; environment != nil {
+		metadata.Environment = environment.Name
+		metadata.EnvironmentType = string(environment.Type)
+	}
+
+	return metadata
+}
+
+// Records the applied documents as a new revision of the apply set so that they can be rolled back later.
+func (component *component) saveRevision(
+	kubernetesClient *client.KubernetesClient,
+	applySetName string,
+	documentYamls []string,
+	metadata *client.RevisionMetadata,
+	logger *slog.Logger,
+) error {
+	revision, err := kubernetesClient.SaveRevision(
+		applySetName,
+		"",
+		documentYamls,
+		metadata,
+		component.options.RevisionHistoryLimit)
+
+	if err != nil {
+		return fmt.Errorf("failed to save revision for document group '%s': %w", applySetName, err)
+	}
+
+	logger.Debug(
+		"Saved revision ${revision} for apply set ${applySetName}",
+		slog.Int("revision", revision.Number),
+		slog.String("applySetName", applySetName))
+
+	return nil
+}
+
+// Computes the changes for each document group using server-side dry-run without modifying the cluster.
+// Wait provisioners are skipped since nothing is applied.
+func (component *component) diff(context *core.BuildContext, kubernetesClient *client.KubernetesClient, provisioners []*core.Provisioner) {
+	options := component.options
+	summary := map[client.DiffType]int{}
+
+	for _, provisioner := range provisioners {
+		if provisioner.Type != core.ProvisionerTypeApply {
+			continue
+		}
+
+		documentGroup := provisioner.DocumentGroup
+		documents := getSortedDocuments(documentGroup)
+		applySetName := getApplySetName(documentGroup)
+
+		if len(documents) == 0 {
+			slog.Info("No documents to diff in document group: ${path}", slog.String("path", documentGroup.Path))
+			continue
+		}
+
+		slog.Info("")
+		slog.Info("Computing changes for document group: ${applySetName}", slog.String("applySetName", applySetName))
+
+		diffs, err := kubernetesClient.Diff(
+			serializeDocuments(context, documents),
+			applySetName,
+			"",
+			options.ForceConflicts)
+
+		if err != nil {
+			js.Throw(fmt.Errorf("failed to compute changes for document group '%s': %w", applySetName, err))
+		}
+
+		if len(diffs) == 0 {
+			slog.Info("No changes for document group ${applySetName}", slog.String("applySetName", applySetName))
+			continue
+		}
+
+		for _, diff := range diffs {
+			summary[diff.DiffType]++
+		}
+	}
+
+	numberOfChanges := summary[client.DiffTypeAdded] + summary[client.DiffTypeModified] + summary[client.DiffTypeDeleted]
+
+	slog.Info("")
+	slog.Info(
+		"Total changes: ${added} to add, ${modified} to modify, ${deleted} to delete",
+		slog.Int("added", summary[client.DiffTypeAdded]),
+		slog.Int("modified", summary[client.DiffTypeModified]),
+		slog.Int("deleted", summary[client.DiffTypeDeleted]))
+
+	if options.OnDiffCompleted != nil {
+		options.OnDiffCompleted(numberOfChanges)
+	}
+}
+
+func serializeDocuments(context *core.BuildContext, documents []*core.Document) []string {
+	documentYamls := make([]string, 0, len(documents))
+	for _, document := range documents {
+		yaml, err := core.SerializeSobekObjectToYaml(context.JsRuntime, document.Object)
+		if err != nil {
+			js.Throw(fmt.Errorf("failed to serialize document to YAML: %w", err))
+		}
+
+		documentYamls = append(documentYamls, yaml)
+	}
+
+	return documentYamls
 }
 
 func getSortedProvisioners(provisioners []*core.Provisioner) []*core.Provisioner {
@@ -16,6 +16,10 @@ func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
 		js.Field("DocumentGroups"),
 		js.Field("SkipConfirmation"),
 		js.Field("ForceConflicts"),
+		js.Field("MaxConcurrency"),
+		js.Field("RevisionHistoryLimit"),
+		js.Field("DiffOnly"),
+		js.Field("OnDiffCompleted"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewOptions)),
 	)
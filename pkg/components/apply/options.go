@@ -9,6 +9,18 @@ type Options struct {
 	SkipConfirmation bool
 	ForceConflicts   bool
 	Timeout          time.Duration
+
+	// Maximum number of provisioners that run at the same time. Provisioners only run concurrently when
+	// they don't depend on each other and SkipConfirmation is set. Defaults to 4.
+	MaxConcurrency int
+
+	// Maximum number of applied revisions that are kept for each apply set. Defaults to 10.
+	RevisionHistoryLimit int
+
+	// Only computes and prints the changes using server-side dry-run, nothing is applied to the cluster.
+	DiffOnly bool
+	// Called with the total number of changes after the changes are computed when DiffOnly is set.
+	OnDiffCompleted func(numberOfChanges int)
 }
 
 func NewOptions() *Options {
@@ -0,0 +1,240 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+type provisionerTask struct {
+	provisioner   *core.Provisioner
+	applySetName  string
+	documents     []*core.Document
+	documentYamls []string
+}
+
+type provisionerResult struct {
+	task    *provisionerTask
+	err     error
+	skipped bool
+}
+
+// Runs the given tasks while respecting the dependencies between their provisioners. Tasks whose
+// prerequisites are completed run concurrently, at most maxConcurrency at a time. When a task fails,
+// the tasks that depend on it directly or indirectly are skipped, other tasks continue to run.
+//
+// Tasks must be topologically sorted, ready tasks are started in the given order. When more than one
+// task can run at a time, log output of each task is buffered and written as a whole once the task completes
+// so that the output of different apply sets is not interleaved. Returned results are in the order of the tasks,
+// tasks that can't run because of a dependency cycle are returned with an error.
+func runProvisionerTasks(
+	tasks []*provisionerTask,
+	maxConcurrency int,
+	run func(task *provisionerTask, logger *slog.Logger) error,
+) []*provisionerResult {
+	maxConcurrency = max(maxConcurrency, 1)
+
+	indices := map[*core.Provisioner]int{}
+	for i, task := range tasks {
+		indices[task.provisioner] = i
+	}
+
+	// Dependencies can be declared on either side, collect both into a single graph.
+	dependents := make([][]int, len(tasks))
+	remainingPrerequisites := make([]int, len(tasks))
+
+	addEdge := func(prerequisite *core.Provisioner, dependent *core.Provisioner) {
+		from, ok := indices[prerequisite]
+		if !ok {
+			return
+		}
+
+		to, ok := indices[dependent]
+		if !ok || slices.Contains(dependents[from], to) {
+			return
+		}
+
+		dependents[from] = append(dependents[from], to)
+		remainingPrerequisites[to]++
+	}
+
+	for _, task := range tasks {
+		for _, prerequisite := range task.provisioner.Dependencies.Prerequisites {
+			addEdge(prerequisite, task.provisioner)
+		}
+
+		for _, dependent := range task.provisioner.Dependencies.Dependents {
+			addEdge(task.provisioner, dependent)
+		}
+	}
+
+	results := make([]*provisionerResult, len(tasks))
+	ready := []int{}
+
+	for i := range tasks {
+		if remainingPrerequisites[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	type completion struct {
+		index  int
+		err    error
+		logger *bufferedLogHandler
+	}
+
+	completions := make(chan completion)
+	running := 0
+	completed := 0
+
+	var skip func(index int)
+	skip = func(index int) {
+		for _, dependent := range dependents[index] {
+			if results[dependent] != nil {
+				continue
+			}
+
+			results[dependent] = &provisionerResult{task: tasks[dependent], skipped: true}
+			completed++
+
+			skip(dependent)
+		}
+	}
+
+	for completed < len(tasks) {
+		for running < maxConcurrency && len(ready) > 0 {
+			index := ready[0]
+			ready = ready[1:]
+			running++
+
+			var handler *bufferedLogHandler
+			logger := slog.Default()
+
+			if maxConcurrency > 1 {
+				handler = newBufferedLogHandler(slog.Default().Handler())
+				logger = slog.New(handler)
+			}
+
+			go func() {
+				err := run(tasks[index], logger)
+				completions <- completion{index: index, err: err, logger: handler}
+			}()
+		}
+
+		if running == 0 {
+			// Remaining tasks can't be started, which can only happen if there is a dependency cycle.
+			// Provisioners are sorted beforehand, which already fails in that case. Remaining tasks are
+			// reported as failed below.
+			break
+		}
+
+		completion := <-completions
+		running--
+		completed++
+
+		if completion.logger != nil {
+			completion.logger.flush()
+		}
+
+		results[completion.index] = &provisionerResult{task: tasks[completion.index], err: completion.err}
+
+		if completion.err != nil {
+			skip(completion.index)
+			continue
+		}
+
+		for _, dependent := range dependents[completion.index] {
+			if results[dependent] != nil {
+				continue
+			}
+
+			remainingPrerequisites[dependent]--
+			if remainingPrerequisites[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+
+		// Keep the original order among the ready tasks.
+		slices.Sort(ready)
+	}
+
+	for i, result := range results {
+		if result == nil {
+			results[i] = &provisionerResult{
+				task: tasks[i],
+				err:  fmt.Errorf("%s -> %s has a dependency cycle", tasks[i].provisioner.Type, tasks[i].applySetName),
+			}
+		}
+	}
+
+	return results
+}
+
+// Collects the log records of a provisioner that runs concurrently with others so that they can be
+// written together once the provisioner completes.
+type bufferedLogHandler struct {
+	handler slog.Handler
+	records *[]bufferedLogRecord
+	lock    *sync.Mutex
+}
+
+type bufferedLogRecord struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+func newBufferedLogHandler(handler slog.Handler) *bufferedLogHandler {
+	return &bufferedLogHandler{
+		handler: handler,
+		records: &[]bufferedLogRecord{},
+		lock:    &sync.Mutex{},
+	}
+}
+
+func (handler *bufferedLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.handler.Enabled(ctx, level)
+}
+
+func (handler *bufferedLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	*handler.records = append(*handler.records, bufferedLogRecord{
+		handler: handler.handler,
+		record:  record.Clone(),
+	})
+
+	return nil
+}
+
+func (handler *bufferedLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &bufferedLogHandler{
+		handler: handler.handler.WithAttrs(attrs),
+		records: handler.records,
+		lock:    handler.lock,
+	}
+}
+
+func (handler *bufferedLogHandler) WithGroup(name string) slog.Handler {
+	return &bufferedLogHandler{
+		handler: handler.handler.WithGroup(name),
+		records: handler.records,
+		lock:    handler.lock,
+	}
+}
+
+// Writes the collected records to the underlying handlers.
+func (handler *bufferedLogHandler) flush() {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	for _, buffered := range *handler.records {
+		buffered.handler.Handle(context.Background(), buffered.record)
+	}
+
+	*handler.records = nil
+}
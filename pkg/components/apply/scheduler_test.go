@@ -0,0 +1,166 @@
+package apply
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func newTestTasks(names ...string) []*provisionerTask {
+	tasks := []*provisionerTask{}
+	for _, name := range names {
+		tasks = append(tasks, &provisionerTask{
+			provisioner: &core.Provisioner{
+				Type:         core.ProvisionerTypeApply,
+				Dependencies: core.NewDependencies[*core.Provisioner](),
+			},
+			applySetName: name,
+		})
+	}
+
+	return tasks
+}
+
+// Runs the tasks and returns the names of the tasks in the order they are started.
+func runTestTasks(tasks []*provisionerTask, maxConcurrency int, failing ...string) ([]*provisionerResult, []string) {
+	lock := sync.Mutex{}
+	started := []string{}
+
+	results := runProvisionerTasks(tasks, maxConcurrency, func(task *provisionerTask, logger *slog.Logger) error {
+		lock.Lock()
+		started = append(started, task.applySetName)
+		lock.Unlock()
+
+		if slices.Contains(failing, task.applySetName) {
+			return fmt.Errorf("%s failed", task.applySetName)
+		}
+
+		return nil
+	})
+
+	return results, started
+}
+
+func TestRunProvisionerTasksOrder(t *testing.T) {
+	tasks := newTestTasks("namespace", "database", "app", "monitoring")
+
+	tasks[1].provisioner.RunAfter(tasks[0].provisioner)
+	tasks[0].provisioner.RunBefore(tasks[2].provisioner)
+	tasks[2].provisioner.RunAfter(tasks[1].provisioner)
+
+	results, started := runTestTasks(tasks, 1)
+
+	expected := []string{"namespace", "database", "app", "monitoring"}
+	if !slices.Equal(started, expected) {
+		t.Errorf("expected tasks to start in order %v, got %v", expected, started)
+	}
+
+	for i, result := range results {
+		if result.task != tasks[i] || result.err != nil || result.skipped {
+			t.Errorf("unexpected result for %s: %+v", tasks[i].applySetName, result)
+		}
+	}
+}
+
+func TestRunProvisionerTasksConcurrency(t *testing.T) {
+	tasks := newTestTasks("first", "second", "third", "dependent")
+	for _, task := range tasks[:3] {
+		tasks[3].provisioner.RunAfter(task.provisioner)
+	}
+
+	// Independent tasks block until all of them are running, which only completes if they run concurrently.
+	barrier := sync.WaitGroup{}
+	barrier.Add(3)
+
+	lock := sync.Mutex{}
+	running, maxRunning := 0, 0
+
+	results := runProvisionerTasks(tasks, 3, func(task *provisionerTask, logger *slog.Logger) error {
+		lock.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		current := running
+		lock.Unlock()
+
+		if task.applySetName != "dependent" {
+			barrier.Done()
+			barrier.Wait()
+		} else if current != 1 {
+			t.Errorf("expected dependent task to run after its prerequisites")
+		}
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		return nil
+	})
+
+	if maxRunning != 3 {
+		t.Errorf("expected 3 tasks to run concurrently, got %d", maxRunning)
+	}
+
+	for _, result := range results {
+		if result.err != nil || result.skipped {
+			t.Errorf("unexpected result for %s: %+v", result.task.applySetName, result)
+		}
+	}
+}
+
+func TestRunProvisionerTasksFailure(t *testing.T) {
+	tasks := newTestTasks("database", "migrations", "app", "monitoring")
+
+	tasks[1].provisioner.RunAfter(tasks[0].provisioner)
+	tasks[2].provisioner.RunAfter(tasks[1].provisioner)
+
+	results, started := runTestTasks(tasks, 2, "database")
+
+	if !slices.Equal(started, []string{"database", "monitoring"}) && !slices.Equal(started, []string{"monitoring", "database"}) {
+		t.Errorf("expected only database and monitoring to run, got %v", started)
+	}
+
+	if results[0].err == nil {
+		t.Errorf("expected database to fail")
+	}
+
+	for _, result := range results[1:3] {
+		if !result.skipped {
+			t.Errorf("expected %s to be skipped since its prerequisite failed", result.task.applySetName)
+		}
+	}
+
+	if results[3].err != nil || results[3].skipped {
+		t.Errorf("expected independent task to succeed, got %+v", results[3])
+	}
+}
+
+func TestRunProvisionerTasksCycle(t *testing.T) {
+	tasks := newTestTasks("first", "second", "independent")
+
+	tasks[0].provisioner.RunAfter(tasks[1].provisioner)
+	tasks[1].provisioner.RunAfter(tasks[0].provisioner)
+
+	results, started := runTestTasks(tasks, 4)
+
+	if !slices.Equal(started, []string{"independent"}) {
+		t.Errorf("expected only the independent task to run, got %v", started)
+	}
+
+	if len(results) != len(tasks) {
+		t.Fatalf("expected a result for each task, got %d results", len(results))
+	}
+
+	for _, result := range results[:2] {
+		if result.err == nil {
+			t.Errorf("expected %s to fail because of the dependency cycle", result.task.applySetName)
+		}
+	}
+
+	if results[2].err != nil {
+		t.Errorf("expected independent task to succeed, got %v", results[2].err)
+	}
+}
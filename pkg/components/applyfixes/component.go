@@ -0,0 +1,122 @@
+package applyfixes
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+const componentType = "apply-fixes"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Fixes are applied after all diagnostics are added and before the baseline and the reports so that
+	// the fixed diagnostics are neither reported nor fail the build.
+	component.AddAction(core.NewStep("Apply diagnostic fixes", append(core.StepReport.Numbers, -2)...), component.applyFixes)
+	component.AddAction(core.StepReport, component.report)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	if component.options == nil {
+		component.options = NewOptions()
+	}
+}
+
+func (component *component) applyFixes(context *core.BuildContext) {
+	for _, diagnostic := range context.GetAllDiagnostics() {
+		if diagnostic.Fix == nil || diagnostic.Fix.Apply == nil {
+			continue
+		}
+
+		if len(component.options.Ids) > 0 && !slices.Contains(component.options.Ids, diagnostic.Metadata.Id) {
+			continue
+		}
+
+		context.FixDiagnostic(diagnostic)
+	}
+
+	if fixed := len(context.GetFixedDiagnostics()); fixed > 0 {
+		slog.Info("Applied fixes of ${count} diagnostics", slog.Int("count", fixed))
+	}
+}
+
+func (component *component) report(context *core.BuildContext) {
+	diagnostics := context.GetFixedDiagnostics()
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	getDocumentPath := func(diagnostic *core.Diagnostic) string {
+		if diagnostic.Document == nil {
+			return ""
+		}
+
+		return diagnostic.Document.FullPath()
+	}
+
+	slices.SortStableFunc(diagnostics, func(a, b *core.Diagnostic) int {
+		if result := strings.Compare(a.Metadata.Name, b.Metadata.Name); result != 0 {
+			return result
+		}
+
+		return strings.Compare(getDocumentPath(a), getDocumentPath(b))
+	})
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "# Applied Fixes\n\n")
+	fmt.Fprintf(builder, "Fixes of %d diagnostics are applied to the documents.\n\n", len(diagnostics))
+
+	previousName := ""
+	previousDocument := ""
+	firstDocument := true
+
+	for _, diagnostic := range diagnostics {
+		if name := diagnostic.Metadata.Name; name != previousName {
+			if previousName != "" {
+				builder.WriteString("\n")
+			}
+
+			fmt.Fprintf(builder, "## %s\n", name)
+			previousName = name
+			previousDocument = ""
+			firstDocument = true
+		}
+
+		if document := getDocumentPath(diagnostic); document != previousDocument || firstDocument {
+			fmt.Fprintf(builder, "\n#### `%s`\n", document)
+			previousDocument = document
+			firstDocument = false
+		}
+
+		line := diagnostic.Fix.Description
+		if line == "" {
+			line = "Fixed"
+		}
+
+		if diagnostic.Message != "" {
+			line = fmt.Sprintf("%s (%s)", line, diagnostic.Message)
+		}
+
+		fmt.Fprintf(builder, "- %s\n", line)
+	}
+
+	context.AddReport(core.NewReport(core.NewReportMetadata("fixes.md"), builder.String()))
+}
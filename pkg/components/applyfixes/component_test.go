@@ -0,0 +1,106 @@
+package applyfixes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// Runs a build that adds a diagnostic with a fix for each of the given ids and a diagnostic without a fix.
+// Returns the build context and the ids of the diagnostics whose fixes are applied.
+func runTestBuild(t *testing.T, options *Options, ids ...string) (*core.BuildContext, []string) {
+	t.Helper()
+
+	builder := core.NewEmptyBuilder(&core.BuilderOptions{
+		OutputConfiguration: &core.OutputConfiguration{OutputPath: t.TempDir()},
+	}, js.NewJsRuntime())
+
+	builder.AddComponent(NewComponent(options))
+
+	applied := []string{}
+
+	builder.OnModify(func(context *core.BuildContext) {
+		for _, id := range ids {
+			metadata := core.NewDiagnosticMetadata(id, strings.ToUpper(id), "", core.DiagnosticSeverityWarning, nil)
+
+			diagnostic := core.NewDiagnostic(metadata, "")
+			diagnostic.Fix = core.NewDiagnosticFix("Fix "+id, func(context *core.BuildContext) {
+				applied = append(applied, id)
+			})
+
+			context.AddDiagnostic(diagnostic)
+		}
+
+		metadata := core.NewDiagnosticMetadata("no-fix", "No Fix", "", core.DiagnosticSeverityWarning, nil)
+		context.AddDiagnostic(core.NewDiagnostic(metadata, ""))
+	})
+
+	var result *core.BuildContext
+	builder.OnStep(core.StepOutput, func(context *core.BuildContext) {
+		result = context
+	})
+
+	builder.Build()
+
+	return result, applied
+}
+
+func getReport(context *core.BuildContext, path string) *core.Report {
+	for _, report := range context.GetAllReports() {
+		if report.Metadata.FilePath == path {
+			return report
+		}
+	}
+
+	return nil
+}
+
+func TestApplyFixes(t *testing.T) {
+	context, applied := runTestBuild(t, nil, "missing-labels", "run-as-root")
+
+	if strings.Join(applied, ",") != "missing-labels,run-as-root" {
+		t.Errorf("expected fixes of all diagnostics to be applied, got %v", applied)
+	}
+
+	if fixed := len(context.GetFixedDiagnostics()); fixed != 2 {
+		t.Errorf("expected 2 fixed diagnostics, got %d", fixed)
+	}
+
+	remaining := context.GetAllDiagnostics()
+	if len(remaining) != 1 || remaining[0].Metadata.Id != "no-fix" {
+		t.Errorf("expected only the diagnostic without a fix to remain, got %v", remaining)
+	}
+
+	report := getReport(context, "fixes.md")
+	if report == nil {
+		t.Fatal("expected fixes.md report")
+	}
+
+	for _, expected := range []string{"## MISSING-LABELS", "- Fix missing-labels", "## RUN-AS-ROOT", "- Fix run-as-root"} {
+		if !strings.Contains(report.MarkdownContent, expected) {
+			t.Errorf("expected report to contain %q, got\n%s", expected, report.MarkdownContent)
+		}
+	}
+}
+
+func TestApplyFixesWithIds(t *testing.T) {
+	context, applied := runTestBuild(t, NewOptionsWithIds([]string{"run-as-root"}), "missing-labels", "run-as-root")
+
+	if strings.Join(applied, ",") != "run-as-root" {
+		t.Errorf("expected only the fix of run-as-root to be applied, got %v", applied)
+	}
+
+	if remaining := len(context.GetAllDiagnostics()); remaining != 2 {
+		t.Errorf("expected 2 diagnostics to remain, got %d", remaining)
+	}
+}
+
+func TestApplyFixesWithoutFixes(t *testing.T) {
+	context, _ := runTestBuild(t, nil)
+
+	if report := getReport(context, "fixes.md"); report != nil {
+		t.Errorf("expected no report when no fixes are applied, got\n%s", report.MarkdownContent)
+	}
+}
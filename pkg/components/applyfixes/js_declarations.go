@@ -0,0 +1,28 @@
+package applyfixes
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("applyFixes", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"applyFixes",
+	).Fields(
+		js.Field("Ids"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+		js.Constructor(reflect.ValueOf(NewOptionsWithIds)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("applyFixes"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("applyFixes"),
+	)
+}
@@ -0,0 +1,16 @@
+package applyfixes
+
+type Options struct {
+	// Only applies the fixes of the diagnostics with these ids. Fixes of all diagnostics are applied if empty.
+	Ids []string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func NewOptionsWithIds(ids []string) *Options {
+	return &Options{
+		Ids: ids,
+	}
+}
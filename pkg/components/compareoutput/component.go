@@ -0,0 +1,146 @@
+package compareoutput
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const componentType = "compare-output"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Previous output must be read before the output directory is deleted, report step runs before that.
+	component.AddAction(core.StepReport, component.report)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	options := component.options
+
+	if options == nil {
+		options = &Options{}
+		component.options = options
+	}
+
+	if options.PreviousManifestsPath == "" {
+		options.PreviousManifestsPath = filepath.Join(context.BuilderOptions.OutputConfiguration.OutputPath, core.DocumentsDir)
+	}
+}
+
+func (component *component) report(context *core.BuildContext) {
+	previousManifestsPath := component.options.PreviousManifestsPath
+
+	previous, err := core.ReadManifestsDirectory(previousManifestsPath)
+	if err != nil {
+		js.Throw(fmt.Errorf("failed to read previous manifests from %s: %w", previousManifestsPath, err))
+	}
+
+	current, err := core.DocumentsToManifests(context, context.GetAllDocumentsSorted())
+	if err != nil {
+		js.Throw(fmt.Errorf("failed to convert documents to manifests: %w", err))
+	}
+
+	changes := core.CompareManifests(previous, current)
+
+	printSummary(changes, previousManifestsPath)
+	context.AddReport(createReport(changes, previousManifestsPath))
+}
+
+func countChanges(changes []*core.ManifestChange) map[core.ManifestChangeType]int {
+	counts := map[core.ManifestChangeType]int{}
+	for _, change := range changes {
+		counts[change.Type]++
+	}
+
+	return counts
+}
+
+func printSummary(changes []*core.ManifestChange, previousManifestsPath string) {
+	counts := countChanges(changes)
+
+	slog.Info(
+		"Output changes compared to ${path}: ${added} added, ${removed} removed, ${modified} modified",
+		slog.String("path", previousManifestsPath),
+		slog.Int("added", counts[core.ManifestChangeTypeAdded]),
+		slog.Int("removed", counts[core.ManifestChangeTypeRemoved]),
+		slog.Int("modified", counts[core.ManifestChangeTypeModified]))
+
+	for _, change := range changes {
+		slog.Info(
+			"  ${type} ${identity}",
+			slog.String("type", strings.ToUpper(string(change.Type)[:1])),
+			slog.String("identity", change.Identity))
+	}
+}
+
+func createReport(changes []*core.ManifestChange, previousManifestsPath string) *core.Report {
+	counts := countChanges(changes)
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "# Output Changes\n\n")
+	fmt.Fprintf(
+		builder,
+		"Compared to `%s`: %d added, %d removed, %d modified.\n\n",
+		filepath.ToSlash(previousManifestsPath),
+		counts[core.ManifestChangeTypeAdded],
+		counts[core.ManifestChangeTypeRemoved],
+		counts[core.ManifestChangeTypeModified])
+
+	if len(changes) == 0 {
+		fmt.Fprintf(builder, "No changes.\n")
+		return core.NewReport(core.NewReportMetadata("output-changes.md"), builder.String())
+	}
+
+	fmt.Fprintf(builder, "| Change | Resource | File |\n")
+	fmt.Fprintf(builder, "| --- | --- | --- |\n")
+
+	for _, change := range changes {
+		path := change.Path
+		if path == "" {
+			path = change.PreviousPath
+		}
+
+		fmt.Fprintf(builder, "| %s | `%s` | `%s` |\n", change.Type, change.Identity, path)
+	}
+
+	fmt.Fprintf(builder, "\n")
+
+	for _, change := range changes {
+		if change.Type != core.ManifestChangeTypeModified {
+			continue
+		}
+
+		fmt.Fprintf(builder, "## `%s`\n", change.Identity)
+
+		if change.PreviousPath != change.Path {
+			fmt.Fprintf(builder, "Moved from `%s` to `%s`.\n\n", change.PreviousPath, change.Path)
+		}
+
+		for _, fieldChange := range change.FieldChanges {
+			fmt.Fprintf(builder, "- `%s`\n", fieldChange.String())
+		}
+
+		fmt.Fprintf(builder, "\n")
+	}
+
+	return core.NewReport(core.NewReportMetadata("output-changes.md"), builder.String())
+}
@@ -0,0 +1,27 @@
+package compareoutput
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("compareOutput", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"compareOutput",
+	).Fields(
+		js.Field("PreviousManifestsPath"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("compareOutput"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("compareOutput"),
+	)
+}
@@ -0,0 +1,11 @@
+package compareoutput
+
+type Options struct {
+	// Directory that contains the manifests of the previous build. Defaults to the manifests
+	// directory under the output path.
+	PreviousManifestsPath string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
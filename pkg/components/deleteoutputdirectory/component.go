@@ -23,8 +23,9 @@ func NewComponent(options *Options) *core.Component {
 	}
 
 	component.AddAction(core.StepSanitize, component.sanitizeOptions)
-	// This component should be run just before the output step otherwise all output files will be lost.
-	component.AddAction(core.NewStep("Delete outputs", append(core.StepOutput.Numbers, -1)...), component.output)
+	// This component should be run before the output files are written otherwise they will be lost. Diagnostics
+	// are written before they are checked, so the directory is deleted before both.
+	component.AddAction(core.NewStep("Delete outputs", append(core.StepOutput.Numbers, -5)...), component.output)
 
 	component.SetComponentType(componentType)
 	component.SetIdentifier(componentType)
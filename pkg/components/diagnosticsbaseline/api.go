@@ -0,0 +1,16 @@
+package diagnosticsbaseline
+
+import (
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func Add(builder *core.Builder, path string) *core.Component {
+	return AddWithOptions(builder, NewOptionsWithPath(path))
+}
+
+func AddWithOptions(builder *core.Builder, options *Options) *core.Component {
+	component := NewComponent(options)
+	builder.AddComponent(component)
+
+	return component
+}
@@ -0,0 +1,164 @@
+package diagnosticsbaseline
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"gopkg.in/yaml.v3"
+)
+
+const componentType = "diagnostics-baseline"
+
+// Baseline contains the diagnostics that are accepted, e.g. the existing diagnostics of legacy charts that
+// can't be fixed right away. Only the diagnostics that are not in the baseline are reported.
+type Baseline struct {
+	Diagnostics []*BaselineEntry `yaml:"diagnostics"`
+}
+
+// BaselineEntry identifies an accepted diagnostic. Entries don't contain the source locations since
+// they change whenever the scripts are edited.
+type BaselineEntry struct {
+	Id        string `yaml:"id"`
+	Document  string `yaml:"document,omitempty"`
+	FieldPath string `yaml:"fieldPath,omitempty"`
+	Message   string `yaml:"message,omitempty"`
+}
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Baseline is applied after all diagnostics are added and before they are reported.
+	component.AddAction(core.NewStep("Apply diagnostics baseline", append(core.StepReport.Numbers, -1)...), component.applyBaseline)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	if component.options == nil || component.options.Path == "" {
+		js.Throw(fmt.Errorf("path of the diagnostics baseline file must be specified"))
+	}
+}
+
+func (component *component) applyBaseline(context *core.BuildContext) {
+	options := component.options
+	diagnostics := context.GetAllDiagnostics()
+
+	if options.Update {
+		if err := writeBaseline(options.Path, diagnostics); err != nil {
+			js.Throw(err)
+		}
+
+		slog.Info("Wrote ${count} diagnostics to the baseline file ${path}", slog.Int("count", len(diagnostics)), slog.String("path", options.Path))
+	}
+
+	baseline, err := readBaseline(options.Path)
+	if err != nil {
+		js.Throw(err)
+	}
+
+	// Same diagnostic may be reported multiple times, e.g. for different containers with the same name
+	// in different documents. Count the entries so that each entry accepts a single diagnostic.
+	accepted := map[BaselineEntry]int{}
+	for _, entry := range baseline.Diagnostics {
+		accepted[*entry]++
+	}
+
+	suppression := &core.DiagnosticSuppression{
+		Reason: fmt.Sprintf("Accepted in the baseline file %s", options.Path),
+		Kind:   core.DiagnosticSuppressionKindBaseline,
+	}
+
+	for _, diagnostic := range diagnostics {
+		entry := newBaselineEntry(diagnostic)
+		if accepted[*entry] == 0 {
+			continue
+		}
+
+		accepted[*entry]--
+
+		if !slices.Contains(suppression.Ids, entry.Id) {
+			suppression.Ids = append(suppression.Ids, entry.Id)
+		}
+
+		context.SuppressDiagnostic(diagnostic, suppression)
+	}
+}
+
+func newBaselineEntry(diagnostic *core.Diagnostic) *BaselineEntry {
+	entry := &BaselineEntry{
+		Id:        diagnostic.Metadata.Id,
+		FieldPath: diagnostic.FieldPath,
+		Message:   diagnostic.Message,
+	}
+
+	if diagnostic.Document != nil {
+		entry.Document = diagnostic.Document.FullPath()
+	}
+
+	return entry
+}
+
+func readBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"can't read diagnostics baseline file %s, create it with the update option or --update-diagnostics-baseline flag: %w", path, err)
+	}
+
+	baseline := &Baseline{}
+	if err := yaml.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("can't parse diagnostics baseline file %s: %w", path, err)
+	}
+
+	return baseline, nil
+}
+
+func writeBaseline(path string, diagnostics []*core.Diagnostic) error {
+	baseline := &Baseline{
+		Diagnostics: []*BaselineEntry{},
+	}
+
+	for _, diagnostic := range diagnostics {
+		baseline.Diagnostics = append(baseline.Diagnostics, newBaselineEntry(diagnostic))
+	}
+
+	// Keep the file stable between the builds so that the changes are easy to review.
+	slices.SortStableFunc(baseline.Diagnostics, func(a, b *BaselineEntry) int {
+		return strings.Compare(
+			strings.Join([]string{a.Id, a.Document, a.FieldPath, a.Message}, "\x00"),
+			strings.Join([]string{b.Id, b.Document, b.FieldPath, b.Message}, "\x00"))
+	})
+
+	data, err := yaml.Marshal(baseline)
+	if err != nil {
+		return fmt.Errorf("can't serialize diagnostics baseline: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("can't create directory %s, %v", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("can't write diagnostics baseline file %s, %v", path, err)
+	}
+
+	return nil
+}
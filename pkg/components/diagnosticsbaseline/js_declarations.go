@@ -0,0 +1,29 @@
+package diagnosticsbaseline
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("diagnosticsBaseline", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"diagnosticsBaseline",
+	).Fields(
+		js.Field("Path"),
+		js.Field("Update"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+		js.Constructor(reflect.ValueOf(NewOptionsWithPath)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("useDiagnosticsBaseline"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("useDiagnosticsBaseline"),
+	)
+}
@@ -0,0 +1,18 @@
+package diagnosticsbaseline
+
+type Options struct {
+	// Path of the baseline file that contains the accepted diagnostics.
+	Path string
+	// Writes the current diagnostics into the baseline file instead of reading it.
+	Update bool
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func NewOptionsWithPath(path string) *Options {
+	return &Options{
+		Path: path,
+	}
+}
@@ -0,0 +1,12 @@
+package expectdiagnostic
+
+import (
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func Add(builder *core.Builder, options *Options) *core.Component {
+	component := NewComponent(options)
+	builder.AddComponent(component)
+
+	return component
+}
@@ -0,0 +1,109 @@
+package expectdiagnostic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const componentType = "expect-diagnostic"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Diagnostics are added during the diagnose step, so they are checked in the report step that follows it.
+	component.AddAction(core.StepReport, component.check)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	if component.options == nil {
+		component.options = &Options{}
+	}
+}
+
+func (component *component) check(context *core.BuildContext) {
+	options := component.options
+	matches := 0
+
+	for _, diagnostic := range context.GetAllDiagnostics() {
+		if component.matches(diagnostic) {
+			matches++
+		}
+	}
+
+	switch {
+	case options.Absent && matches > 0:
+		js.Throw(fmt.Errorf("expected no diagnostics matching %s, found %d", component.describe(), matches))
+	case options.Absent:
+		return
+	case options.Count > 0 && matches != options.Count:
+		js.Throw(fmt.Errorf("expected %d diagnostics matching %s, found %d", options.Count, component.describe(), matches))
+	case matches == 0:
+		js.Throw(fmt.Errorf("expected a diagnostic matching %s, found none", component.describe()))
+	}
+}
+
+func (component *component) matches(diagnostic *core.Diagnostic) bool {
+	options := component.options
+
+	if options.Id != "" && diagnostic.Metadata.Id != options.Id {
+		return false
+	}
+
+	if options.Severity != "" && diagnostic.Metadata.Severity != options.Severity {
+		return false
+	}
+
+	if options.DocumentPath != "" && (diagnostic.Document == nil || diagnostic.Document.FullPath() != options.DocumentPath) {
+		return false
+	}
+
+	if options.MessageContains != "" && !strings.Contains(diagnostic.Message, options.MessageContains) {
+		return false
+	}
+
+	return true
+}
+
+func (component *component) describe() string {
+	options := component.options
+	conditions := []string{}
+
+	if options.Id != "" {
+		conditions = append(conditions, fmt.Sprintf("id=%s", options.Id))
+	}
+
+	if options.Severity != "" {
+		conditions = append(conditions, fmt.Sprintf("severity=%s", options.Severity))
+	}
+
+	if options.DocumentPath != "" {
+		conditions = append(conditions, fmt.Sprintf("document=%s", options.DocumentPath))
+	}
+
+	if options.MessageContains != "" {
+		conditions = append(conditions, fmt.Sprintf("message contains %q", options.MessageContains))
+	}
+
+	if len(conditions) == 0 {
+		return "any"
+	}
+
+	return "{" + strings.Join(conditions, ", ") + "}"
+}
@@ -0,0 +1,31 @@
+package expectdiagnostic
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("expectDiagnostic", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"expectDiagnostic",
+	).Fields(
+		js.Field("Id"),
+		js.Field("Severity"),
+		js.Field("DocumentPath"),
+		js.Field("MessageContains"),
+		js.Field("Count"),
+		js.Field("Absent"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("expectDiagnostic"),
+	)
+}
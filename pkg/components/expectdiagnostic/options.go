@@ -0,0 +1,22 @@
+package expectdiagnostic
+
+import "github.com/ohayocorp/anemos/pkg/core"
+
+type Options struct {
+	// Only matches the diagnostics with this id if set.
+	Id string
+	// Only matches the diagnostics with this severity if set.
+	Severity core.DiagnosticSeverity
+	// Only matches the diagnostics of the document with this full path if set.
+	DocumentPath string
+	// Only matches the diagnostics whose messages contain this text if set.
+	MessageContains string
+	// Exact number of matching diagnostics that are expected. At least one matching diagnostic is expected if zero.
+	Count int
+	// Expects no matching diagnostics.
+	Absent bool
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
@@ -1,18 +1,36 @@
 package components
 
 import (
+	"github.com/ohayocorp/anemos/pkg/components/admissionpolicies"
 	"github.com/ohayocorp/anemos/pkg/components/apply"
+	"github.com/ohayocorp/anemos/pkg/components/applyfixes"
+	"github.com/ohayocorp/anemos/pkg/components/compareoutput"
 	"github.com/ohayocorp/anemos/pkg/components/deleteoutputdirectory"
+	"github.com/ohayocorp/anemos/pkg/components/diagnosticsbaseline"
+	"github.com/ohayocorp/anemos/pkg/components/expectdiagnostic"
+	"github.com/ohayocorp/anemos/pkg/components/mutationlog"
+	"github.com/ohayocorp/anemos/pkg/components/profile"
+	"github.com/ohayocorp/anemos/pkg/components/provenance"
 	"github.com/ohayocorp/anemos/pkg/components/reportdiagnostics"
+	"github.com/ohayocorp/anemos/pkg/components/writediagnostics"
 	"github.com/ohayocorp/anemos/pkg/components/writedocuments"
 	"github.com/ohayocorp/anemos/pkg/components/writereports"
 	"github.com/ohayocorp/anemos/pkg/js"
 )
 
 func RegisterComponents(jsRuntime *js.JsRuntime) {
+	admissionpolicies.RegisterJsDeclarations(jsRuntime)
 	apply.RegisterJsDeclarations(jsRuntime)
+	applyfixes.RegisterJsDeclarations(jsRuntime)
+	compareoutput.RegisterJsDeclarations(jsRuntime)
 	deleteoutputdirectory.RegisterJsDeclarations(jsRuntime)
+	diagnosticsbaseline.RegisterJsDeclarations(jsRuntime)
+	expectdiagnostic.RegisterJsDeclarations(jsRuntime)
+	mutationlog.RegisterJsDeclarations(jsRuntime)
+	profile.RegisterJsDeclarations(jsRuntime)
+	provenance.RegisterJsDeclarations(jsRuntime)
 	reportdiagnostics.RegisterJsDeclarations(jsRuntime)
+	writediagnostics.RegisterJsDeclarations(jsRuntime)
 	writedocuments.RegisterJsDeclarations(jsRuntime)
 	writereports.RegisterJsDeclarations(jsRuntime)
 }
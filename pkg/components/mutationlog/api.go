@@ -0,0 +1,19 @@
+package mutationlog
+
+import (
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func Add(builder *core.Builder) *core.Component {
+	return AddWithOptions(builder, nil)
+}
+
+func AddWithOptions(builder *core.Builder, options *Options) *core.Component {
+	// Mutation log must be enabled before the build starts so that all changes are recorded.
+	builder.EnableMutationLog()
+
+	component := NewComponent(options)
+	builder.AddComponent(component)
+
+	return component
+}
@@ -0,0 +1,108 @@
+package mutationlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const componentType = "mutation-log"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Reports are written on the output step, so the report has to be created right before it.
+	// Documents are not modified after the output step starts.
+	component.AddAction(core.NewStep("Mutation log report", append(core.StepOutput.Numbers, -2)...), component.report)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	if component.options == nil {
+		component.options = &Options{}
+	}
+}
+
+func (component *component) report(context *core.BuildContext) {
+	entries := []*core.MutationLogEntry{}
+
+	for _, entry := range context.GetMutationLog() {
+		componentTypes := component.options.ComponentTypes
+		if len(componentTypes) > 0 && !slices.Contains(componentTypes, entry.Component.ComponentType) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	report, err := createReport(entries)
+	if err != nil {
+		js.Throw(err)
+	}
+
+	context.AddReport(report)
+}
+
+func createReport(entries []*core.MutationLogEntry) (*core.Report, error) {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "# Mutation Log\n\n")
+
+	if len(entries) == 0 {
+		fmt.Fprintf(builder, "No component modified the documents.\n")
+		return core.NewReport(core.NewReportMetadata("mutation-log.md"), builder.String()), nil
+	}
+
+	fmt.Fprintf(builder, "| Component | Step | Document | Operations |\n")
+	fmt.Fprintf(builder, "| --- | --- | --- | ---: |\n")
+
+	for _, entry := range entries {
+		fmt.Fprintf(
+			builder,
+			"| %s | %s | %s | %d |\n",
+			entry.Component.ComponentName(),
+			entry.Component.Step,
+			entry.DocumentPath,
+			len(entry.Operations))
+	}
+
+	fmt.Fprintf(builder, "\n")
+
+	// Entries of the same action are consecutive since they are recorded right after the action runs.
+	var previous *core.ProvenanceEntry
+
+	for _, entry := range entries {
+		if entry.Component != previous {
+			fmt.Fprintf(builder, "## %s (step %s)\n\n", entry.Component.ComponentName(), entry.Component.Step)
+			previous = entry.Component
+		}
+
+		patch, err := json.MarshalIndent(entry.Operations, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize changes of %s made by %s: %w",
+				entry.DocumentPath, entry.Component.ComponentName(), err)
+		}
+
+		fmt.Fprintf(builder, "### %s\n\n", entry.DocumentPath)
+		fmt.Fprintf(builder, "```json\n%s\n```\n\n", string(patch))
+	}
+
+	return core.NewReport(core.NewReportMetadata("mutation-log.md"), builder.String()), nil
+}
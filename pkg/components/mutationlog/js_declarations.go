@@ -0,0 +1,28 @@
+package mutationlog
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("mutationLog", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"mutationLog",
+	).Fields(
+		js.Field("ComponentTypes"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+		js.Constructor(reflect.ValueOf(NewOptionsWithComponentTypes)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("logMutations"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("logMutations"),
+	)
+}
@@ -0,0 +1,17 @@
+package mutationlog
+
+type Options struct {
+	// Only includes the changes made by the components with these types in the report.
+	// Changes made by all components are included if empty.
+	ComponentTypes []string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func NewOptionsWithComponentTypes(componentTypes []string) *Options {
+	return &Options{
+		ComponentTypes: componentTypes,
+	}
+}
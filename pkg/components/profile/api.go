@@ -0,0 +1,19 @@
+package profile
+
+import (
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func Add(builder *core.Builder) *core.Component {
+	return AddWithOptions(builder, nil)
+}
+
+func AddWithOptions(builder *core.Builder, options *Options) *core.Component {
+	// Profiling must be enabled before the build starts so that the first steps are measured too.
+	builder.EnableProfiling()
+
+	component := NewComponent(options)
+	builder.AddComponent(component)
+
+	return component
+}
@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"path/filepath"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+const componentType = "profile"
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Reports are written on the output step, so the report has to be created right before it.
+	// Output and apply steps are only included in the trace file.
+	component.AddAction(core.NewStep("Profile report", append(core.StepOutput.Numbers, -2)...), component.report)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	options := component.options
+
+	if options == nil {
+		options = &Options{}
+		component.options = options
+	}
+
+	if options.TraceFilePath == "" {
+		options.TraceFilePath = filepath.Join(context.BuilderOptions.OutputConfiguration.OutputPath, "profile", "trace.json")
+	}
+
+	if context.Profiler != nil {
+		context.Profiler.TraceFilePath = options.TraceFilePath
+	}
+}
+
+func (component *component) report(context *core.BuildContext) {
+	if context.Profiler == nil {
+		return
+	}
+
+	context.AddReport(core.NewReport(core.NewReportMetadata("profile.md"), context.Profiler.MarkdownSummary()))
+}
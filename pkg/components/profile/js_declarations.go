@@ -0,0 +1,27 @@
+package profile
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("profile", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"profile",
+	).Fields(
+		js.Field("TraceFilePath"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("profile"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("profile"),
+	)
+}
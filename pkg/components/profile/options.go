@@ -0,0 +1,11 @@
+package profile
+
+type Options struct {
+	// Path of the Chrome trace event file that is written after the build. Defaults to
+	// profile/trace.json under the output path.
+	TraceFilePath string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
@@ -0,0 +1,19 @@
+package provenance
+
+import (
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+func Add(builder *core.Builder) *core.Component {
+	return AddWithOptions(builder, nil)
+}
+
+func AddWithOptions(builder *core.Builder, options *Options) *core.Component {
+	// Tracking must be enabled before the build starts so that all modifications are detected.
+	builder.EnableProvenanceTracking()
+
+	component := NewComponent(options)
+	builder.AddComponent(component)
+
+	return component
+}
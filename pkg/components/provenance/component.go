@@ -0,0 +1,148 @@
+package provenance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/ohayocorp/anemos/pkg/core"
+)
+
+const (
+	componentType = "provenance"
+
+	AnnotationCreatedBy  = core.ProvenanceAnnotationPrefix + "created-by"
+	AnnotationModifiedBy = core.ProvenanceAnnotationPrefix + "modified-by"
+)
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	component.AddAction(core.StepReport, component.report)
+	// Annotations are written right before the output step so that all modifications are included.
+	component.AddAction(core.NewStep("Write provenance annotations", append(core.StepOutput.Numbers, -2)...), component.annotate)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	if component.options == nil {
+		component.options = &Options{}
+	}
+}
+
+func (component *component) report(context *core.BuildContext) {
+	if component.options.DisableReport {
+		return
+	}
+
+	context.AddReport(createReport(context.GetAllDocumentsSorted()))
+}
+
+func (component *component) annotate(context *core.BuildContext) {
+	if !component.options.WriteAnnotations {
+		return
+	}
+
+	for _, document := range context.GetAllDocuments() {
+		provenance := document.GetProvenance()
+		if provenance == nil {
+			continue
+		}
+
+		annotations := getAnnotations(context, document)
+		annotations.Set(AnnotationCreatedBy, provenance.CreatedBy.ComponentName())
+
+		if modifiedBy := provenance.ModifiedByComponentNames(); len(modifiedBy) > 0 {
+			annotations.Set(AnnotationModifiedBy, strings.Join(modifiedBy, ", "))
+		}
+	}
+}
+
+// Returns the annotations object of the document, creates the metadata and annotations objects if necessary.
+func getAnnotations(context *core.BuildContext, document *core.Document) *sobek.Object {
+	runtime := context.JsRuntime.Runtime
+
+	getOrCreate := func(object *sobek.Object, key string) *sobek.Object {
+		value, ok := object.Get(key).(*sobek.Object)
+		if !ok || value == nil {
+			value = runtime.NewObject()
+			object.Set(key, value)
+		}
+
+		return value
+	}
+
+	return getOrCreate(getOrCreate(document.Object, "metadata"), "annotations")
+}
+
+func createReport(documents []*core.Document) *core.Report {
+	builder := &strings.Builder{}
+	creators := []*core.ProvenanceEntry{}
+
+	fmt.Fprintf(builder, "# Document Provenance\n\n")
+	fmt.Fprintf(builder, "| Document | Created By | Modified By |\n")
+	fmt.Fprintf(builder, "| --- | --- | --- |\n")
+
+	for _, document := range documents {
+		provenance := document.GetProvenance()
+		if provenance == nil {
+			continue
+		}
+
+		createdBy := provenance.CreatedBy
+		if !containsComponent(creators, createdBy) {
+			creators = append(creators, createdBy)
+		}
+
+		modifiedBy := strings.Join(provenance.ModifiedByComponentNames(), ", ")
+		if modifiedBy == "" {
+			modifiedBy = "-"
+		}
+
+		fmt.Fprintf(
+			builder,
+			"| %s | %s (step %s) | %s |\n",
+			document.FullPath(),
+			createdBy.ComponentName(),
+			createdBy.Step,
+			modifiedBy)
+	}
+
+	fmt.Fprintf(builder, "\n## Component Registrations\n\n")
+
+	for _, creator := range creators {
+		fmt.Fprintf(builder, "### %s\n\n", creator.ComponentName())
+
+		if creator.StackTrace == "" {
+			fmt.Fprintf(builder, "Stack trace is not available.\n\n")
+			continue
+		}
+
+		fmt.Fprintf(builder, "```\n%s\n```\n\n", creator.StackTrace)
+	}
+
+	return core.NewReport(core.NewReportMetadata("provenance.md"), builder.String())
+}
+
+func containsComponent(entries []*core.ProvenanceEntry, entry *core.ProvenanceEntry) bool {
+	for _, e := range entries {
+		if e.ComponentName() == entry.ComponentName() && e.StackTrace == entry.StackTrace {
+			return true
+		}
+	}
+
+	return false
+}
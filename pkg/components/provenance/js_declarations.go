@@ -0,0 +1,28 @@
+package provenance
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("provenance", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"provenance",
+	).Fields(
+		js.Field("WriteAnnotations"),
+		js.Field("DisableReport"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("trackProvenance"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("trackProvenance"),
+	)
+}
@@ -0,0 +1,13 @@
+package provenance
+
+type Options struct {
+	// Writes the components that created and modified each document as annotations
+	// with the "provenance.anemos.sh/" prefix.
+	WriteAnnotations bool
+	// Skips writing the provenance report.
+	DisableReport bool
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
@@ -2,10 +2,13 @@ package reportdiagnostics
 
 import (
 	"fmt"
+	"log/slog"
+	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
 )
 
 const componentType = "report-diagnostics"
@@ -23,6 +26,8 @@ func NewComponent(options *Options) *core.Component {
 
 	component.AddAction(core.StepSanitize, component.sanitizeOptions)
 	component.AddAction(core.StepReport, component.report)
+	// Documents are neither written nor applied if the build fails before the output step.
+	component.AddAction(core.NewStep("Check diagnostics", append(core.StepOutput.Numbers, -3)...), component.check)
 
 	component.SetComponentType(componentType)
 	component.SetIdentifier(componentType)
@@ -37,6 +42,10 @@ func (component *component) sanitizeOptions(context *core.BuildContext) {
 		options = &Options{}
 		component.options = options
 	}
+
+	if options.FailOn != "" && options.FailOn.Level() < 0 {
+		js.Throw(fmt.Errorf("invalid diagnostic severity to fail on: %s, must be one of info, warning or error", options.FailOn))
+	}
 }
 
 func (component *component) report(context *core.BuildContext) {
@@ -101,9 +110,17 @@ func (component *component) report(context *core.BuildContext) {
 				report.MarkdownContent += fmt.Sprintf("#### `%s`\n", key)
 
 				for _, diagnostic := range diagnosticsByDocument[key] {
-					if diagnostic.Message != "" {
-						report.MarkdownContent += fmt.Sprintf("- %s\n", diagnostic.Message)
+					if diagnostic.Message == "" {
+						continue
+					}
+
+					report.MarkdownContent += fmt.Sprintf("- %s", diagnostic.Message)
+
+					if source := formatSource(context, diagnostic); source != "" {
+						report.MarkdownContent += fmt.Sprintf(" (%s)", source)
 					}
+
+					report.MarkdownContent += "\n"
 				}
 
 				report.MarkdownContent += "\n"
@@ -111,7 +128,130 @@ func (component *component) report(context *core.BuildContext) {
 		}
 	}
 
-	if len(severities) > 0 {
+	suppressed := context.GetSuppressedDiagnostics()
+	if len(suppressed) > 0 {
+		report.MarkdownContent += formatSuppressedDiagnostics(suppressed)
+	}
+
+	if len(severities) > 0 || len(suppressed) > 0 {
 		context.AddReport(report)
 	}
 }
+
+// Returns the number of suppressed diagnostics for each diagnostic and suppression kind as a markdown table.
+func formatSuppressedDiagnostics(diagnostics []*core.Diagnostic) string {
+	type key struct {
+		name string
+		kind core.DiagnosticSuppressionKind
+	}
+
+	counts := map[key]int{}
+	for _, diagnostic := range diagnostics {
+		counts[key{name: diagnostic.Metadata.Name, kind: diagnostic.SuppressedBy.Kind}]++
+	}
+
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	slices.SortFunc(keys, func(a, b key) int {
+		if result := strings.Compare(a.name, b.name); result != 0 {
+			return result
+		}
+
+		return strings.Compare(string(a.kind), string(b.kind))
+	})
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "## Suppressed Diagnostics\n")
+	fmt.Fprintf(builder, "%d diagnostics are suppressed and not reported.\n\n", len(diagnostics))
+	fmt.Fprintf(builder, "| Diagnostic | Suppressed By | Count |\n")
+	fmt.Fprintf(builder, "|---|---|---|\n")
+
+	for _, k := range keys {
+		fmt.Fprintf(builder, "| %s | %s | %d |\n", k.name, k.kind, counts[k])
+	}
+
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+func (component *component) check(context *core.BuildContext) {
+	options := component.options
+	failed := []*core.Diagnostic{}
+
+	for _, diagnostic := range context.GetAllDiagnostics() {
+		if diagnostic.Metadata.FailsBuild {
+			failed = append(failed, diagnostic)
+			continue
+		}
+
+		if options.FailOn == "" || !diagnostic.Metadata.Severity.IsAtLeast(options.FailOn) {
+			continue
+		}
+
+		if len(options.FailOnCategories) > 0 && !slices.ContainsFunc(diagnostic.Metadata.Categories, func(category core.DiagnosticCategory) bool {
+			return slices.Contains(options.FailOnCategories, category)
+		}) {
+			continue
+		}
+
+		failed = append(failed, diagnostic)
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	for _, diagnostic := range failed {
+		document := ""
+		if diagnostic.Document != nil {
+			document = diagnostic.Document.FullPath()
+		}
+
+		location := ""
+		if sourceLocation := diagnostic.GetSourceLocation(); sourceLocation != nil {
+			location = sourceLocation.RelativeTo(mainScriptDirectory(context)).String()
+		}
+
+		slog.Error(
+			"[${severity}] ${name} ${document} ${location}: ${message}",
+			slog.String("severity", string(diagnostic.Metadata.Severity)),
+			slog.String("name", diagnostic.Metadata.Name),
+			slog.String("document", document),
+			slog.String("location", location),
+			slog.String("message", diagnostic.Message))
+	}
+
+	if options.FailOn == "" {
+		js.Throw(fmt.Errorf("build failed with %d diagnostics that fail the build", len(failed)))
+	}
+
+	js.Throw(fmt.Errorf("build failed with %d diagnostics with severity %s or above", len(failed), options.FailOn))
+}
+
+// Returns the field path and the source location of the diagnostic formatted as markdown,
+// e.g. "`spec.replicas` at `index.js:12:5`". Returns an empty string if both are unknown.
+func formatSource(context *core.BuildContext, diagnostic *core.Diagnostic) string {
+	parts := []string{}
+
+	if diagnostic.FieldPath != "" {
+		parts = append(parts, fmt.Sprintf("`%s`", diagnostic.FieldPath))
+	}
+
+	if location := diagnostic.GetSourceLocation(); location != nil {
+		parts = append(parts, fmt.Sprintf("at `%s`", location.RelativeTo(mainScriptDirectory(context))))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func mainScriptDirectory(context *core.BuildContext) string {
+	if context.JsRuntime == nil || context.JsRuntime.MainScriptPath == "" {
+		return ""
+	}
+
+	return filepath.Dir(context.JsRuntime.MainScriptPath)
+}
@@ -0,0 +1,61 @@
+package reportdiagnostics
+
+import (
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// Runs a build that adds a diagnostic with the given metadata and returns true if the build fails.
+func buildFails(t *testing.T, options *Options, metadata *core.DiagnosticMetadata) (failed bool) {
+	t.Helper()
+
+	builder := core.NewEmptyBuilder(&core.BuilderOptions{
+		OutputConfiguration: &core.OutputConfiguration{OutputPath: t.TempDir()},
+	}, js.NewJsRuntime())
+
+	builder.AddComponent(NewComponent(options))
+	builder.OnModify(func(context *core.BuildContext) {
+		context.AddDiagnostic(core.NewDiagnostic(metadata, "message"))
+	})
+
+	defer func() {
+		failed = recover() != nil
+	}()
+
+	builder.Build()
+
+	return false
+}
+
+func TestCheck(t *testing.T) {
+	warning := core.NewDiagnosticMetadata("warning", "Warning", "", core.DiagnosticSeverityWarning, nil)
+	failsBuild := &core.DiagnosticMetadata{Id: "invalid-input", Name: "Invalid Input", Severity: core.DiagnosticSeverityError, FailsBuild: true}
+
+	tests := []struct {
+		name     string
+		options  *Options
+		metadata *core.DiagnosticMetadata
+		expected bool
+	}{
+		{name: "without fail on", options: nil, metadata: warning, expected: false},
+		{name: "below fail on", options: &Options{FailOn: core.DiagnosticSeverityError}, metadata: warning, expected: false},
+		{name: "at fail on", options: &Options{FailOn: core.DiagnosticSeverityWarning}, metadata: warning, expected: true},
+		{name: "fails build without fail on", options: nil, metadata: failsBuild, expected: true},
+		{
+			name:     "fails build outside of the categories",
+			options:  &Options{FailOn: core.DiagnosticSeverityError, FailOnCategories: []core.DiagnosticCategory{core.DiagnosticCategorySecurity}},
+			metadata: failsBuild,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if failed := buildFails(t, test.options, test.metadata); failed != test.expected {
+				t.Errorf("expected the build to fail: %v, got %v", test.expected, failed)
+			}
+		})
+	}
+}
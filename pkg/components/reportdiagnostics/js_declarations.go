@@ -12,6 +12,9 @@ func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
 
 	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
 		"reportDiagnostics",
+	).Fields(
+		js.Field("FailOn"),
+		js.Field("FailOnCategories"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewOptions)),
 	)
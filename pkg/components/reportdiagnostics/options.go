@@ -1,6 +1,15 @@
 package reportdiagnostics
 
-type Options struct{}
+import "github.com/ohayocorp/anemos/pkg/core"
+
+type Options struct {
+	// Fails the build before the output step if there is a diagnostic with this severity or a more severe one.
+	// Diagnostics don't fail the build if empty.
+	FailOn core.DiagnosticSeverity
+	// Only the diagnostics that have at least one of these categories fail the build. All diagnostics are
+	// considered if empty.
+	FailOnCategories []core.DiagnosticCategory
+}
 
 func NewOptions() *Options {
 	return &Options{}
@@ -0,0 +1,149 @@
+package writediagnostics
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const (
+	componentType  = "write-diagnostics"
+	diagnosticsDir = "diagnostics"
+)
+
+type component struct {
+	*core.Component
+	options *Options
+}
+
+func NewComponent(options *Options) *core.Component {
+	component := &component{
+		Component: core.NewComponent(),
+		options:   options,
+	}
+
+	component.AddAction(core.StepSanitize, component.sanitizeOptions)
+	// Diagnostics are written after the output directory is deleted and before the diagnostics are checked, so
+	// that the files exist when the build fails.
+	component.AddAction(core.NewStep("Write diagnostics", append(core.StepOutput.Numbers, -4)...), component.output)
+
+	component.SetComponentType(componentType)
+	component.SetIdentifier(componentType)
+
+	return component.Component
+}
+
+func (component *component) sanitizeOptions(context *core.BuildContext) {
+	options := component.options
+
+	if options == nil {
+		options = NewOptions()
+		component.options = options
+	}
+
+	if len(options.OutputTypes) == 0 {
+		options.OutputTypes = []DiagnosticsOutputType{
+			DiagnosticsOutputTypeSarif,
+			DiagnosticsOutputTypeJUnit,
+		}
+	}
+
+	for _, outputType := range options.OutputTypes {
+		if outputType != DiagnosticsOutputTypeSarif && outputType != DiagnosticsOutputTypeJUnit {
+			js.Throw(fmt.Errorf("invalid diagnostics output type: %s, must be one of sarif or junit", outputType))
+		}
+	}
+}
+
+func (component *component) output(context *core.BuildContext) {
+	outputDirectory := filepath.Join(context.BuilderOptions.OutputConfiguration.OutputPath, diagnosticsDir)
+
+	slog.Info("Writing diagnostics to ${directory}", slog.String("directory", outputDirectory))
+
+	// Suppressed diagnostics are also written so that the tools can show them as suppressed.
+	diagnostics := append(context.GetAllDiagnostics(), context.GetSuppressedDiagnostics()...)
+	slices.SortStableFunc(diagnostics, func(a, b *core.Diagnostic) int {
+		if result := strings.Compare(a.Metadata.Id, b.Metadata.Id); result != 0 {
+			return result
+		}
+
+		return strings.Compare(getDocumentPath(context, a), getDocumentPath(context, b))
+	})
+
+	for _, outputType := range component.options.OutputTypes {
+		var err error
+
+		switch outputType {
+		case DiagnosticsOutputTypeSarif:
+			err = writeSarif(context, filepath.Join(outputDirectory, "diagnostics.sarif"), diagnostics)
+		case DiagnosticsOutputTypeJUnit:
+			err = writeJUnit(context, filepath.Join(outputDirectory, "diagnostics.junit.xml"), diagnostics)
+		}
+
+		if err != nil {
+			js.Throw(err)
+		}
+	}
+}
+
+// Returns the path of the file the document of the diagnostic is written to, relative to the main script directory
+// so that the results link back to the output manifests, e.g. "output/manifests/app/deployment.yaml".
+// Returns an empty string if the diagnostic doesn't belong to a document.
+func getDocumentPath(context *core.BuildContext, diagnostic *core.Diagnostic) string {
+	if diagnostic.Document == nil {
+		return ""
+	}
+
+	path := filepath.Join(context.BuilderOptions.OutputConfiguration.OutputPath, core.DocumentsDir, diagnostic.Document.FullPath())
+
+	if mainScriptPath := context.JsRuntime.MainScriptPath; mainScriptPath != "" {
+		if relativePath, err := filepath.Rel(filepath.Dir(mainScriptPath), path); err == nil {
+			path = relativePath
+		}
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// Returns the source location of the diagnostic with a path relative to the main script directory.
+// Returns nil if the location is unknown.
+func getSourceLocation(context *core.BuildContext, diagnostic *core.Diagnostic) *core.SourceLocation {
+	location := diagnostic.GetSourceLocation()
+	if location == nil || location.File == "" {
+		return nil
+	}
+
+	if mainScriptPath := context.JsRuntime.MainScriptPath; mainScriptPath != "" {
+		location = location.RelativeTo(filepath.Dir(mainScriptPath))
+	}
+
+	return location
+}
+
+// Returns the reason of the suppression of the diagnostic, falls back to the kind of the suppression.
+func getSuppressionReason(diagnostic *core.Diagnostic) string {
+	suppression := diagnostic.SuppressedBy
+	if suppression.Reason != "" {
+		return suppression.Reason
+	}
+
+	return fmt.Sprintf("Suppressed by %s", suppression.Kind)
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("can't create directory %s, %v", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("can't write diagnostics file %s, %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package writediagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/components/deleteoutputdirectory"
+	"github.com/ohayocorp/anemos/pkg/components/reportdiagnostics"
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func TestDiagnosticsWrittenWhenBuildFails(t *testing.T) {
+	directory := t.TempDir()
+	outputPath := filepath.Join(directory, "output")
+
+	// Files of the previous build are deleted before the diagnostics are written.
+	stalePath := filepath.Join(outputPath, "stale.yaml")
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(stalePath, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jsRuntime := js.NewJsRuntime()
+	jsRuntime.MainScriptPath = filepath.Join(directory, "index.js")
+
+	builder := core.NewEmptyBuilder(&core.BuilderOptions{
+		OutputConfiguration: &core.OutputConfiguration{OutputPath: outputPath},
+	}, jsRuntime)
+
+	builder.AddComponent(deleteoutputdirectory.NewComponent(nil))
+	builder.AddComponent(reportdiagnostics.NewComponent(&reportdiagnostics.Options{FailOn: core.DiagnosticSeverityError}))
+	builder.AddComponent(NewComponent(nil))
+
+	metadata := core.NewDiagnosticMetadata("broken", "Broken", "Always fails.", core.DiagnosticSeverityError, nil)
+	builder.OnModify(func(context *core.BuildContext) {
+		context.AddDiagnostic(core.NewDiagnostic(metadata, "broken"))
+	})
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected the build to fail because of the error diagnostic")
+			}
+		}()
+
+		builder.Build()
+	}()
+
+	if _, err := os.Stat(stalePath); !os.IsNotExist(err) {
+		t.Errorf("expected the output directory to be deleted before the diagnostics are written, %v", err)
+	}
+
+	for _, name := range []string{"diagnostics.sarif", "diagnostics.junit.xml"} {
+		if _, err := os.Stat(filepath.Join(outputPath, diagnosticsDir, name)); err != nil {
+			t.Errorf("expected %s to be written before the build failed, %v", name, err)
+		}
+	}
+}
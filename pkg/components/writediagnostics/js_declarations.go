@@ -0,0 +1,31 @@
+package writediagnostics
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func RegisterJsDeclarations(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("writeDiagnostics", "componentType", reflect.ValueOf(componentType))
+
+	jsRuntime.Variable("writeDiagnostics", "Sarif", reflect.ValueOf(DiagnosticsOutputTypeSarif))
+	jsRuntime.Variable("writeDiagnostics", "Junit", reflect.ValueOf(DiagnosticsOutputTypeJUnit))
+
+	jsRuntime.Type(reflect.TypeFor[Options]()).JsModule(
+		"writeDiagnostics",
+	).Fields(
+		js.Field("OutputTypes"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewOptions)),
+		js.Constructor(reflect.ValueOf(NewOptionsWithOutputTypes)),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[core.Builder]()).JsModule(
+		"builder",
+	).ExtensionMethods(
+		js.ExtensionMethod(reflect.ValueOf(Add)).JsName("writeDiagnostics"),
+		js.ExtensionMethod(reflect.ValueOf(AddWithOptions)).JsName("writeDiagnostics"),
+	)
+}
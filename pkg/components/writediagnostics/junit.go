@@ -0,0 +1,78 @@
+package writediagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/util"
+)
+
+// Writes each diagnostic as a test case. Errors and warnings are written as failures, info diagnostics pass.
+// Suppressed diagnostics are written as skipped test cases.
+func writeJUnit(context *core.BuildContext, path string, diagnostics []*core.Diagnostic) error {
+	suite := &util.JUnitTestSuite{
+		Name: "anemos diagnostics",
+	}
+
+	for _, diagnostic := range diagnostics {
+		metadata := diagnostic.Metadata
+		documentPath := getDocumentPath(context, diagnostic)
+
+		name := metadata.Name
+		if documentPath != "" {
+			name = fmt.Sprintf("%s: %s", metadata.Name, documentPath)
+		}
+
+		categories := []string{}
+		for _, category := range metadata.Categories {
+			categories = append(categories, string(category))
+		}
+
+		details := &strings.Builder{}
+		fmt.Fprintf(details, "Id: %s\n", metadata.Id)
+		fmt.Fprintf(details, "Name: %s\n", metadata.Name)
+		fmt.Fprintf(details, "Description: %s\n", metadata.Description)
+		fmt.Fprintf(details, "Severity: %s\n", metadata.Severity)
+		fmt.Fprintf(details, "Categories: %s\n", strings.Join(categories, ", "))
+
+		if documentPath != "" {
+			fmt.Fprintf(details, "File: %s\n", documentPath)
+		}
+
+		if diagnostic.FieldPath != "" {
+			fmt.Fprintf(details, "Field: %s\n", diagnostic.FieldPath)
+		}
+
+		if location := getSourceLocation(context, diagnostic); location != nil {
+			fmt.Fprintf(details, "Source: %s\n", location)
+		}
+
+		fmt.Fprintf(details, "Message: %s\n", diagnostic.Message)
+
+		testCase := &util.JUnitTestCase{
+			Name:      name,
+			ClassName: metadata.Id,
+			Time:      util.FormatJUnitDuration(0),
+		}
+
+		if diagnostic.SuppressedBy != nil {
+			fmt.Fprintf(details, "Suppressed: %s\n", diagnostic.SuppressedBy.Kind)
+
+			testCase.Skipped = &util.JUnitSkipped{Message: getSuppressionReason(diagnostic)}
+			testCase.SystemOut = details.String()
+		} else if metadata.Severity.IsAtLeast(core.DiagnosticSeverityWarning) {
+			testCase.Failure = &util.JUnitFailure{
+				Message: diagnostic.Message,
+				Type:    string(metadata.Severity),
+				Content: details.String(),
+			}
+		} else {
+			testCase.SystemOut = details.String()
+		}
+
+		suite.AddTestCase(testCase)
+	}
+
+	return util.WriteJUnitReport(path, suite)
+}
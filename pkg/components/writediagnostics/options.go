@@ -0,0 +1,23 @@
+package writediagnostics
+
+const (
+	DiagnosticsOutputTypeSarif DiagnosticsOutputType = "sarif"
+	DiagnosticsOutputTypeJUnit DiagnosticsOutputType = "junit"
+)
+
+type DiagnosticsOutputType string
+
+type Options struct {
+	// OutputTypes determines the file formats the diagnostics are written in. Defaults to both SARIF and JUnit.
+	OutputTypes []DiagnosticsOutputType
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func NewOptionsWithOutputTypes(outputTypes []DiagnosticsOutputType) *Options {
+	return &Options{
+		OutputTypes: outputTypes,
+	}
+}
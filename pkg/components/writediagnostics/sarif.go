@@ -0,0 +1,232 @@
+package writediagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/util"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version"`
+	InformationUri string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id                   string                  `json:"id"`
+	Name                 string                  `json:"name"`
+	ShortDescription     *sarifMessage           `json:"shortDescription"`
+	FullDescription      *sarifMessage           `json:"fullDescription,omitempty"`
+	DefaultConfiguration *sarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           *sarifProperties        `json:"properties,omitempty"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Tags []core.DiagnosticCategory `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId           string                 `json:"ruleId"`
+	RuleIndex        int                    `json:"ruleIndex"`
+	Level            string                 `json:"level"`
+	Message          *sarifMessage          `json:"message"`
+	Locations        []*sarifLocation       `json:"locations,omitempty"`
+	RelatedLocations []*sarifLocation       `json:"relatedLocations,omitempty"`
+	Suppressions     []*sarifSuppression    `json:"suppressions,omitempty"`
+	Properties       *sarifResultProperties `json:"properties"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifResultProperties struct {
+	Severity   core.DiagnosticSeverity   `json:"severity"`
+	Categories []core.DiagnosticCategory `json:"categories"`
+	FieldPath  string                    `json:"fieldPath,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+// Returns the SARIF level that corresponds to the given severity.
+func getSarifLevel(severity core.DiagnosticSeverity) string {
+	switch severity {
+	case core.DiagnosticSeverityError:
+		return "error"
+	case core.DiagnosticSeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func writeSarif(context *core.BuildContext, path string, diagnostics []*core.Diagnostic) error {
+	driver := &sarifDriver{
+		Name:           "anemos",
+		Version:        util.AppVersion,
+		InformationUri: "https://anemos.sh",
+		Rules:          []*sarifRule{},
+	}
+
+	run := &sarifRun{
+		Tool:    &sarifTool{Driver: driver},
+		Results: []*sarifResult{},
+	}
+
+	ruleIndices := map[string]int{}
+
+	for _, diagnostic := range diagnostics {
+		metadata := diagnostic.Metadata
+
+		ruleIndex, ok := ruleIndices[metadata.Id]
+		if !ok {
+			rule := &sarifRule{
+				Id:               metadata.Id,
+				Name:             metadata.Name,
+				ShortDescription: &sarifMessage{Text: metadata.Name},
+				DefaultConfiguration: &sarifRuleConfiguration{
+					Level: getSarifLevel(metadata.Severity),
+				},
+			}
+
+			if metadata.Description != "" {
+				rule.FullDescription = &sarifMessage{Text: metadata.Description}
+			}
+
+			if len(metadata.Categories) > 0 {
+				rule.Properties = &sarifProperties{Tags: metadata.Categories}
+			}
+
+			ruleIndex = len(driver.Rules)
+			ruleIndices[metadata.Id] = ruleIndex
+			driver.Rules = append(driver.Rules, rule)
+		}
+
+		// Message is required by SARIF, fall back to the name of the diagnostic.
+		message := diagnostic.Message
+		if message == "" {
+			message = metadata.Name
+		}
+
+		result := &sarifResult{
+			RuleId:    metadata.Id,
+			RuleIndex: ruleIndex,
+			Level:     getSarifLevel(metadata.Severity),
+			Message:   &sarifMessage{Text: message},
+			Properties: &sarifResultProperties{
+				Severity:   metadata.Severity,
+				Categories: append([]core.DiagnosticCategory{}, metadata.Categories...),
+				FieldPath:  diagnostic.FieldPath,
+			},
+		}
+
+		if documentPath := getDocumentPath(context, diagnostic); documentPath != "" {
+			result.Locations = []*sarifLocation{
+				{
+					PhysicalLocation: &sarifPhysicalLocation{
+						ArtifactLocation: &sarifArtifactLocation{Uri: documentPath},
+					},
+				},
+			}
+		}
+
+		if location := getSourceLocation(context, diagnostic); location != nil {
+			relatedLocation := &sarifLocation{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: &sarifArtifactLocation{Uri: location.File},
+				},
+				Message: &sarifMessage{Text: "Source of the document"},
+			}
+
+			if location.Line > 0 {
+				relatedLocation.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   location.Line,
+					StartColumn: location.Column,
+				}
+			}
+
+			result.RelatedLocations = []*sarifLocation{relatedLocation}
+		}
+
+		if suppression := diagnostic.SuppressedBy; suppression != nil {
+			// Annotations are in the manifests, other suppressions are kept outside of them.
+			kind := "external"
+			if suppression.Kind == core.DiagnosticSuppressionKindAnnotation {
+				kind = "inSource"
+			}
+
+			result.Suppressions = []*sarifSuppression{
+				{
+					Kind:          kind,
+					Status:        "accepted",
+					Justification: getSuppressionReason(diagnostic),
+				},
+			}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	log := &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize SARIF log: %w", err)
+	}
+
+	return writeFile(path, data)
+}
@@ -93,9 +93,12 @@ func (component *component) output(context *core.BuildContext) {
 		js.Throw(fmt.Errorf("duplicate document paths found:\n%s", message))
 	}
 
+	documentPaths := []string{}
+
 	for _, documentGroup := range context.GetDocumentGroups() {
 		for _, document := range documentGroup.Documents {
 			component.writeDocument(context, document, outputDirectory)
+			documentPaths = append(documentPaths, document.FullPath())
 		}
 
 		for _, additionalFile := range documentGroup.AdditionalFiles {
@@ -118,6 +121,11 @@ func (component *component) output(context *core.BuildContext) {
 			}
 		}
 	}
+
+	// Index is used to compare the documents with the previous output without the additional files.
+	if err := core.WriteDocumentsIndex(outputDirectory, documentPaths); err != nil {
+		js.Throw(err)
+	}
 }
 
 func (component *component) writeDocument(context *core.BuildContext, document *core.Document, outputDirectory string) {
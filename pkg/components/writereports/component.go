@@ -86,7 +86,7 @@ func (component *component) output(context *core.BuildContext) {
 				htmlFile := component.createFile(changeFileExtension(report.Metadata.FilePath, ".html"), reportsDirectory)
 				defer htmlFile.Close()
 
-				htmlText := component.renderMarkdown(report.MarkdownContent, "")
+				htmlText := RenderMarkdown(report.MarkdownContent, "")
 				htmlFile.WriteString(htmlText)
 			}
 		}
@@ -115,7 +115,8 @@ func (component *component) createFile(fileName, directory string) *os.File {
 	return file
 }
 
-func (component *component) renderMarkdown(mdText, title string) string {
+// Renders the given markdown text as a complete HTML page that uses the GitHub markdown style.
+func RenderMarkdown(mdText, title string) string {
 	head := util.ParseTemplate(`
 		<meta name="viewport" content="width=device-width, initial-scale=1, minimal-ui">
 		<style>
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// ApiDeprecation describes an API version of a kind that is deprecated or removed in the target cluster.
+type ApiDeprecation struct {
+	ApiVersion string
+	Kind       string
+	// Kubernetes version in which the API version is deprecated, e.g. "1.21".
+	DeprecatedIn string
+	// Kubernetes version in which the API version is removed, e.g. "1.25". Empty if the removal is not scheduled.
+	RemovedIn string
+	// API version that replaces the deprecated one, e.g. "policy/v1". Empty if there is no replacement.
+	Replacement string
+	// True if the API version is not served by the target cluster anymore.
+	Removed bool
+}
+
+type apiDeprecation struct {
+	apiVersion   string
+	kinds        []string
+	deprecatedIn string
+	removedIn    string
+	// Replacements in the order of preference. The first one that the target cluster serves is used.
+	replacements []string
+}
+
+// Deprecated API versions from https://kubernetes.io/docs/reference/using-api/deprecation-guide.
+var apiDeprecations = []*apiDeprecation{
+	{"extensions/v1beta1", []string{"DaemonSet", "Deployment", "ReplicaSet"}, "1.9", "1.16", []string{"apps/v1"}},
+	{"apps/v1beta1", []string{"Deployment", "StatefulSet"}, "1.9", "1.16", []string{"apps/v1"}},
+	{"apps/v1beta2", []string{"DaemonSet", "Deployment", "ReplicaSet", "StatefulSet"}, "1.9", "1.16", []string{"apps/v1"}},
+	{"extensions/v1beta1", []string{"NetworkPolicy"}, "1.9", "1.16", []string{"networking.k8s.io/v1"}},
+	{"extensions/v1beta1", []string{"PodSecurityPolicy"}, "1.11", "1.16", nil},
+	{"extensions/v1beta1", []string{"Ingress"}, "1.14", "1.22", []string{"networking.k8s.io/v1"}},
+	{"networking.k8s.io/v1beta1", []string{"Ingress", "IngressClass"}, "1.19", "1.22", []string{"networking.k8s.io/v1"}},
+	{"admissionregistration.k8s.io/v1beta1", []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, "1.16", "1.22", []string{"admissionregistration.k8s.io/v1"}},
+	{"apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, "1.16", "1.22", []string{"apiextensions.k8s.io/v1"}},
+	{"apiregistration.k8s.io/v1beta1", []string{"APIService"}, "1.19", "1.22", []string{"apiregistration.k8s.io/v1"}},
+	{"authentication.k8s.io/v1beta1", []string{"TokenReview"}, "1.19", "1.22", []string{"authentication.k8s.io/v1"}},
+	{"authorization.k8s.io/v1beta1", []string{"LocalSubjectAccessReview", "SelfSubjectAccessReview", "SubjectAccessReview"}, "1.19", "1.22", []string{"authorization.k8s.io/v1"}},
+	{"certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, "1.19", "1.22", []string{"certificates.k8s.io/v1"}},
+	{"coordination.k8s.io/v1beta1", []string{"Lease"}, "1.19", "1.22", []string{"coordination.k8s.io/v1"}},
+	{"rbac.authorization.k8s.io/v1beta1", []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, "1.17", "1.22", []string{"rbac.authorization.k8s.io/v1"}},
+	{"scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, "1.14", "1.22", []string{"scheduling.k8s.io/v1"}},
+	{"storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, "1.19", "1.22", []string{"storage.k8s.io/v1"}},
+	{"batch/v1beta1", []string{"CronJob"}, "1.21", "1.25", []string{"batch/v1"}},
+	{"discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, "1.21", "1.25", []string{"discovery.k8s.io/v1"}},
+	{"events.k8s.io/v1beta1", []string{"Event"}, "1.19", "1.25", []string{"events.k8s.io/v1"}},
+	{"autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, "1.22", "1.25", []string{"autoscaling/v2"}},
+	{"policy/v1beta1", []string{"PodDisruptionBudget"}, "1.21", "1.25", []string{"policy/v1"}},
+	{"policy/v1beta1", []string{"PodSecurityPolicy"}, "1.21", "1.25", nil},
+	{"node.k8s.io/v1beta1", []string{"RuntimeClass"}, "1.20", "1.25", []string{"node.k8s.io/v1"}},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.23", "1.26", []string{"flowcontrol.apiserver.k8s.io/v1", "flowcontrol.apiserver.k8s.io/v1beta3"}},
+	{"autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, "1.23", "1.26", []string{"autoscaling/v2"}},
+	{"storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, "1.24", "1.27", []string{"storage.k8s.io/v1"}},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.26", "1.29", []string{"flowcontrol.apiserver.k8s.io/v1", "flowcontrol.apiserver.k8s.io/v1beta3"}},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.29", "1.32", []string{"flowcontrol.apiserver.k8s.io/v1"}},
+}
+
+// Returns the deprecation of the given API version of the kind if it is deprecated or removed in the target
+// cluster, nil otherwise. E.g. returns the deprecation of policy/v1beta1 PodDisruptionBudget for Kubernetes 1.21
+// and later versions.
+func (info *KubernetesResourceInfo) GetApiDeprecation(apiVersion string, kind string) *ApiDeprecation {
+	for _, deprecation := range apiDeprecations {
+		if deprecation.apiVersion != apiVersion || !slices.Contains(deprecation.kinds, kind) {
+			continue
+		}
+
+		if info.version.LessThan(semver.MustParse(deprecation.deprecatedIn)) {
+			return nil
+		}
+
+		result := &ApiDeprecation{
+			ApiVersion:   apiVersion,
+			Kind:         kind,
+			DeprecatedIn: deprecation.deprecatedIn,
+			RemovedIn:    deprecation.removedIn,
+			Removed:      deprecation.removedIn != "" && !info.version.LessThan(semver.MustParse(deprecation.removedIn)),
+		}
+
+		for _, replacement := range deprecation.replacements {
+			result.Replacement = replacement
+
+			if info.Contains(replacement, kind) {
+				break
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+func registerApiDeprecation(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[ApiDeprecation]()).JsModule(
+		"kubernetesResourceInfo",
+	).Fields(
+		js.Field("ApiVersion"),
+		js.Field("Kind"),
+		js.Field("DeprecatedIn"),
+		js.Field("RemovedIn"),
+		js.Field("Replacement"),
+		js.Field("Removed"),
+	)
+}
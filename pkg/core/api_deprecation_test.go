@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestGetApiDeprecation(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		apiVersion  string
+		kind        string
+		expected    bool
+		removed     bool
+		replacement string
+	}{
+		{name: "not deprecated yet", version: "1.28", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta3", kind: "FlowSchema", expected: false},
+		{name: "removed", version: "1.26", apiVersion: "policy/v1beta1", kind: "PodDisruptionBudget", expected: true, removed: true, replacement: "policy/v1"},
+		{name: "deprecated", version: "1.27", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", kind: "FlowSchema", expected: true, removed: false, replacement: "flowcontrol.apiserver.k8s.io/v1beta3"},
+		{name: "served replacement", version: "1.30", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", kind: "FlowSchema", expected: true, removed: true, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+		{name: "no replacement", version: "1.26", apiVersion: "policy/v1beta1", kind: "PodSecurityPolicy", expected: true, removed: true, replacement: ""},
+		{name: "other kind", version: "1.26", apiVersion: "policy/v1beta1", kind: "Eviction", expected: false},
+		{name: "current", version: "1.26", apiVersion: "apps/v1", kind: "Deployment", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := NewKubernetesResourceInfo(semver.MustParse(test.version))
+			deprecation := info.GetApiDeprecation(test.apiVersion, test.kind)
+
+			if (deprecation != nil) != test.expected {
+				t.Fatalf("expected deprecation %v, got %v", test.expected, deprecation)
+			}
+
+			if deprecation == nil {
+				return
+			}
+
+			if deprecation.Removed != test.removed {
+				t.Errorf("expected removed %v, got %v", test.removed, deprecation.Removed)
+			}
+
+			if deprecation.Replacement != test.replacement {
+				t.Errorf("expected replacement %q, got %q", test.replacement, deprecation.Replacement)
+			}
+		})
+	}
+}
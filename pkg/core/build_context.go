@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/sobek"
 	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/k8sschema"
 )
 
 // BuildContext provides all the necessary objects and options to generate documents when [Builder.Build] is called.
@@ -17,11 +18,20 @@ type BuildContext struct {
 	CustomData             *sobek.Object
 	JsRuntime              *js.JsRuntime
 
+	// Profiler of the builder, nil if profiling is not enabled.
+	Profiler *Profiler
+
 	builder          *Builder
 	documentGroups   map[*Component][]*DocumentGroup
 	diagnostics      map[*Component][]*Diagnostic
 	reports          map[*Component][]*Report
 	currentComponent *Component
+
+	suppressedDiagnostics map[*Component][]*Diagnostic
+	fixedDiagnostics      map[*Component][]*Diagnostic
+
+	// Schemas of the custom resources, collected when a custom resource is validated for the first time.
+	crdSchemas *k8sschema.CRDSchemas
 }
 
 func NewBuildContext(builder *Builder, options *BuilderOptions) *BuildContext {
@@ -30,10 +40,13 @@ func NewBuildContext(builder *Builder, options *BuilderOptions) *BuildContext {
 		KubernetesResourceInfo: NewKubernetesResourceInfo(builder.Options.KubernetesCluster.Version),
 		CustomData:             builder.jsRuntime.Runtime.NewObject(),
 		JsRuntime:              builder.jsRuntime,
+		Profiler:               builder.profiler,
 		builder:                builder,
 		documentGroups:         map[*Component][]*DocumentGroup{},
 		diagnostics:            map[*Component][]*Diagnostic{},
 		reports:                map[*Component][]*Report{},
+		suppressedDiagnostics:  map[*Component][]*Diagnostic{},
+		fixedDiagnostics:       map[*Component][]*Diagnostic{},
 	}
 
 }
@@ -209,9 +222,19 @@ func (context *BuildContext) GetComponentWithIdentifier(identifier string) *Comp
 	return nil
 }
 
+// Adds given diagnostic to the diagnostics list. Diagnostics that match a suppression are added to
+// the suppressed diagnostics instead, see [BuildContext.GetSuppressedDiagnostics].
 func (context *BuildContext) AddDiagnostic(diagnostic *Diagnostic) {
-	context.diagnostics[context.currentComponent] = append(context.diagnostics[context.currentComponent], diagnostic)
 	diagnostic.component = context.currentComponent
+
+	if suppression := context.findSuppression(diagnostic); suppression != nil {
+		diagnostic.SuppressedBy = suppression
+		context.suppressedDiagnostics[context.currentComponent] = append(context.suppressedDiagnostics[context.currentComponent], diagnostic)
+
+		return
+	}
+
+	context.diagnostics[context.currentComponent] = append(context.diagnostics[context.currentComponent], diagnostic)
 }
 
 func (context *BuildContext) AddReport(report *Report) {
@@ -268,7 +291,15 @@ func registerBuildContext(jsRuntime *js.JsRuntime) {
 		js.Method("AddDiagnostic"),
 		js.Method("AddReport"),
 		js.Method("GetAllDiagnostics"),
+		js.Method("GetSuppressedDiagnostics"),
+		js.Method("SuppressDiagnostic"),
+		js.Method("FixDiagnostic"),
+		js.Method("GetFixedDiagnostics"),
+		js.Method("ValidateCustomResource"),
+		js.Method("ValidateDocumentSchema"),
+		js.Method("GetSchemaFallbackVersion"),
 		js.Method("GetAllReports"),
+		js.Method("GetMutationLog"),
 		js.Method("IsDevelopment"),
 		js.Method("IsProduction"),
 	)
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// Key of the build target in the builder defaults context of the runtime.
+const buildTargetContextKey = "buildTarget"
+
+// BuildTarget is a single target of a build matrix. When a target is set on the runtime, its values override
+// the builder options that the script passes to the builders, and the documents of each builder are written
+// into a subdirectory named after the target.
+type BuildTarget struct {
+	Name                   string                 `yaml:"name"`
+	KubernetesVersion      string                 `yaml:"kubernetesVersion"`
+	KubernetesDistribution KubernetesDistribution `yaml:"kubernetesDistribution"`
+	EnvironmentName        string                 `yaml:"environmentName"`
+	EnvironmentType        EnvironmentType        `yaml:"environmentType"`
+
+	// Options of the builder that is created for this target. Set by the builder.
+	BuilderOptions *BuilderOptions `yaml:"-"`
+}
+
+// Sets the build target of the builders that will be created on the given runtime.
+func SetBuildTarget(jsRuntime *js.JsRuntime, target *BuildTarget) {
+	jsRuntime.BuilderDefaultsContext.Set(buildTargetContextKey, target)
+}
+
+// Returns the build target of the given runtime, nil if the runtime doesn't build a matrix.
+func GetBuildTarget(jsRuntime *js.JsRuntime) *BuildTarget {
+	value := jsRuntime.BuilderDefaultsContext.Get(buildTargetContextKey)
+	if value == nil {
+		return nil
+	}
+
+	target, _ := value.Export().(*BuildTarget)
+	return target
+}
+
+// Overrides the given options with the values that are set on the target. Safe to call on a nil target.
+func (target *BuildTarget) applyTo(options *BuilderOptions) {
+	if target == nil {
+		return
+	}
+
+	if options.KubernetesCluster == nil {
+		options.KubernetesCluster = NewKubernetesCluster(nil, KubernetesDistributionUnknown)
+	}
+
+	if target.KubernetesVersion != "" {
+		version, err := semver.NewVersion(target.KubernetesVersion)
+		if err != nil {
+			js.Throw(fmt.Errorf("invalid Kubernetes version %s for build target %s: %w", target.KubernetesVersion, target.Name, err))
+		}
+
+		options.KubernetesCluster.Version = version
+	}
+
+	if target.KubernetesDistribution != "" {
+		options.KubernetesCluster.Distribution = target.KubernetesDistribution
+	}
+
+	if options.Environment == nil {
+		options.Environment = NewEnvironment("", EnvironmentTypeUnknown)
+	}
+
+	if target.EnvironmentName != "" {
+		options.Environment.Name = target.EnvironmentName
+	}
+
+	if target.EnvironmentType != "" {
+		options.Environment.Type = target.EnvironmentType
+	}
+}
+
+// Moves the output path of the given sanitized options into the subdirectory of the target and records the
+// options. Matrix summaries compare the output of a single builder, so the scripts that create more than one
+// builder are rejected. Safe to call on a nil target.
+func (target *BuildTarget) applyOutputPath(options *BuilderOptions) {
+	if target == nil {
+		return
+	}
+
+	if target.BuilderOptions != nil {
+		js.Throw(fmt.Errorf("script creates more than one builder for build target %s, matrix builds only support scripts that create a single builder", target.Name))
+	}
+
+	options.OutputConfiguration.OutputPath = filepath.Join(options.OutputConfiguration.OutputPath, target.Name)
+	target.BuilderOptions = options
+}
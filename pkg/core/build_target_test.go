@@ -0,0 +1,32 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func TestBuildTargetRejectsMultipleBuilders(t *testing.T) {
+	target := &BuildTarget{Name: "dev"}
+	options := &BuilderOptions{OutputConfiguration: &OutputConfiguration{OutputPath: "output"}}
+
+	target.applyOutputPath(options)
+
+	if target.BuilderOptions != options {
+		t.Fatal("expected the options of the first builder to be recorded")
+	}
+
+	defer func() {
+		err, ok := recover().(js.JsError)
+		if !ok {
+			t.Fatal("expected the second builder of the target to be rejected")
+		}
+
+		if !strings.Contains(err.Error(), "more than one builder") {
+			t.Errorf("unexpected error %v", err)
+		}
+	}()
+
+	target.applyOutputPath(&BuilderOptions{OutputConfiguration: &OutputConfiguration{OutputPath: "output"}})
+}
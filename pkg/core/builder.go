@@ -21,12 +21,21 @@ import (
 //go:embed builderDefaults.js
 var builderDefaultsScript string
 
+// Key of the builder observer in the builder defaults context of the runtime.
+const builderObserverContextKey = "builderObserver"
+
 // Builder is a collection of components.
 type Builder struct {
 	Components []*Component
 	Options    *BuilderOptions
 
-	jsRuntime *js.JsRuntime
+	jsRuntime          *js.JsRuntime
+	profiler           *Profiler
+	provenanceTracking bool
+	mutationLogging    bool
+	mutationLog        []*MutationLogEntry
+
+	diagnosticSuppressions []*DiagnosticSuppression
 }
 
 // Appends given component to the list of components.
@@ -132,6 +141,34 @@ func (builder *Builder) AddAdditionalFileWithGroupPath(documentGroupPath string,
 	})
 }
 
+// Enables measuring the time spent in each action and returns the profiler. Calling it more than once
+// returns the same profiler.
+func (builder *Builder) EnableProfiling() *Profiler {
+	if builder.profiler == nil {
+		builder.profiler = NewProfiler()
+	}
+
+	return builder.profiler
+}
+
+// Enables recording the components that create and modify each document. Detecting modifications requires
+// comparing the documents after every action, so nothing is recorded unless this is enabled.
+func (builder *Builder) EnableProvenanceTracking() {
+	builder.provenanceTracking = true
+}
+
+// Enables recording the changes each component action makes to the documents as JSON Patch operations.
+// Also enables provenance tracking since both require comparing the documents after every action.
+func (builder *Builder) EnableMutationLog() {
+	builder.EnableProvenanceTracking()
+	builder.mutationLogging = true
+}
+
+// Returns the profiler of the builder, nil if profiling is not enabled.
+func (builder *Builder) GetProfiler() *Profiler {
+	return builder.profiler
+}
+
 // Creates a new component with the given action and adds it to the list of components.
 func (builder *Builder) OnStep(step *Step, callback func(context *BuildContext)) *Component {
 	component := NewComponent()
@@ -185,6 +222,11 @@ func (builder *Builder) Build() {
 
 	context := NewBuildContext(builder, builder.Options)
 
+	if builder.profiler != nil {
+		activeProfilers.Store(builder.jsRuntime, builder.profiler)
+		defer activeProfilers.Delete(builder.jsRuntime)
+	}
+
 	for _, resource := range builder.Options.KubernetesCluster.AdditionalResources {
 		context.KubernetesResourceInfo.AddKubernetesResource(resource)
 	}
@@ -248,6 +290,13 @@ func (builder *Builder) Build() {
 
 	var lastAppliedStep *Step = nil
 
+	var tracker *provenanceTracker
+	if builder.provenanceTracking {
+		tracker = newProvenanceTracker(builder.mutationLogging)
+	}
+
+	builder.mutationLog = nil
+
 	for i := 0; i < len(steps); i++ {
 		step := steps[i]
 		if lastAppliedStep != nil && step.Compare(*lastAppliedStep) < 0 {
@@ -264,6 +313,9 @@ func (builder *Builder) Build() {
 		// Cloning the components slice to avoid issues with components being added or removed during the loop.
 		components := slices.Clone(builder.Components)
 
+		// Documents are not modified after the output step starts, only their creators are recorded.
+		detectModifications := step.Compare(*StepOutput) < 0
+
 		for _, component := range components {
 			context.currentComponent = component
 
@@ -273,7 +325,11 @@ func (builder *Builder) Build() {
 				}
 
 				if action.Callback != nil {
+					endSpan := context.Profiler.startActionSpan(step, component)
 					action.Callback(context)
+					endSpan()
+
+					tracker.recordAction(context, step, component, detectModifications)
 				}
 			}
 		}
@@ -282,6 +338,38 @@ func (builder *Builder) Build() {
 		// Components may have added new actions, so we need to recompute the steps.
 		steps = builder.getSteps()
 	}
+
+	builder.writeProfile()
+}
+
+func (builder *Builder) writeProfile() {
+	profiler := builder.profiler
+	if profiler == nil {
+		return
+	}
+
+	slog.Info("Slowest components:")
+
+	for i, entry := range profiler.SummaryByComponent() {
+		if i == 10 {
+			break
+		}
+
+		slog.Info(
+			"  ${duration} ${component}",
+			slog.String("duration", fmt.Sprintf("%10s", formatProfileDuration(entry.Duration))),
+			slog.String("component", entry.Name))
+	}
+
+	if profiler.TraceFilePath == "" {
+		return
+	}
+
+	if err := profiler.WriteChromeTrace(profiler.TraceFilePath); err != nil {
+		js.Throw(err)
+	}
+
+	slog.Info("Wrote build profile trace to ${path}", slog.String("path", profiler.TraceFilePath))
 }
 
 func (builder *Builder) getSteps() []Step {
@@ -370,12 +458,54 @@ func NewBuilderWithOptions(options *BuilderOptions, jsRuntime *js.JsRuntime) *Bu
 		jsRuntime: jsRuntime,
 	}
 
+	target := GetBuildTarget(jsRuntime)
+	target.applyTo(builder.Options)
+
 	builder.sanitizeBuilderOptions(builder.Options)
+	target.applyOutputPath(builder.Options)
 	initializeBuilderWithDefaults(jsRuntime, builder)
 
+	if observer := getBuilderObserver(jsRuntime); observer != nil && observer.OnBuilderCreated != nil {
+		observer.OnBuilderCreated(builder)
+	}
+
 	return builder
 }
 
+// Creates a new [Builder] instance with given options without the default components, e.g. to run a build
+// with only the given components.
+func NewEmptyBuilder(options *BuilderOptions, jsRuntime *js.JsRuntime) *Builder {
+	if options == nil {
+		options = &BuilderOptions{}
+	}
+
+	return &Builder{
+		Options:   options,
+		jsRuntime: jsRuntime,
+	}
+}
+
+// BuilderObserver is notified about the builders that are created on a runtime, e.g. to capture their output.
+type BuilderObserver struct {
+	// Called with each builder after the default components are added to it.
+	OnBuilderCreated func(builder *Builder)
+}
+
+// Sets the observer of the builders that will be created on the given runtime.
+func SetBuilderObserver(jsRuntime *js.JsRuntime, observer *BuilderObserver) {
+	jsRuntime.BuilderDefaultsContext.Set(builderObserverContextKey, observer)
+}
+
+func getBuilderObserver(jsRuntime *js.JsRuntime) *BuilderObserver {
+	value := jsRuntime.BuilderDefaultsContext.Get(builderObserverContextKey)
+	if value == nil {
+		return nil
+	}
+
+	observer, _ := value.Export().(*BuilderObserver)
+	return observer
+}
+
 func NewBuilderVersionDistributionEnvironmentType(version *semver.Version, distribution KubernetesDistribution, environment EnvironmentType, jsRuntime *js.JsRuntime) *Builder {
 	options := NewBuilderOptions(
 		NewKubernetesCluster(version, distribution),
@@ -438,6 +568,13 @@ func registerBuilder(jsRuntime *js.JsRuntime) {
 		js.Method("OnGenerateResourcesBasedOnOtherResources"),
 		js.Method("OnModify"),
 		js.Method("OnSpecifyProvisionerDependencies"),
+		js.Method("EnableProfiling"),
+		js.Method("GetProfiler"),
+		js.Method("EnableProvenanceTracking"),
+		js.Method("EnableMutationLog"),
+		js.Method("GetMutationLog"),
+		js.Method("SuppressDiagnostics"),
+		js.Method("SuppressDiagnosticIds").JsName("suppressDiagnostics"),
 		js.Method("Build"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewBuilder)),
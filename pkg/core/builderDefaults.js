@@ -2,24 +2,71 @@
     const anemos = require("@ohayocorp/anemos");
     const builder = context.builder;
 
-    builder.deleteOutputDirectory();
-    builder.reportDiagnostics();
-    builder.writeDocuments();
-    builder.writeReports();
+    // Tests compare the documents with the snapshots instead of writing them into the output directory.
+    if (!context.test) {
+        builder.deleteOutputDirectory();
+        builder.writeDocuments();
+        builder.writeReports();
+    }
+
+    builder.reportDiagnostics({
+        failOn: context.failOn,
+        failOnCategories: context.failOnCategories
+    });
 
     anemos.sortFields.add(builder);
     anemos.setDefaultProvisionerDependencies.add(builder);
     anemos.collectCRDs.add(builder);
     anemos.collectNamespaces.add(builder);
 
+    if (context.profile) {
+        builder.profile();
+    }
+
+    if (context.diagnosticsFormats && context.diagnosticsFormats.length > 0) {
+        builder.writeDiagnostics({
+            outputTypes: context.diagnosticsFormats
+        });
+    }
+
+    if (context.fix) {
+        builder.applyFixes();
+    }
+
+    // Policies in the build are evaluated even if no policy file is given. The check is skipped when there is
+    // neither a policy file nor a binding in the build.
+    builder.checkAdmissionPolicies({
+        files: context.admissionPolicyFiles ?? []
+    });
+
+    if (context.diagnosticsBaseline) {
+        builder.useDiagnosticsBaseline({
+            path: context.diagnosticsBaseline,
+            update: !!context.updateDiagnosticsBaseline
+        });
+    }
+
+    if (context.compareOutput) {
+        builder.compareOutput();
+    }
+
     anemos.diagnostics.addDefaultDiagnostics(builder);
     anemos.reports.addDefaultReports(builder);
 
-    if (context.apply) {
-        builder.apply({
+    if (context.apply || context.diffOnly) {
+        const applyOptions = {
             skipConfirmation: context.skipConfirmation,
             forceConflicts: context.forceConflicts,
-            documentGroups: context.documentGroups
-        });
+            documentGroups: context.documentGroups,
+            maxConcurrency: context.maxConcurrency,
+            diffOnly: !!context.diffOnly
+        };
+
+        // Only the build and diff commands count the changes, undefined can't be converted to a callback.
+        if (context.onDiffCompleted) {
+            applyOptions.onDiffCompleted = context.onDiffCompleted;
+        }
+
+        builder.apply(applyOptions);
     }
 })(__anemos__context);
\ No newline at end of file
@@ -30,6 +30,10 @@ const (
 
 const (
 	DocumentsDir = "manifests"
+
+	// Lists the paths of the document files in the documents directory, relative to the directory. Other files
+	// in the directory, e.g. the additional files of the document groups, are not manifests.
+	DocumentsIndexFile = ".documents"
 )
 
 // EnvironmentType represents the type of the target environment such as Development or Production.
@@ -49,6 +53,9 @@ type KubernetesCluster struct {
 	Version             *semver.Version
 	Distribution        KubernetesDistribution
 	AdditionalResources []*KubernetesResource
+	// Paths of the YAML files that contain the CRDs of the cluster, e.g. the output of "kubectl get crds -o yaml".
+	// Custom resources are validated against the schemas of these CRDs.
+	SchemaFiles []string
 }
 
 // OutputConfiguration specifies the output paths.
@@ -130,6 +137,7 @@ func registerBuilderOptions(jsRuntime *js.JsRuntime) {
 		js.Field("Version"),
 		js.Field("Distribution"),
 		js.Field("AdditionalResources"),
+		js.Field("SchemaFiles"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewKubernetesCluster)),
 		js.Constructor(reflect.ValueOf(NewKubernetesClusterWithAdditionalResources)),
@@ -17,6 +17,7 @@ const (
 	DiagnosticCategoryLinting  DiagnosticCategory = "linting"
 	DiagnosticCategorySecurity DiagnosticCategory = "security"
 	DiagnosticCategorySpecs    DiagnosticCategory = "specs"
+	DiagnosticCategoryPolicy   DiagnosticCategory = "policy"
 )
 
 type DiagnosticMetadata struct {
@@ -25,16 +26,48 @@ type DiagnosticMetadata struct {
 	Description string
 	Severity    DiagnosticSeverity
 	Categories  []DiagnosticCategory
+	// Fails the build before the output step even if no severity to fail on is set, e.g. for the inputs that
+	// can't be rendered.
+	FailsBuild bool
 }
 
 type Diagnostic struct {
 	Metadata DiagnosticMetadata
 	Message  string
 	Document *Document
+	// Path of the field in the document that caused the diagnostic, e.g. "spec.template.spec.containers[0].image".
+	FieldPath string
+	// Location in the source files that caused the diagnostic. Resolved from the document and
+	// the field path if not set explicitly.
+	Location *SourceLocation
+	// Optional change that resolves the diagnostic.
+	Fix *DiagnosticFix
+	// Suppression that matched the diagnostic, nil if the diagnostic is not suppressed.
+	SuppressedBy *DiagnosticSuppression
 
 	component *Component
 }
 
+// Returns the level of the severity that is used to compare severities, higher levels are more severe.
+// Returns -1 for unknown severities.
+func (severity DiagnosticSeverity) Level() int {
+	switch severity {
+	case DiagnosticSeverityInfo:
+		return 0
+	case DiagnosticSeverityWarning:
+		return 1
+	case DiagnosticSeverityError:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// Returns true if the severity is at least as severe as the given severity.
+func (severity DiagnosticSeverity) IsAtLeast(other DiagnosticSeverity) bool {
+	return severity.Level() >= other.Level()
+}
+
 func NewDiagnosticMetadata(id string, name string, description string, severity DiagnosticSeverity, categories []DiagnosticCategory) *DiagnosticMetadata {
 	return &DiagnosticMetadata{
 		Id:          id,
@@ -60,6 +93,30 @@ func NewDiagnosticWithDocument(metadata *DiagnosticMetadata, message string, doc
 	}
 }
 
+func NewDiagnosticWithField(metadata *DiagnosticMetadata, message string, document *Document, fieldPath string) *Diagnostic {
+	return &Diagnostic{
+		Metadata:  *metadata,
+		Message:   message,
+		Document:  document,
+		FieldPath: fieldPath,
+	}
+}
+
+// Returns the location in the source files that caused the diagnostic. Uses the location of the field
+// in the YAML text the document was parsed from if it is known, otherwise the location of the call that
+// created the document. Returns nil if the location is unknown.
+func (diagnostic *Diagnostic) GetSourceLocation() *SourceLocation {
+	if diagnostic.Location != nil {
+		return diagnostic.Location
+	}
+
+	if diagnostic.Document == nil || diagnostic.Document.source == nil {
+		return nil
+	}
+
+	return diagnostic.Document.source.GetFieldLocation(diagnostic.FieldPath)
+}
+
 func registerDiagnostic(jsRuntime *js.JsRuntime) {
 	jsRuntime.Variable("diagnostic", "info", reflect.ValueOf(DiagnosticSeverityInfo))
 	jsRuntime.Variable("diagnostic", "warning", reflect.ValueOf(DiagnosticSeverityWarning))
@@ -68,6 +125,7 @@ func registerDiagnostic(jsRuntime *js.JsRuntime) {
 	jsRuntime.Variable("diagnostic", "linting", reflect.ValueOf(DiagnosticCategoryLinting))
 	jsRuntime.Variable("diagnostic", "security", reflect.ValueOf(DiagnosticCategorySecurity))
 	jsRuntime.Variable("diagnostic", "specs", reflect.ValueOf(DiagnosticCategorySpecs))
+	jsRuntime.Variable("diagnostic", "policy", reflect.ValueOf(DiagnosticCategoryPolicy))
 
 	jsRuntime.Type(reflect.TypeFor[DiagnosticMetadata]()).JsModule(
 		"diagnostic",
@@ -77,6 +135,7 @@ func registerDiagnostic(jsRuntime *js.JsRuntime) {
 		js.Field("Description"),
 		js.Field("Severity"),
 		js.Field("Categories"),
+		js.Field("FailsBuild"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewDiagnosticMetadata)),
 	)
@@ -87,8 +146,15 @@ func registerDiagnostic(jsRuntime *js.JsRuntime) {
 		js.Field("Metadata"),
 		js.Field("Message"),
 		js.Field("Document"),
+		js.Field("FieldPath"),
+		js.Field("Location"),
+		js.Field("Fix"),
+		js.Field("SuppressedBy"),
+	).Methods(
+		js.Method("GetSourceLocation"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewDiagnostic)),
 		js.Constructor(reflect.ValueOf(NewDiagnosticWithDocument)),
+		js.Constructor(reflect.ValueOf(NewDiagnosticWithField)),
 	)
 }
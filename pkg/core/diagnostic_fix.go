@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// DiagnosticFix changes the documents to resolve a diagnostic, e.g. adds the missing labels. Fixes are only
+// applied when they are requested, e.g. with "anemos build --fix".
+type DiagnosticFix struct {
+	// Short explanation of the change, e.g. "Set runAsNonRoot to true".
+	Description string
+	// Changes the documents to resolve the diagnostic.
+	Apply func(context *BuildContext)
+}
+
+func NewDiagnosticFix(description string, apply func(context *BuildContext)) *DiagnosticFix {
+	return &DiagnosticFix{
+		Description: description,
+		Apply:       apply,
+	}
+}
+
+// Applies the fix of the given diagnostic and moves the diagnostic to the fixed diagnostics so that it is
+// neither reported nor fails the build.
+func (context *BuildContext) FixDiagnostic(diagnostic *Diagnostic) {
+	if diagnostic.Fix == nil || diagnostic.Fix.Apply == nil {
+		js.Throw(fmt.Errorf("diagnostic %s doesn't have a fix", diagnostic.Metadata.Id))
+	}
+
+	diagnostic.Fix.Apply(context)
+
+	component := diagnostic.component
+
+	context.diagnostics[component] = slices.DeleteFunc(context.diagnostics[component], func(d *Diagnostic) bool {
+		return d == diagnostic
+	})
+
+	context.fixedDiagnostics[component] = append(context.fixedDiagnostics[component], diagnostic)
+}
+
+// Returns the diagnostics whose fixes are applied.
+func (context *BuildContext) GetFixedDiagnostics() []*Diagnostic {
+	diagnostics := []*Diagnostic{}
+	for _, d := range context.fixedDiagnostics {
+		diagnostics = append(diagnostics, d...)
+	}
+
+	return diagnostics
+}
+
+func registerDiagnosticFix(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[DiagnosticFix]()).JsModule(
+		"diagnostic",
+	).Fields(
+		js.Field("Description"),
+		js.Field("Apply"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewDiagnosticFix)),
+	)
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// Annotation that suppresses the diagnostics with the given comma separated ids on a document,
+// e.g. "run-as-root,missing-resource-requirements".
+const SuppressDiagnosticsAnnotation = "diagnostics.anemos.sh/suppress"
+
+type DiagnosticSuppressionKind string
+
+const (
+	// Suppressed with the [SuppressDiagnosticsAnnotation] on the document.
+	DiagnosticSuppressionKindAnnotation DiagnosticSuppressionKind = "annotation"
+	// Suppressed with [Builder.SuppressDiagnostics].
+	DiagnosticSuppressionKindBuilder DiagnosticSuppressionKind = "builder"
+	// Accepted in a diagnostics baseline file.
+	DiagnosticSuppressionKindBaseline DiagnosticSuppressionKind = "baseline"
+)
+
+// DiagnosticSuppression suppresses the diagnostics with the given ids. Suppressions can be limited to the documents
+// of a component or a document group, all diagnostics with the given ids are suppressed otherwise. Suppressed
+// diagnostics are neither reported as problems nor fail the build, but they are still counted in the reports.
+type DiagnosticSuppression struct {
+	Ids []string
+	// Limits the suppression to the diagnostics of the documents that are created by this component
+	// and to the diagnostics that are reported by this component.
+	Component *Component
+	// Limits the suppression to the diagnostics of the documents in the document group with this path.
+	DocumentGroup *string
+	// Explanation of why the diagnostics are suppressed.
+	Reason string
+
+	Kind DiagnosticSuppressionKind
+}
+
+func NewDiagnosticSuppression(ids []string) *DiagnosticSuppression {
+	return &DiagnosticSuppression{
+		Ids:  ids,
+		Kind: DiagnosticSuppressionKindBuilder,
+	}
+}
+
+// Suppresses the diagnostics that match the given suppression.
+func (builder *Builder) SuppressDiagnostics(suppression *DiagnosticSuppression) {
+	if suppression.Kind == "" {
+		suppression.Kind = DiagnosticSuppressionKindBuilder
+	}
+
+	builder.diagnosticSuppressions = append(builder.diagnosticSuppressions, suppression)
+}
+
+// Suppresses the diagnostics with the given ids in all documents.
+func (builder *Builder) SuppressDiagnosticIds(ids []string) {
+	builder.SuppressDiagnostics(NewDiagnosticSuppression(ids))
+}
+
+// Returns true if the suppression applies to the given diagnostic.
+func (suppression *DiagnosticSuppression) Matches(diagnostic *Diagnostic) bool {
+	if !slices.Contains(suppression.Ids, diagnostic.Metadata.Id) {
+		return false
+	}
+
+	if suppression.DocumentGroup != nil {
+		document := diagnostic.Document
+		if document == nil || document.Group == nil || document.Group.Path != *suppression.DocumentGroup {
+			return false
+		}
+	}
+
+	if suppression.Component != nil {
+		document := diagnostic.Document
+		createdByComponent := document != nil && document.Group != nil && document.Group.component == suppression.Component
+
+		if !createdByComponent && diagnostic.component != suppression.Component {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the ids in the suppression annotation of the document.
+func getSuppressedDiagnosticIds(document *Document) []string {
+	if document == nil || document.Object == nil {
+		return nil
+	}
+
+	metadata, ok := document.Object.Get("metadata").(*sobek.Object)
+	if !ok || metadata == nil {
+		return nil
+	}
+
+	annotations, ok := metadata.Get("annotations").(*sobek.Object)
+	if !ok || annotations == nil {
+		return nil
+	}
+
+	value := annotations.Get(SuppressDiagnosticsAnnotation)
+	if value == nil || sobek.IsUndefined(value) || sobek.IsNull(value) {
+		return nil
+	}
+
+	ids := []string{}
+	for _, id := range strings.Split(value.String(), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Returns the suppression that applies to the given diagnostic, nil if the diagnostic is not suppressed.
+func (context *BuildContext) findSuppression(diagnostic *Diagnostic) *DiagnosticSuppression {
+	if slices.Contains(getSuppressedDiagnosticIds(diagnostic.Document), diagnostic.Metadata.Id) {
+		return &DiagnosticSuppression{
+			Ids:    []string{diagnostic.Metadata.Id},
+			Reason: "Suppressed with the " + SuppressDiagnosticsAnnotation + " annotation",
+			Kind:   DiagnosticSuppressionKindAnnotation,
+		}
+	}
+
+	for _, suppression := range context.builder.diagnosticSuppressions {
+		if suppression.Matches(diagnostic) {
+			return suppression
+		}
+	}
+
+	return nil
+}
+
+// Suppresses the given diagnostic, i.e. moves it from the diagnostics to the suppressed diagnostics.
+func (context *BuildContext) SuppressDiagnostic(diagnostic *Diagnostic, suppression *DiagnosticSuppression) {
+	component := diagnostic.component
+
+	context.diagnostics[component] = slices.DeleteFunc(context.diagnostics[component], func(d *Diagnostic) bool {
+		return d == diagnostic
+	})
+
+	diagnostic.SuppressedBy = suppression
+	context.suppressedDiagnostics[component] = append(context.suppressedDiagnostics[component], diagnostic)
+}
+
+// Returns the diagnostics that are suppressed by annotations, builder suppressions or baselines.
+func (context *BuildContext) GetSuppressedDiagnostics() []*Diagnostic {
+	diagnostics := []*Diagnostic{}
+	for _, d := range context.suppressedDiagnostics {
+		diagnostics = append(diagnostics, d...)
+	}
+
+	return diagnostics
+}
+
+func registerDiagnosticSuppression(jsRuntime *js.JsRuntime) {
+	jsRuntime.Variable("diagnostic", "suppressDiagnosticsAnnotation", reflect.ValueOf(SuppressDiagnosticsAnnotation))
+
+	jsRuntime.Type(reflect.TypeFor[DiagnosticSuppression]()).JsModule(
+		"diagnostic",
+	).Fields(
+		js.Field("Ids"),
+		js.Field("Component"),
+		js.Field("DocumentGroup"),
+		js.Field("Reason"),
+		js.Field("Kind"),
+	).Methods(
+		js.Method("Matches"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewDiagnosticSuppression)),
+	)
+}
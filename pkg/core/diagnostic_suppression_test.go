@@ -0,0 +1,38 @@
+package core
+
+import "testing"
+
+func TestDiagnosticSuppressionMatches(t *testing.T) {
+	metadata := &DiagnosticMetadata{Id: "run-as-root"}
+	chart := NewComponent()
+	other := NewComponent()
+
+	group := NewDocumentGroup("legacy")
+	group.component = chart
+
+	document := NewDocumentWithContent(nil)
+	document.Group = group
+
+	diagnostic := NewDiagnosticWithDocument(metadata, "", document)
+	diagnostic.component = other
+
+	tests := []struct {
+		name        string
+		suppression *DiagnosticSuppression
+		expected    bool
+	}{
+		{name: "id", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}}, expected: true},
+		{name: "other id", suppression: &DiagnosticSuppression{Ids: []string{"missing-labels"}}, expected: false},
+		{name: "document group", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}, DocumentGroup: Pointer("legacy")}, expected: true},
+		{name: "other document group", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}, DocumentGroup: Pointer("app")}, expected: false},
+		{name: "creator component", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}, Component: chart}, expected: true},
+		{name: "reporter component", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}, Component: other}, expected: true},
+		{name: "unrelated component", suppression: &DiagnosticSuppression{Ids: []string{"run-as-root"}, Component: NewComponent()}, expected: false},
+	}
+
+	for _, test := range tests {
+		if actual := test.suppression.Matches(diagnostic); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
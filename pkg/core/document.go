@@ -26,6 +26,9 @@ type Document struct {
 	path         *string
 	Group        *DocumentGroup
 	Dependencies *Dependencies[*Document]
+
+	provenance *DocumentProvenance
+	source     *DocumentSource
 }
 
 func NewNewDocumentOptions() *NewDocumentOptions {
@@ -38,12 +41,32 @@ func NewDocument(jsRuntime *js.JsRuntime) *Document {
 }
 
 func NewDocumentWithYaml(jsRuntime *js.JsRuntime, yaml string) (*Document, error) {
-	content, err := Parse(jsRuntime, yaml)
+	callSite := getScriptCallSite(jsRuntime)
+
+	// YAML is usually given as a template literal that starts on the line of the call, so the lines
+	// of the fields are counted from the call site.
+	file := ""
+	firstLine := 1
+
+	if callSite != nil {
+		file = callSite.File
+		firstLine = callSite.Line
+	}
+
+	locations := newYamlLocations(file, firstLine, yaml)
+
+	content, err := parseWithLocations(jsRuntime, yaml, locations)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewDocumentWithContent(content), nil
+	document := NewDocumentWithContent(content)
+	document.source = &DocumentSource{
+		CallSite: callSite,
+		fields:   locations.fields,
+	}
+
+	return document, nil
 }
 
 func NewDocumentWithContent(content *sobek.Object) *Document {
@@ -74,6 +97,9 @@ func NewDocumentWithOptions(jsRuntime *js.JsRuntime, options *NewDocumentOptions
 		}
 	} else if options.Object != nil {
 		document = NewDocumentWithContent(options.Object)
+		document.source = &DocumentSource{
+			CallSite: getScriptCallSite(jsRuntime),
+		}
 	}
 
 	document.SetPath(options.Path)
@@ -248,6 +274,8 @@ func registerDocument(jsRuntime *js.JsRuntime) {
 		js.Method("ProvisionAfter"),
 		js.Method("ProvisionBefore"),
 		js.Method("ToJSON"),
+		js.Method("GetProvenance"),
+		js.Method("GetSource"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewDocument)),
 		js.Constructor(reflect.ValueOf(NewDocumentWithOptions)),
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
+	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"slices"
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/sobek"
 	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/remote"
 	"github.com/ohayocorp/anemos/pkg/util"
 	"gopkg.in/yaml.v3"
 
@@ -23,6 +24,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 )
@@ -33,6 +35,11 @@ type HelmOptions struct {
 	Namespace    string
 	ValuesString *string
 	ValuesObject *sobek.Object
+	// Reports the values that don't exist in the values.yaml or the values.schema.json of the chart.
+	CheckUnknownValues bool
+
+	// Location of the call that added the chart, diagnostics about the values point to it.
+	location *SourceLocation
 }
 
 func NewHelmOptions(releaseName string, namespace string) *HelmOptions {
@@ -74,68 +81,127 @@ func LoadChartFromPath(path string) *chart.Chart {
 		js.Throw(fmt.Errorf("can't load chart from path %s, %v", path, err))
 	}
 
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		addLocalHelmDependencies(chart, path)
+	}
+
 	return chart
 }
 
 func AddHelmChart(builder *Builder, chartIdentifier string, releaseName string, values string) {
+	AddHelmChartWithOptions(builder, chartIdentifier, NewHelmOptionsWithValues(releaseName, "", values))
+}
+
+func AddHelmChartObject(builder *Builder, chartIdentifier string, releaseName string, values *sobek.Object) {
+	AddHelmChart(builder, chartIdentifier, releaseName, serializeHelmValues(builder, values))
+}
+
+func AddHelmChartNoValues(builder *Builder, chartIdentifier string, releaseName string) {
+	AddHelmChart(builder, chartIdentifier, releaseName, "")
+}
+
+func AddHelmChartFromReference(builder *Builder, reference *HelmChartReference, releaseName string, values string) {
+	AddHelmChartFromReferenceWithOptions(builder, reference, NewHelmOptionsWithValues(releaseName, "", values))
+}
+
+func AddHelmChartFromReferenceObject(builder *Builder, reference *HelmChartReference, releaseName string, values *sobek.Object) {
+	AddHelmChartFromReference(builder, reference, releaseName, serializeHelmValues(builder, values))
+}
+
+func AddHelmChartFromReferenceNoValues(builder *Builder, reference *HelmChartReference, releaseName string) {
+	AddHelmChartFromReference(builder, reference, releaseName, "")
+}
+
+// Adds the chart with the given options, e.g. to set the namespace of the release or to check the unknown values.
+func AddHelmChartWithOptions(builder *Builder, chartIdentifier string, options *HelmOptions) {
 	if chartIdentifier == "" {
 		js.Throw(fmt.Errorf("chart identifier is not defined"))
 	}
 
-	slog.Info(
-		"Adding Helm chart: ${chart}, release name: ${releaseName}",
-		slog.String("chart", chartIdentifier),
-		slog.String("releaseName", releaseName))
-
-	builder.OnStep(StepGenerateResources, func(context *BuildContext) {
-		var chart *chart.Chart
+	addHelmChart(builder, chartIdentifier, func(context *BuildContext) *chart.Chart {
+		return loadChartFromIdentifier(context.JsRuntime, chartIdentifier)
+	}, options)
+}
 
-		if strings.HasPrefix(chartIdentifier, "http://") || strings.HasPrefix(chartIdentifier, "https://") {
-			response, err := http.Get(chartIdentifier)
-			if err != nil {
-				js.Throw(fmt.Errorf("can't load chart from URL %s, %v", chartIdentifier, err))
-			}
-			defer response.Body.Close()
+func AddHelmChartFromReferenceWithOptions(builder *Builder, reference *HelmChartReference, options *HelmOptions) {
+	if reference == nil || reference.Chart == "" {
+		js.Throw(fmt.Errorf("chart name is not defined on the chart reference"))
+	}
 
-			if response.StatusCode != http.StatusOK {
-				js.Throw(fmt.Errorf("can't load chart from URL %s, status code: %d", chartIdentifier, response.StatusCode))
-			}
+	addHelmChart(builder, reference.String(), func(context *BuildContext) *chart.Chart {
+		return LoadChartFromReference(context.JsRuntime, reference)
+	}, options)
+}
 
-			data, err := io.ReadAll(response.Body)
-			if err != nil {
-				js.Throw(fmt.Errorf("can't read chart data from URL %s, %v", chartIdentifier, err))
-			}
+func addHelmChart(builder *Builder, chartDescription string, loadChart func(context *BuildContext) *chart.Chart, options *HelmOptions) {
+	options.sanitize()
+	options.location = getScriptCallSite(builder.jsRuntime)
 
-			chart = LoadChart(data)
-		} else {
-			chart = LoadChartFromPath(chartIdentifier)
-		}
+	slog.Info(
+		"Adding Helm chart: ${chart}, release name: ${releaseName}",
+		slog.String("chart", chartDescription),
+		slog.String("releaseName", options.ReleaseName))
 
+	builder.OnStep(StepGenerateResources, func(context *BuildContext) {
+		chart := loadChart(context)
 		if chart == nil {
-			js.Throw(fmt.Errorf("can't load chart from path %s", chartIdentifier))
+			js.Throw(fmt.Errorf("can't load chart %s", chartDescription))
 		}
 
-		options := NewHelmOptionsWithValues(releaseName, "", values)
-
 		documentGroup := GenerateFromChart(chart, context, options)
 		context.AddDocumentGroup(documentGroup)
 	})
 }
 
-func AddHelmChartObject(builder *Builder, chartIdentifier string, releaseName string, values *sobek.Object) {
+func serializeHelmValues(builder *Builder, values *sobek.Object) string {
 	valuesString, err := SerializeSobekObjectToYaml(builder.jsRuntime, values)
 	if err != nil {
 		js.Throw(fmt.Errorf("can't serialize values object to yaml, %v", err))
 	}
 
-	AddHelmChart(builder, chartIdentifier, releaseName, valuesString)
+	return valuesString
 }
 
-func AddHelmChartNoValues(builder *Builder, chartIdentifier string, releaseName string) {
-	AddHelmChart(builder, chartIdentifier, releaseName, "")
+// Loads the chart using the same rules as the Helm CLI. The identifier can be an URL of a chart archive,
+// a local path, an OCI reference such as oci://registry.example.com/charts/chart:1.2.3 or a "repo/chart"
+// reference for the repositories that are added with "helm repo add". Remote charts are recorded in the lockfile.
+func loadChartFromIdentifier(jsRuntime *js.JsRuntime, chartIdentifier string) *chart.Chart {
+	if strings.HasPrefix(chartIdentifier, "http://") || strings.HasPrefix(chartIdentifier, "https://") {
+		return loadChartFromUrl(jsRuntime, chartIdentifier)
+	}
+
+	if registry.IsOCI(chartIdentifier) {
+		return LoadChartFromReference(jsRuntime, &HelmChartReference{Chart: chartIdentifier})
+	}
+
+	// Helm treats absolute paths and paths starting with a dot as local paths even if they don't exist.
+	if _, err := os.Stat(chartIdentifier); err == nil || filepath.IsAbs(chartIdentifier) || strings.HasPrefix(chartIdentifier, ".") {
+		return LoadChartFromPath(chartIdentifier)
+	}
+
+	return LoadChartFromReference(jsRuntime, &HelmChartReference{Chart: chartIdentifier})
 }
 
-// Runs helm template with values from the options and parses the generated documents.
+func loadChartFromUrl(jsRuntime *js.JsRuntime, url string) *chart.Chart {
+	data, err := js.FetchRemote(jsRuntime, &remote.LockEntry{Url: url, Kind: remote.KindHelmChart}, func(resolved string) (string, []byte, error) {
+		if resolved == "" {
+			resolved = url
+		}
+
+		data, err := remote.DownloadUrl(resolved)
+		return resolved, data, err
+	})
+
+	if err != nil {
+		js.Throw(fmt.Errorf("can't load chart from URL %s, %v", url, err))
+	}
+
+	return LoadChart(data)
+}
+
+// Runs helm template with values from the options and parses the generated documents. Install and upgrade hooks
+// are added to the context as separate document groups for each hook weight, which are provisioned before or
+// after the returned document group.
 func GenerateFromChart(chart *chart.Chart, context *BuildContext, options *HelmOptions) *DocumentGroup {
 	options.sanitize()
 
@@ -158,6 +224,11 @@ func GenerateFromChart(chart *chart.Chart, context *BuildContext, options *HelmO
 		slog.String("chart", chart.Metadata.Name),
 		slog.String("version", chart.Metadata.Version))
 
+	defer context.Profiler.StartSpan(
+		ProfileCategoryHelm,
+		fmt.Sprintf("%s (%s)", chart.Metadata.Name, options.ReleaseName),
+		map[string]string{"chart": chart.Metadata.Name, "version": chart.Metadata.Version, "releaseName": options.ReleaseName})()
+
 	kubeVersion := context.BuilderOptions.KubernetesCluster.Version.String()
 
 	if kubeVersion != "" {
@@ -171,6 +242,17 @@ func GenerateFromChart(chart *chart.Chart, context *BuildContext, options *HelmO
 	}
 
 	values := options.getValues(context)
+	resolveHelmDependencies(context.JsRuntime, chart, values)
+
+	// Values are validated against the schemas of the chart and its subcharts here instead of Helm, so that each
+	// violation is reported as a diagnostic with its path. The chart is not rendered with invalid values, and the
+	// diagnostics fail the build after they are reported and written.
+	violations := reportHelmValueDiagnostics(chart, values, context, options)
+	if len(violations) > 0 {
+		return NewDocumentGroup(options.ReleaseName)
+	}
+
+	client.SkipSchemaValidation = true
 
 	helmRelease, err := client.Run(chart, values)
 	if err != nil {
@@ -184,18 +266,6 @@ func GenerateFromChart(chart *chart.Chart, context *BuildContext, options *HelmO
 		documentGroup.AddDocument(document)
 	}
 
-	for _, hook := range helmRelease.Hooks {
-		if slices.Contains(hook.Events, release.HookTest) {
-			continue
-		}
-
-		hookDocuments := HelmManifestToDocuments(context.JsRuntime, hook.Manifest, options.ReleaseName, hook.Path)
-
-		for _, document := range hookDocuments {
-			documentGroup.AddDocument(document)
-		}
-	}
-
 	for _, crd := range chart.CRDObjects() {
 		manifest := bytes.NewBuffer(crd.File.Data).String()
 
@@ -208,9 +278,58 @@ func GenerateFromChart(chart *chart.Chart, context *BuildContext, options *HelmO
 
 	fixNameClashes(documentGroup)
 
+	for _, hookGroup := range createHelmHookDocumentGroups(context, documentGroup, helmRelease.Hooks, options.ReleaseName) {
+		context.AddDocumentGroup(hookGroup)
+	}
+
 	return documentGroup
 }
 
+// Reports the values that don't match the schemas of the chart and its subcharts, and the unknown values
+// if they are enabled in the options. Returns the schema violations.
+func reportHelmValueDiagnostics(chart *chart.Chart, values map[string]any, context *BuildContext, options *HelmOptions) []*helmValuesViolation {
+	violations, err := validateHelmValues(chart, values, "")
+	if err != nil {
+		js.Throw(err)
+	}
+
+	location := options.location
+	if location == nil {
+		location = getScriptCallSite(context.JsRuntime)
+	}
+
+	addDiagnostic := func(metadata *DiagnosticMetadata, violation *helmValuesViolation, message string) {
+		diagnostic := NewDiagnostic(metadata, message)
+		diagnostic.FieldPath = violation.path
+		diagnostic.Location = location
+
+		context.AddDiagnostic(diagnostic)
+	}
+
+	for _, violation := range violations {
+		path := violation.path
+		if path == "" {
+			path = "values"
+		}
+
+		addDiagnostic(
+			InvalidHelmValuesDiagnosticMetadata,
+			violation,
+			fmt.Sprintf("Invalid %s for chart %s, release %s: %s", path, chart.Name(), options.ReleaseName, violation.message))
+	}
+
+	if options.CheckUnknownValues {
+		for _, violation := range findUnknownHelmValues(chart, values, "") {
+			addDiagnostic(
+				UnknownHelmValueDiagnosticMetadata,
+				violation,
+				fmt.Sprintf("Unknown value %s for chart %s, release %s: %s", violation.path, chart.Name(), options.ReleaseName, violation.message))
+		}
+	}
+
+	return violations
+}
+
 func (options *HelmOptions) getValues(context *BuildContext) (values map[string]interface{}) {
 	valuesYaml := ""
 
@@ -290,7 +409,7 @@ func createDocumentFromHelmManifest(jsRuntime *js.JsRuntime, manifest string, re
 		return nil
 	}
 
-	document, err := ParseDocument(jsRuntime, manifest)
+	document, err := parseDocumentWithSource(jsRuntime, manifest, path)
 	if err != nil {
 		js.Throw(err)
 	}
@@ -418,7 +537,14 @@ func jsToHelmOptions(jsRuntime *js.JsRuntime, jsValue sobek.Value) (*HelmOptions
 		Namespace:   namespace,
 	}
 
+	if slices.Contains(propertyNames, "checkUnknownValues") {
+		options.CheckUnknownValues = jsObject.Get("checkUnknownValues").ToBoolean()
+	}
+
 	values := jsObject.Get("values")
+	if values == nil || sobek.IsUndefined(values) || sobek.IsNull(values) {
+		return options, nil
+	}
 
 	valuesStringValue, yamlErr := jsRuntime.MarshalToGo(values, reflect.TypeFor[string]())
 	if yamlErr == nil {
@@ -446,6 +572,22 @@ func registerHelm(jsRuntime *js.JsRuntime) {
 		js.ExtensionMethod(reflect.ValueOf(AddHelmChart)),
 		js.ExtensionMethod(reflect.ValueOf(AddHelmChartObject)).JsName("addHelmChart"),
 		js.ExtensionMethod(reflect.ValueOf(AddHelmChartNoValues)).JsName("addHelmChart"),
+		js.ExtensionMethod(reflect.ValueOf(AddHelmChartFromReference)).JsName("addHelmChart"),
+		js.ExtensionMethod(reflect.ValueOf(AddHelmChartFromReferenceObject)).JsName("addHelmChart"),
+		js.ExtensionMethod(reflect.ValueOf(AddHelmChartFromReferenceNoValues)).JsName("addHelmChart"),
+		js.ExtensionMethod(reflect.ValueOf(AddHelmChartWithOptions)).JsName("addHelmChart"),
+		js.ExtensionMethod(reflect.ValueOf(AddHelmChartFromReferenceWithOptions)).JsName("addHelmChart"),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[HelmChartReference]()).JsModule(
+		"helm",
+	).Fields(
+		js.Field("RepoUrl"),
+		js.Field("Chart"),
+		js.Field("Version"),
+		js.Field("PlainHttp"),
+	).Constructors(
+		js.Constructor(reflect.ValueOf(NewHelmChartReference)),
 	)
 
 	jsRuntime.Type(reflect.TypeFor[chart.Chart]()).JsModule(
@@ -466,6 +608,7 @@ func registerHelm(jsRuntime *js.JsRuntime) {
 		js.Field("Namespace"),
 		js.Field("ValuesString").JsName("values"),
 		js.Field("ValuesObject").JsName("values"),
+		js.Field("CheckUnknownValues"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewHelmOptions)),
 		js.Constructor(reflect.ValueOf(NewHelmOptionsWithValues)),
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const helmLocalRepositoryPrefix = "file://"
+
+// Loads the dependencies with file:// repositories that are not in the charts directory of the chart, same as
+// "helm dependency build" does. Local dependencies are always loaded, Helm decides whether they are rendered
+// using their conditions and tags.
+func addLocalHelmDependencies(helmChart *chart.Chart, path string) {
+	if helmChart.Metadata == nil {
+		return
+	}
+
+	for _, dependency := range helmChart.Metadata.Dependencies {
+		if !strings.HasPrefix(dependency.Repository, helmLocalRepositoryPrefix) || hasHelmDependency(helmChart, dependency) {
+			continue
+		}
+
+		dependencyPath := strings.TrimPrefix(dependency.Repository, helmLocalRepositoryPrefix)
+		if !filepath.IsAbs(dependencyPath) {
+			dependencyPath = filepath.Join(path, dependencyPath)
+		}
+
+		slog.Debug(
+			"Loading dependency ${dependency} of chart ${chart} from ${path}",
+			slog.String("dependency", dependency.Name),
+			slog.String("chart", helmChart.Name()),
+			slog.String("path", dependencyPath))
+
+		helmChart.AddDependency(LoadChartFromPath(dependencyPath))
+	}
+}
+
+// Downloads the dependencies that are not in the charts directory of the chart and its subcharts, same as
+// "helm dependency build" does. Versions in the Chart.lock are used if they match the Chart.yaml. Dependencies
+// that are disabled by their conditions or tags are not downloaded. Downloaded charts are cached and recorded
+// in the lockfile. Import values are handled by Helm when the chart is rendered.
+func resolveHelmDependencies(jsRuntime *js.JsRuntime, helmChart *chart.Chart, values map[string]any) {
+	if helmChart.Metadata == nil || len(helmChart.Metadata.Dependencies) == 0 {
+		return
+	}
+
+	coalesced, err := chartutil.CoalesceValues(helmChart, values)
+	if err != nil {
+		js.Throw(fmt.Errorf("can't coalesce values of chart %s, %v", helmChart.Name(), err))
+	}
+
+	for _, dependency := range helmChart.Metadata.Dependencies {
+		if !hasHelmDependency(helmChart, dependency) {
+			if !isHelmDependencyEnabled(dependency, coalesced) {
+				slog.Debug(
+					"Skipping disabled dependency ${dependency} of chart ${chart}",
+					slog.String("dependency", dependency.Name),
+					slog.String("chart", helmChart.Name()))
+
+				continue
+			}
+
+			helmChart.AddDependency(downloadHelmDependency(jsRuntime, helmChart, dependency))
+		}
+	}
+
+	for _, subchart := range getEnabledSubcharts(helmChart, coalesced) {
+		subchartValues, _ := coalesced[subchart.key].(map[string]any)
+		resolveHelmDependencies(jsRuntime, subchart.chart, subchartValues)
+	}
+}
+
+func downloadHelmDependency(jsRuntime *js.JsRuntime, helmChart *chart.Chart, dependency *chart.Dependency) *chart.Chart {
+	repository := dependency.Repository
+	version := getLockedHelmDependencyVersion(helmChart, dependency)
+
+	var reference *HelmChartReference
+
+	switch {
+	case repository == "":
+		js.Throw(fmt.Errorf(
+			"dependency %s of chart %s is not in the charts directory and doesn't have a repository",
+			dependency.Name, helmChart.Name()))
+	case strings.HasPrefix(repository, helmLocalRepositoryPrefix):
+		js.Throw(fmt.Errorf(
+			"dependency %s of chart %s is not in the charts directory, local dependencies can only be loaded if the chart is loaded from a directory",
+			dependency.Name, helmChart.Name()))
+	case strings.HasPrefix(repository, "@") || strings.HasPrefix(repository, "alias:"):
+		// Repositories that are added with "helm repo add".
+		name := strings.TrimPrefix(strings.TrimPrefix(repository, "@"), "alias:")
+		reference = NewHelmChartReference("", fmt.Sprintf("%s/%s", name, dependency.Name), version)
+	default:
+		reference = NewHelmChartReference(repository, dependency.Name, version)
+	}
+
+	slog.Info(
+		"Downloading dependency ${dependency} of chart ${chart}",
+		slog.String("dependency", reference.String()),
+		slog.String("chart", helmChart.Name()))
+
+	return LoadChartFromReference(jsRuntime, reference)
+}
+
+// Returns the version of the dependency in the Chart.lock if it matches the version range in the Chart.yaml,
+// otherwise returns the version range.
+func getLockedHelmDependencyVersion(helmChart *chart.Chart, dependency *chart.Dependency) string {
+	if helmChart.Lock == nil {
+		return dependency.Version
+	}
+
+	for _, locked := range helmChart.Lock.Dependencies {
+		if locked.Name != dependency.Name || locked.Repository != dependency.Repository {
+			continue
+		}
+
+		if dependency.Version == "" || chartutil.IsCompatibleRange(dependency.Version, locked.Version) {
+			return locked.Version
+		}
+
+		slog.Warn(
+			"Chart.lock of chart ${chart} is out of sync with Chart.yaml, using version ${version} of dependency ${dependency}",
+			slog.String("chart", helmChart.Name()),
+			slog.String("version", dependency.Version),
+			slog.String("dependency", dependency.Name))
+	}
+
+	return dependency.Version
+}
+
+func hasHelmDependency(helmChart *chart.Chart, dependency *chart.Dependency) bool {
+	for _, subchart := range helmChart.Dependencies() {
+		if subchart.Name() == dependency.Name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestLoadLocalHelmDependencies(t *testing.T) {
+	directory := t.TempDir()
+
+	database := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "database", Version: "1.0.0"},
+		Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("connection:\n  port: 5432\n")}},
+	}
+
+	app := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "app",
+			Version:    "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{
+					Name:         "database",
+					Version:      "1.x",
+					Repository:   "file://../database",
+					Condition:    "database.enabled",
+					ImportValues: []any{map[string]any{"child": "connection", "parent": "databaseConnection"}},
+				},
+			},
+		},
+		Raw: []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("database:\n  enabled: true\n")}},
+	}
+
+	for _, helmChart := range []*chart.Chart{database, app} {
+		if err := chartutil.SaveDir(helmChart, directory); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := LoadChartFromPath(filepath.Join(directory, "app"))
+
+	if len(loaded.Dependencies()) != 1 || loaded.Dependencies()[0].Name() != "database" {
+		t.Fatalf("expected the local dependency to be loaded, got %v", loaded.Dependencies())
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(loaded, map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+
+	connection, _ := loaded.Values["databaseConnection"].(map[string]any)
+	if port := fmt.Sprint(connection["port"]); port != "5432" {
+		t.Errorf("expected databaseConnection to be imported from the dependency, got %v", loaded.Values)
+	}
+}
+
+func TestResolveHelmDependenciesFromRepository(t *testing.T) {
+	useTemporaryHelmHome(t)
+
+	server := newTestHelmRepository(t, "1.2.0", "1.3.0")
+	defer server.Close()
+
+	app := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "app",
+			Version:    "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "test", Version: "^1.0.0", Repository: server.URL},
+				{Name: "missing", Version: "1.0.0", Repository: server.URL, Condition: "missing.enabled"},
+			},
+		},
+		Lock: &chart.Lock{
+			Dependencies: []*chart.Dependency{
+				{Name: "test", Version: "1.2.0", Repository: server.URL},
+			},
+		},
+		Values: map[string]any{"missing": map[string]any{"enabled": true}},
+	}
+
+	// Disabled dependency is not downloaded, it doesn't exist in the repository.
+	resolveHelmDependencies(js.NewJsRuntime(), app, map[string]any{"missing": map[string]any{"enabled": false}})
+
+	if len(app.Dependencies()) != 1 {
+		t.Fatalf("expected only the enabled dependency to be downloaded, got %d dependencies", len(app.Dependencies()))
+	}
+
+	if version := app.Dependencies()[0].Metadata.Version; version != "1.2.0" {
+		t.Errorf("expected the version in Chart.lock, got %s", version)
+	}
+}
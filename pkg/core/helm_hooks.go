@@ -0,0 +1,109 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+type helmHookPhase string
+
+const (
+	helmHookPhasePre  helmHookPhase = "pre"
+	helmHookPhasePost helmHookPhase = "post"
+)
+
+// Returns the phase in which the hook runs when the chart is applied. Applying doesn't distinguish between
+// installing and upgrading a release, so both install and upgrade hooks run on each apply. Returns an empty
+// phase for the hooks that don't run on apply, i.e. delete, rollback and test hooks.
+func getHelmHookPhase(hook *release.Hook) helmHookPhase {
+	if slices.Contains(hook.Events, release.HookPreInstall) || slices.Contains(hook.Events, release.HookPreUpgrade) {
+		return helmHookPhasePre
+	}
+
+	if slices.Contains(hook.Events, release.HookPostInstall) || slices.Contains(hook.Events, release.HookPostUpgrade) {
+		return helmHookPhasePost
+	}
+
+	return ""
+}
+
+// Returns the path of the document group that contains the hooks with the given phase and weight,
+// e.g. "release-pre-hooks-0" or "release-post-hooks-minus-5".
+func getHelmHookDocumentGroupPath(releaseName string, phase helmHookPhase, weight int) string {
+	weightString := fmt.Sprintf("%d", weight)
+	if weight < 0 {
+		weightString = fmt.Sprintf("minus-%d", -weight)
+	}
+
+	return fmt.Sprintf("%s-%s-hooks-%s", releaseName, phase, weightString)
+}
+
+// Creates a document group for each hook phase and weight, same as Helm runs the hooks with the same weight
+// together. Pre hook groups are provisioned before the main document group and post hook groups are
+// provisioned after it, each group waits for the groups with lower weights in the same phase.
+func createHelmHookDocumentGroups(
+	context *BuildContext,
+	mainGroup *DocumentGroup,
+	hooks []*release.Hook,
+	releaseName string,
+) []*DocumentGroup {
+	groups := map[helmHookPhase]map[int]*DocumentGroup{
+		helmHookPhasePre:  {},
+		helmHookPhasePost: {},
+	}
+
+	for _, hook := range hooks {
+		phase := getHelmHookPhase(hook)
+		if phase == "" {
+			slog.Debug(
+				"Skipping Helm hook ${path} with events ${events}, only install and upgrade hooks are applied",
+				slog.String("path", hook.Path),
+				slog.Any("events", hook.Events))
+
+			continue
+		}
+
+		group := groups[phase][hook.Weight]
+		if group == nil {
+			group = NewDocumentGroup(getHelmHookDocumentGroupPath(releaseName, phase, hook.Weight))
+			groups[phase][hook.Weight] = group
+		}
+
+		group.AddDocuments(HelmManifestToDocuments(context.JsRuntime, hook.Manifest, releaseName, hook.Path))
+	}
+
+	result := []*DocumentGroup{}
+
+	// Provisioning order is pre hooks, main group and post hooks, each phase in the ascending order of weights.
+	for _, phase := range []helmHookPhase{helmHookPhasePre, helmHookPhasePost} {
+		weights := slices.Sorted(maps.Keys(groups[phase]))
+
+		var previous *DocumentGroup
+		if phase == helmHookPhasePost {
+			previous = mainGroup
+		}
+
+		for _, weight := range weights {
+			group := groups[phase][weight]
+
+			if previous != nil {
+				group.ProvisionAfter(previous)
+			}
+
+			fixNameClashes(group)
+
+			result = append(result, group)
+			previous = group
+		}
+
+		if phase == helmHookPhasePre && previous != nil {
+			mainGroup.ProvisionAfter(previous)
+		}
+	}
+
+	return result
+}
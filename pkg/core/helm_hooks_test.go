@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func newTestHook(name string, weight int, events ...release.HookEvent) *release.Hook {
+	return &release.Hook{
+		Name:   name,
+		Path:   fmt.Sprintf("app/templates/%s.yaml", name),
+		Weight: weight,
+		Events: events,
+		Manifest: fmt.Sprintf(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: %s
+  annotations:
+    helm.sh/hook: %s
+`, name, events[0]),
+	}
+}
+
+func TestCreateHelmHookDocumentGroups(t *testing.T) {
+	context := &BuildContext{JsRuntime: js.NewJsRuntime()}
+	mainGroup := NewDocumentGroup("app")
+
+	hooks := []*release.Hook{
+		newTestHook("migrate", 0, release.HookPreInstall, release.HookPreUpgrade),
+		newTestHook("create-database", -5, release.HookPreInstall),
+		newTestHook("seed", 0, release.HookPostInstall),
+		newTestHook("cleanup", 0, release.HookPreDelete),
+		newTestHook("test-connection", 0, release.HookTest),
+	}
+
+	groups := createHelmHookDocumentGroups(context, mainGroup, hooks, "app")
+
+	paths := []string{}
+	for _, group := range groups {
+		paths = append(paths, group.Path)
+	}
+
+	expected := []string{"app-pre-hooks-minus-5", "app-pre-hooks-0", "app-post-hooks-0"}
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("expected document groups %v, got %v", expected, paths)
+	}
+
+	assertProvisionedAfter := func(group *DocumentGroup, other *DocumentGroup) {
+		t.Helper()
+
+		if !slices.Contains(group.ApplyProvisioner.Dependencies.Prerequisites, other.WaitProvisioner) {
+			t.Errorf("expected %s to be provisioned after %s", group.Path, other.Path)
+		}
+	}
+
+	assertProvisionedAfter(groups[1], groups[0])
+	assertProvisionedAfter(mainGroup, groups[1])
+	assertProvisionedAfter(groups[2], mainGroup)
+
+	if len(groups[1].Documents) != 1 || groups[1].Documents[0].GetPath() != "migrate.yaml" {
+		t.Errorf("expected the migrate hook in %s", groups[1].Path)
+	}
+}
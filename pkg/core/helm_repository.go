@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/remote"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// Identifies a chart that is stored in a classic Helm repository or in an OCI registry.
+type HelmChartReference struct {
+	// URL of the Helm repository, e.g. https://charts.example.com. Leave empty for OCI references and for
+	// the charts in the repositories that are added with "helm repo add".
+	RepoUrl string
+
+	// Name of the chart in the repository given by RepoUrl, a "repo/chart" reference for the repositories
+	// that are added with "helm repo add", or an OCI reference such as oci://registry.example.com/charts/chart.
+	Chart string
+
+	// Exact version or a semver range such as ^1.2.0. Latest version is used if it is empty.
+	Version string
+
+	// Connects to the OCI registry using plain HTTP instead of HTTPS, e.g. for local registries.
+	PlainHttp bool
+}
+
+func NewHelmChartReference(repoUrl string, chart string, version string) *HelmChartReference {
+	return &HelmChartReference{
+		RepoUrl: repoUrl,
+		Chart:   chart,
+		Version: version,
+	}
+}
+
+func (reference *HelmChartReference) String() string {
+	chart := reference.Chart
+	if reference.RepoUrl != "" {
+		chart = fmt.Sprintf("%s/%s", strings.TrimSuffix(reference.RepoUrl, "/"), reference.Chart)
+	}
+
+	if reference.Version == "" {
+		return chart
+	}
+
+	return fmt.Sprintf("%s@%s", chart, reference.Version)
+}
+
+// Resolves the reference to the URL of a specific chart version, following the same rules as the Helm
+// CLI. Repositories and registry credentials are read from the Helm configuration, falling back to the
+// Docker configuration for the registry credentials. HELM_* environment variables can be used to override
+// the locations.
+func (reference *HelmChartReference) Resolve() (string, error) {
+	if reference.Chart == "" {
+		return "", fmt.Errorf("chart name is not defined")
+	}
+
+	chartDownloader, err := reference.newChartDownloader()
+	if err != nil {
+		return "", err
+	}
+
+	chartReference := reference.Chart
+
+	switch {
+	case registry.IsOCI(reference.RepoUrl):
+		chartReference = fmt.Sprintf("%s/%s", strings.TrimSuffix(reference.RepoUrl, "/"), reference.Chart)
+	case reference.RepoUrl != "":
+		return repo.FindChartInRepoURL(reference.RepoUrl, reference.Chart, reference.Version, "", "", "", chartDownloader.Getters)
+	}
+
+	url, err := chartDownloader.ResolveChartVersion(chartReference, reference.Version)
+	if err != nil {
+		return "", err
+	}
+
+	return url.String(), nil
+}
+
+// Downloads the chart archive from the given URL that is returned by Resolve.
+func (reference *HelmChartReference) Download(resolved string) ([]byte, error) {
+	chartDownloader, err := reference.newChartDownloader()
+	if err != nil {
+		return nil, err
+	}
+
+	directory, err := os.MkdirTemp("", "anemos-helm-")
+	if err != nil {
+		return nil, fmt.Errorf("can't create temporary directory, %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	path, _, err := chartDownloader.DownloadTo(resolved, "", directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+func (reference *HelmChartReference) newChartDownloader() (*downloader.ChartDownloader, error) {
+	settings := cli.New()
+
+	registryOptions := []registry.ClientOption{
+		registry.ClientOptDebug(settings.Debug),
+		registry.ClientOptEnableCache(true),
+		registry.ClientOptWriter(io.Discard),
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+	}
+
+	if reference.PlainHttp {
+		registryOptions = append(registryOptions, registry.ClientOptPlainHTTP())
+	}
+
+	registryClient, err := registry.NewClient(registryOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("can't create registry client, %v", err)
+	}
+
+	return &downloader.ChartDownloader{
+		Out:     io.Discard,
+		Verify:  downloader.VerifyNever,
+		Getters: getter.All(settings),
+		Options: []getter.Option{
+			getter.WithPlainHTTP(reference.PlainHttp),
+			getter.WithRegistryClient(registryClient),
+		},
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}, nil
+}
+
+// Downloads the chart referenced by the given reference and loads it into memory. The chart is
+// read from the cache and recorded in the lockfile if the runtime has a fetcher.
+func LoadChartFromReference(jsRuntime *js.JsRuntime, reference *HelmChartReference) *chart.Chart {
+	resource := &remote.LockEntry{
+		Url:       reference.String(),
+		Kind:      remote.KindHelmChart,
+		PlainHttp: reference.PlainHttp,
+	}
+
+	data, err := js.FetchRemote(jsRuntime, resource, func(resolved string) (string, []byte, error) {
+		if resolved == "" {
+			var err error
+			if resolved, err = reference.Resolve(); err != nil {
+				return "", nil, err
+			}
+		}
+
+		data, err := reference.Download(resolved)
+		return resolved, data, err
+	})
+
+	if err != nil {
+		js.Throw(fmt.Errorf("can't download chart %s, %v", reference, err))
+	}
+
+	return LoadChart(data)
+}
+
+// Downloads the chart in the given lockfile entry from its resolved URL.
+func DownloadLockedChart(entry *remote.LockEntry) ([]byte, error) {
+	reference := &HelmChartReference{PlainHttp: entry.PlainHttp}
+	return reference.Download(entry.Resolved)
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestResolveChartInHelmRepository(t *testing.T) {
+	useTemporaryHelmHome(t)
+
+	server := newTestHelmRepository(t, "1.2.0", "1.2.3", "1.3.0")
+	defer server.Close()
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: "", expected: "1.3.0"},
+		{version: "1.2.0", expected: "1.2.0"},
+		{version: "~1.2", expected: "1.2.3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			assertResolvedChartVersion(t, NewHelmChartReference(server.URL, "test", test.version), test.expected)
+		})
+	}
+}
+
+func TestResolveChartInOciRegistry(t *testing.T) {
+	useTemporaryHelmHome(t)
+
+	directory := t.TempDir()
+	testRegistry := newTestRegistry()
+
+	for _, version := range []string{"1.2.0", "1.2.3", "1.3.0"} {
+		data, err := os.ReadFile(saveTestChart(t, directory, version))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testRegistry.push(t, "charts/test", version, data)
+	}
+
+	server := httptest.NewServer(testRegistry)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name      string
+		reference *HelmChartReference
+		expected  string
+	}{
+		{
+			name:      "tag in reference",
+			reference: &HelmChartReference{Chart: fmt.Sprintf("oci://%s/charts/test:1.2.0", host), PlainHttp: true},
+			expected:  "1.2.0",
+		},
+		{
+			name:      "version range",
+			reference: &HelmChartReference{Chart: fmt.Sprintf("oci://%s/charts/test", host), Version: "~1.2", PlainHttp: true},
+			expected:  "1.2.3",
+		},
+		{
+			name:      "registry as repository URL",
+			reference: &HelmChartReference{RepoUrl: fmt.Sprintf("oci://%s/charts", host), Chart: "test", PlainHttp: true},
+			expected:  "1.3.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertResolvedChartVersion(t, test.reference, test.expected)
+		})
+	}
+}
+
+func assertResolvedChartVersion(t *testing.T, reference *HelmChartReference, expected string) {
+	resolved, err := reference.Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := reference.Download(resolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chart, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chart.Metadata.Version != expected {
+		t.Errorf("expected version %s, got %s", expected, chart.Metadata.Version)
+	}
+}
+
+// Points all Helm and Docker configuration to empty directories so that the tests don't use
+// the configuration of the user.
+func useTemporaryHelmHome(t *testing.T) {
+	home := t.TempDir()
+
+	t.Setenv("HELM_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv("HELM_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("HELM_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("HELM_REPOSITORY_CACHE", filepath.Join(home, "cache", "repository"))
+	t.Setenv("HELM_REPOSITORY_CONFIG", filepath.Join(home, "config", "repositories.yaml"))
+	t.Setenv("HELM_REGISTRY_CONFIG", filepath.Join(home, "config", "registry", "config.json"))
+	t.Setenv("DOCKER_CONFIG", filepath.Join(home, "docker"))
+}
+
+// Serves a classic Helm repository that contains the test chart with the given versions.
+func newTestHelmRepository(t *testing.T, versions ...string) *httptest.Server {
+	directory := t.TempDir()
+	for _, version := range versions {
+		saveTestChart(t, directory, version)
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(directory)))
+
+	index, err := repo.IndexDirectory(directory, server.URL)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	if err := index.WriteFile(filepath.Join(directory, "index.yaml"), 0644); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	return server
+}
+
+func saveTestChart(t *testing.T, directory string, version string) string {
+	testChart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "test",
+			Version:    version,
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n"),
+			},
+		},
+	}
+
+	path, err := chartutil.Save(testChart, directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// Minimal stand-in for an OCI registry that serves the parts of the distribution API used by Helm.
+type testRegistry struct {
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	tags      map[string][]string
+}
+
+var testRegistryPath = regexp.MustCompile(`^/v2/(.+)/(manifests|blobs|tags)/(.+)$`)
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+		tags:      map[string][]string{},
+	}
+}
+
+func (r *testRegistry) addBlob(mediaType string, data []byte) map[string]any {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	r.blobs[digest] = data
+
+	return map[string]any{
+		"mediaType": mediaType,
+		"digest":    digest,
+		"size":      len(data),
+	}
+}
+
+func (r *testRegistry) push(t *testing.T, repository string, version string, chartData []byte) {
+	config, err := json.Marshal(map[string]string{"name": "test", "version": version, "apiVersion": chart.APIVersionV2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        r.addBlob(registry.ConfigMediaType, config),
+		"layers":        []any{r.addBlob(registry.ChartLayerMediaType, chartData)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	r.manifests[repository+":"+version] = manifest
+	r.manifests[repository+"@"+digest] = manifest
+	r.tags[repository] = append(r.tags[repository], version)
+}
+
+func (r *testRegistry) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/v2/" {
+		return
+	}
+
+	match := testRegistryPath.FindStringSubmatch(request.URL.Path)
+	if match == nil {
+		http.NotFound(writer, request)
+		return
+	}
+
+	repository, kind, reference := match[1], match[2], match[3]
+
+	var data []byte
+	mediaType := "application/octet-stream"
+
+	switch kind {
+	case "tags":
+		data, _ = json.Marshal(map[string]any{"name": repository, "tags": r.tags[repository]})
+		mediaType = "application/json"
+	case "blobs":
+		data = r.blobs[reference]
+	case "manifests":
+		separator := ":"
+		if strings.HasPrefix(reference, "sha256:") {
+			separator = "@"
+		}
+
+		data = r.manifests[repository+separator+reference]
+		mediaType = "application/vnd.oci.image.manifest.v1+json"
+	}
+
+	if data == nil {
+		http.NotFound(writer, request)
+		return
+	}
+
+	writer.Header().Set("Content-Type", mediaType)
+	writer.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	writer.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(data)))
+
+	if request.Method == http.MethodHead {
+		return
+	}
+
+	writer.Write(data)
+}
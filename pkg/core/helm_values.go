@@ -0,0 +1,296 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Charts are not rendered with invalid values, so these diagnostics always fail the build like Helm does.
+var InvalidHelmValuesDiagnosticMetadata = &DiagnosticMetadata{
+	Id:          "invalid-helm-values",
+	Name:        "Invalid Helm values",
+	Description: "Helm values don't match the values.schema.json of the chart or one of its subcharts.",
+	Severity:    DiagnosticSeverityError,
+	Categories:  []DiagnosticCategory{DiagnosticCategorySpecs},
+	FailsBuild:  true,
+}
+
+var UnknownHelmValueDiagnosticMetadata = NewDiagnosticMetadata(
+	"unknown-helm-value",
+	"Unknown Helm value",
+	"Helm values contain a key that doesn't exist in the values.yaml or the values.schema.json of the chart, which is usually a typo.",
+	DiagnosticSeverityWarning,
+	[]DiagnosticCategory{DiagnosticCategoryLinting})
+
+type helmValuesViolation struct {
+	// Path of the value, e.g. "image.tag" or "postgresql.auth.password" for a subchart.
+	path    string
+	message string
+}
+
+type helmSubchart struct {
+	key   string
+	chart *chart.Chart
+}
+
+// Validates the values against the values.schema.json files of the chart and its enabled subcharts.
+// Values are coalesced with the defaults of the charts before the validation, same as Helm does.
+func validateHelmValues(helmChart *chart.Chart, values map[string]any, path string) ([]*helmValuesViolation, error) {
+	coalesced, err := chartutil.CoalesceValues(helmChart, values)
+	if err != nil {
+		return nil, fmt.Errorf("can't coalesce values of chart %s, %v", helmChart.Name(), err)
+	}
+
+	violations := []*helmValuesViolation{}
+
+	if helmChart.Schema != nil {
+		schemaViolations, err := validateAgainstHelmSchema(helmChart.Schema, coalesced, path)
+		if err != nil {
+			return nil, fmt.Errorf("can't validate values of chart %s, %v", helmChart.Name(), err)
+		}
+
+		violations = append(violations, schemaViolations...)
+	}
+
+	for _, subchart := range getEnabledSubcharts(helmChart, coalesced) {
+		subchartValues, _ := coalesced[subchart.key].(map[string]any)
+
+		subchartViolations, err := validateHelmValues(subchart.chart, subchartValues, joinValuesPath(path, subchart.key))
+		if err != nil {
+			return nil, err
+		}
+
+		violations = append(violations, subchartViolations...)
+	}
+
+	return violations, nil
+}
+
+func validateAgainstHelmSchema(schema []byte, values map[string]any, path string) (violations []*helmValuesViolation, err error) {
+	// Schema library panics on some invalid schemas.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid schema, %v", r)
+		}
+	}()
+
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	valuesJson, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(valuesJson))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resultError := range result.Errors() {
+		field := resultError.Field()
+		if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = ""
+		}
+
+		// Point to the missing property instead of its parent.
+		if property, ok := resultError.Details()["property"].(string); ok && resultError.Type() == "required" {
+			field = joinValuesPath(field, property)
+		}
+
+		violations = append(violations, &helmValuesViolation{
+			path:    joinValuesPath(path, field),
+			message: resultError.Description(),
+		})
+	}
+
+	return violations, nil
+}
+
+// Returns the keys in the given values that don't exist in the default values or in the schema of the chart.
+// Maps that are empty in the default values, e.g. "podAnnotations: {}", accept any key.
+func findUnknownHelmValues(helmChart *chart.Chart, values map[string]any, path string) []*helmValuesViolation {
+	subcharts := map[string]*chart.Chart{}
+	for _, subchart := range getSubcharts(helmChart) {
+		subcharts[subchart.key] = subchart.chart
+	}
+
+	schema := map[string]any{}
+	if helmChart.Schema != nil {
+		// Invalid schemas are reported by the schema validation.
+		_ = json.Unmarshal(helmChart.Schema, &schema)
+	}
+
+	violations := []*helmValuesViolation{}
+
+	for key, value := range values {
+		if key == "global" {
+			continue
+		}
+
+		if subchart, ok := subcharts[key]; ok {
+			if subchartValues, ok := value.(map[string]any); ok {
+				violations = append(violations, findUnknownHelmValues(subchart, subchartValues, joinValuesPath(path, key))...)
+			}
+
+			continue
+		}
+
+		violations = append(violations, findUnknownValues(helmChart.Values, schema, key, value, path)...)
+	}
+
+	return sortHelmValuesViolations(violations)
+}
+
+func findUnknownValues(defaults map[string]any, schema map[string]any, key string, value any, path string) []*helmValuesViolation {
+	defaultValue, inDefaults := defaults[key]
+	propertySchema, inSchema := getSchemaProperty(schema, key)
+	path = joinValuesPath(path, key)
+
+	if !inDefaults && !inSchema {
+		return []*helmValuesViolation{
+			{
+				path:    path,
+				message: "it doesn't exist in the values.yaml or the values.schema.json of the chart",
+			},
+		}
+	}
+
+	values, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	defaultValues, _ := defaultValue.(map[string]any)
+
+	// Empty or non-map defaults accept any keys unless the schema lists the properties.
+	if len(defaultValues) == 0 && propertySchema["properties"] == nil {
+		return nil
+	}
+
+	violations := []*helmValuesViolation{}
+	for childKey, childValue := range values {
+		violations = append(violations, findUnknownValues(defaultValues, propertySchema, childKey, childValue, path)...)
+	}
+
+	return violations
+}
+
+func getSchemaProperty(schema map[string]any, key string) (map[string]any, bool) {
+	properties, _ := schema["properties"].(map[string]any)
+	property, ok := properties[key].(map[string]any)
+
+	return property, ok
+}
+
+// Returns the subcharts with the keys of their values, i.e. their aliases or names.
+func getSubcharts(helmChart *chart.Chart) []*helmSubchart {
+	subcharts := []*helmSubchart{}
+	dependencyCharts := map[string]*chart.Chart{}
+
+	for _, dependency := range helmChart.Dependencies() {
+		dependencyCharts[dependency.Name()] = dependency
+	}
+
+	listed := map[string]bool{}
+
+	if helmChart.Metadata != nil {
+		for _, dependency := range helmChart.Metadata.Dependencies {
+			dependencyChart := dependencyCharts[dependency.Name]
+			if dependencyChart == nil {
+				continue
+			}
+
+			key := dependency.Name
+			if dependency.Alias != "" {
+				key = dependency.Alias
+			}
+
+			listed[dependency.Name] = true
+			subcharts = append(subcharts, &helmSubchart{key: key, chart: dependencyChart})
+		}
+	}
+
+	// Charts in the charts directory that are not listed in Chart.yaml are always enabled.
+	for _, dependency := range helmChart.Dependencies() {
+		if !listed[dependency.Name()] {
+			subcharts = append(subcharts, &helmSubchart{key: dependency.Name(), chart: dependency})
+		}
+	}
+
+	return subcharts
+}
+
+// Returns the subcharts that are enabled by their conditions and tags using the same rules as Helm.
+func getEnabledSubcharts(helmChart *chart.Chart, values chartutil.Values) []*helmSubchart {
+	dependencies := map[string]*chart.Dependency{}
+	if helmChart.Metadata != nil {
+		for _, dependency := range helmChart.Metadata.Dependencies {
+			key := dependency.Name
+			if dependency.Alias != "" {
+				key = dependency.Alias
+			}
+
+			dependencies[key] = dependency
+		}
+	}
+
+	return slices.DeleteFunc(getSubcharts(helmChart), func(subchart *helmSubchart) bool {
+		dependency := dependencies[subchart.key]
+		return dependency != nil && !isHelmDependencyEnabled(dependency, values)
+	})
+}
+
+func isHelmDependencyEnabled(dependency *chart.Dependency, values chartutil.Values) bool {
+	// First condition that resolves to a boolean wins.
+	for _, condition := range strings.Split(dependency.Condition, ",") {
+		condition = strings.TrimSpace(condition)
+		if condition == "" {
+			continue
+		}
+
+		value, err := values.PathValue(condition)
+		if enabled, ok := value.(bool); err == nil && ok {
+			return enabled
+		}
+	}
+
+	tags, _ := values["tags"].(map[string]any)
+	hasTrue, hasFalse := false, false
+
+	for _, tag := range dependency.Tags {
+		if enabled, ok := tags[tag].(bool); ok {
+			hasTrue = hasTrue || enabled
+			hasFalse = hasFalse || !enabled
+		}
+	}
+
+	return hasTrue || !hasFalse
+}
+
+func joinValuesPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	if key == "" {
+		return path
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func sortHelmValuesViolations(violations []*helmValuesViolation) []*helmValuesViolation {
+	slices.SortFunc(violations, func(x, y *helmValuesViolation) int {
+		return strings.Compare(x.path, y.path)
+	})
+
+	return violations
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testValuesSchema = `{
+  "type": "object",
+  "required": ["image"],
+  "properties": {
+    "image": {
+      "type": "object",
+      "required": ["repository"],
+      "properties": {
+        "repository": { "type": "string" },
+        "tag": { "type": "string" }
+      }
+    },
+    "replicas": { "type": "integer", "minimum": 1 },
+    "extraArgs": { "type": "array" }
+  }
+}`
+
+const testSubchartValuesSchema = `{
+  "type": "object",
+  "properties": {
+    "port": { "type": "integer" }
+  }
+}`
+
+func newTestValuesChart() *chart.Chart {
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "database", Version: "1.0.0"},
+		Values:   map[string]any{"port": 5432},
+		Schema:   []byte(testSubchartValuesSchema),
+	}
+
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:    "app",
+			Version: "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "database", Alias: "db", Condition: "db.enabled"},
+			},
+		},
+		Values: map[string]any{
+			"image":          map[string]any{"repository": "app", "tag": "1.0"},
+			"replicas":       1,
+			"podAnnotations": map[string]any{},
+			"db":             map[string]any{"enabled": true},
+		},
+		Schema: []byte(testValuesSchema),
+	}
+
+	parent.AddDependency(subchart)
+
+	return parent
+}
+
+func TestValidateHelmValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]any
+		expected []string
+	}{
+		{
+			name:     "valid values",
+			values:   map[string]any{"replicas": 3},
+			expected: []string{},
+		},
+		{
+			name: "invalid values",
+			values: map[string]any{
+				"image":    map[string]any{"tag": 2},
+				"replicas": 0,
+			},
+			expected: []string{"image.tag", "replicas"},
+		},
+		{
+			name:     "subchart values",
+			values:   map[string]any{"db": map[string]any{"port": "5432"}},
+			expected: []string{"db.port"},
+		},
+		{
+			name:     "disabled subchart",
+			values:   map[string]any{"db": map[string]any{"enabled": false, "port": "5432"}},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := validateHelmValues(newTestValuesChart(), test.values, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertViolationPaths(t, sortHelmValuesViolations(violations), test.expected)
+		})
+	}
+}
+
+func TestValidateHelmValuesRequired(t *testing.T) {
+	helmChart := newTestValuesChart()
+	helmChart.Values = map[string]any{}
+
+	violations, err := validateHelmValues(helmChart, map[string]any{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertViolationPaths(t, violations, []string{"image"})
+}
+
+func TestFindUnknownHelmValues(t *testing.T) {
+	values := map[string]any{
+		"image":          map[string]any{"repository": "app", "tga": "1.0"},
+		"replica":        3,
+		"extraArgs":      []any{"--verbose"},
+		"podAnnotations": map[string]any{"example.com/any": "value"},
+		"global":         map[string]any{"anything": true},
+		"db":             map[string]any{"prot": 5432},
+	}
+
+	violations := findUnknownHelmValues(newTestValuesChart(), values, "")
+
+	assertViolationPaths(t, violations, []string{"db.prot", "image.tga", "replica"})
+}
+
+func assertViolationPaths(t *testing.T, violations []*helmValuesViolation, expected []string) {
+	t.Helper()
+
+	if len(violations) != len(expected) {
+		t.Fatalf("expected violations at %v, got %d violations", expected, len(violations))
+	}
+
+	for i, violation := range violations {
+		if violation.path != expected[i] {
+			t.Errorf("expected violation at %s, got %s: %s", expected[i], violation.path, violation.message)
+		}
+	}
+}
+
+func TestGenerateFromChartSkipsInvalidValues(t *testing.T) {
+	builder := NewEmptyBuilder(nil, js.NewJsRuntime())
+
+	var documentGroup *DocumentGroup
+	var diagnostics []*Diagnostic
+
+	builder.OnStep(StepGenerateResources, func(context *BuildContext) {
+		// Chart is valid otherwise, Helm would render it without the schema validation.
+		helmChart := newTestValuesChart()
+		helmChart.Metadata.APIVersion = chart.APIVersionV2
+		helmChart.Dependencies()[0].Metadata.APIVersion = chart.APIVersionV2
+		helmChart.Templates = []*chart.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"),
+		}}
+
+		documentGroup = GenerateFromChart(helmChart, context, NewHelmOptionsWithValues("app", "default", "replicas: 0"))
+		diagnostics = context.GetAllDiagnostics()
+	})
+
+	builder.Build()
+
+	if len(documentGroup.Documents) != 0 {
+		t.Errorf("expected the chart not to be rendered with invalid values, got %d documents", len(documentGroup.Documents))
+	}
+
+	paths := []string{}
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Metadata.Id == InvalidHelmValuesDiagnosticMetadata.Id && diagnostic.Metadata.FailsBuild {
+			paths = append(paths, diagnostic.FieldPath)
+		}
+	}
+
+	if len(paths) != 1 || paths[0] != "replicas" {
+		t.Errorf("expected the invalid value to be reported as a diagnostic that fails the build, got %v", paths)
+	}
+}
@@ -8,11 +8,20 @@ func RegisterCore(jsRuntime *js.JsRuntime) {
 	registerBuilderOptions(jsRuntime)
 	registerComponent(jsRuntime)
 	registerDiagnostic(jsRuntime)
+	registerDiagnosticSuppression(jsRuntime)
+	registerDiagnosticFix(jsRuntime)
+	registerSchemaValidation(jsRuntime)
 	registerDocument(jsRuntime)
 	registerDocumentGroup(jsRuntime)
 	registerFile(jsRuntime)
 	registerHelm(jsRuntime)
+	registerJsonPatch(jsRuntime)
 	registerKubernetesResourceInfo(jsRuntime)
+	registerApiDeprecation(jsRuntime)
+	registerMutationLog(jsRuntime)
+	registerProfiler(jsRuntime)
+	registerProvenance(jsRuntime)
+	registerSourceLocation(jsRuntime)
 	registerProvisioner(jsRuntime)
 	registerQuantity(jsRuntime)
 	registerReport(jsRuntime)
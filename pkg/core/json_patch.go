@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const (
+	JsonPatchOperationAdd     = "add"
+	JsonPatchOperationRemove  = "remove"
+	JsonPatchOperationReplace = "replace"
+	JsonPatchOperationTest    = "test"
+)
+
+// JsonPatchOperation is a single RFC 6902 JSON Patch operation.
+type JsonPatchOperation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// Value of the add, replace and test operations, nil for remove operations.
+	Value any `json:"value"`
+}
+
+// Writes the value member for the add, replace and test operations even if the value is null, since RFC 6902
+// requires it. The member is omitted for the other operations.
+func (operation JsonPatchOperation) MarshalJSON() ([]byte, error) {
+	switch operation.Op {
+	case JsonPatchOperationAdd, JsonPatchOperationReplace, JsonPatchOperationTest:
+		type jsonPatchOperation JsonPatchOperation
+		return json.Marshal(jsonPatchOperation(operation))
+	default:
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{
+			Op:   operation.Op,
+			Path: operation.Path,
+		})
+	}
+}
+
+// Returns the JSON Patch operations that transform the source value into the target value. Values are expected
+// to be composed of maps, slices and scalars, e.g. the exported contents of a document. Map keys are visited in
+// sorted order so that the result is deterministic.
+func CreateJsonPatch(source any, target any) []*JsonPatchOperation {
+	operations := []*JsonPatchOperation{}
+	createJsonPatch("", source, target, &operations)
+
+	return operations
+}
+
+func createJsonPatch(path string, source any, target any, operations *[]*JsonPatchOperation) {
+	if reflect.DeepEqual(source, target) {
+		return
+	}
+
+	switch source := source.(type) {
+	case map[string]any:
+		target, ok := target.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := []string{}
+		for key := range source {
+			keys = append(keys, key)
+		}
+
+		for key := range target {
+			if _, ok := source[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			childPath := path + "/" + EscapeJsonPointerToken(key)
+			sourceValue, inSource := source[key]
+			targetValue, inTarget := target[key]
+
+			switch {
+			case !inTarget:
+				*operations = append(*operations, &JsonPatchOperation{Op: JsonPatchOperationRemove, Path: childPath})
+			case !inSource:
+				*operations = append(*operations, &JsonPatchOperation{Op: JsonPatchOperationAdd, Path: childPath, Value: targetValue})
+			default:
+				createJsonPatch(childPath, sourceValue, targetValue, operations)
+			}
+		}
+
+		return
+	case []any:
+		target, ok := target.([]any)
+		if !ok {
+			break
+		}
+
+		common := min(len(source), len(target))
+		for i := 0; i < common; i++ {
+			createJsonPatch(path+"/"+strconv.Itoa(i), source[i], target[i], operations)
+		}
+
+		for i := common; i < len(target); i++ {
+			*operations = append(*operations, &JsonPatchOperation{Op: JsonPatchOperationAdd, Path: path + "/" + strconv.Itoa(i), Value: target[i]})
+		}
+
+		// Remove from the end so that the indices of the remaining items don't shift.
+		for i := len(source) - 1; i >= common; i-- {
+			*operations = append(*operations, &JsonPatchOperation{Op: JsonPatchOperationRemove, Path: path + "/" + strconv.Itoa(i)})
+		}
+
+		return
+	}
+
+	*operations = append(*operations, &JsonPatchOperation{Op: JsonPatchOperationReplace, Path: path, Value: target})
+}
+
+// Escapes the given key to be used as a reference token in a JSON Pointer as described in RFC 6901.
+func EscapeJsonPointerToken(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+
+	return key
+}
+
+func registerJsonPatch(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[JsonPatchOperation]()).JsModule(
+		"builder",
+	).Fields(
+		js.Field("Op"),
+		js.Field("Path"),
+		js.Field("Value"),
+	)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateJsonPatch(t *testing.T) {
+	source := map[string]any{
+		"metadata": map[string]any{
+			"name": "app",
+			"annotations": map[string]any{
+				"removed": "true",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"args":     []any{"a", "b", "c"},
+			"ports":    []any{int64(80)},
+		},
+	}
+
+	target := map[string]any{
+		"metadata": map[string]any{
+			"name": "app",
+			"labels": map[string]any{
+				"app.kubernetes.io/name": "app",
+			},
+			"annotations": map[string]any{},
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"args":     []any{"a"},
+			"ports":    []any{int64(80), int64(443)},
+		},
+	}
+
+	operations := CreateJsonPatch(source, target)
+
+	data, err := json.Marshal(operations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"op":"remove","path":"/metadata/annotations/removed"},` +
+		`{"op":"add","path":"/metadata/labels","value":{"app.kubernetes.io/name":"app"}},` +
+		`{"op":"remove","path":"/spec/args/2"},` +
+		`{"op":"remove","path":"/spec/args/1"},` +
+		`{"op":"add","path":"/spec/ports/1","value":443},` +
+		`{"op":"replace","path":"/spec/replicas","value":3}` +
+		`]`
+
+	if string(data) != expected {
+		t.Errorf("unexpected patch\nexpected: %s\nactual:   %s", expected, string(data))
+	}
+}
+
+func TestCreateJsonPatchNullValues(t *testing.T) {
+	source := map[string]any{"a": "value", "b": "value"}
+	target := map[string]any{"a": nil, "c": nil}
+
+	data, err := json.Marshal(CreateJsonPatch(source, target))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"op":"replace","path":"/a","value":null},` +
+		`{"op":"remove","path":"/b"},` +
+		`{"op":"add","path":"/c","value":null}` +
+		`]`
+
+	if string(data) != expected {
+		t.Errorf("unexpected patch\nexpected: %s\nactual:   %s", expected, string(data))
+	}
+}
+
+func TestCreateJsonPatchEqualValues(t *testing.T) {
+	value := map[string]any{"a": []any{"b"}}
+
+	if operations := CreateJsonPatch(value, map[string]any{"a": []any{"b"}}); len(operations) != 0 {
+		t.Errorf("expected no operations, got %d", len(operations))
+	}
+}
+
+func TestEscapeJsonPointerToken(t *testing.T) {
+	if escaped := EscapeJsonPointerToken("a/b~c"); escaped != "a~1b~0c" {
+		t.Errorf("unexpected escaped token: %s", escaped)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/grafana/sobek"
 	"github.com/ohayocorp/anemos/pkg/js"
 )
 
@@ -20,6 +21,9 @@ type KubernetesResource struct {
 	ApiVersion   string
 	Kind         string
 	IsNamespaced bool
+	// OpenAPI v3 schema of the resource, i.e. the openAPIV3Schema field of a CRD version. Custom resources
+	// of this kind are validated against the schema if it is set.
+	Schema *sobek.Object
 }
 
 // KubernetesResourceInfo contains all the API resources defined in the target cluster and enables listing them
@@ -27,6 +31,7 @@ type KubernetesResource struct {
 type KubernetesResourceInfo struct {
 	resources      mapset.Set[*KubernetesResource]
 	namespaceCache map[string]bool
+	version        *semver.Version
 }
 
 func NewKubernetesResource(apiVersion string, kind string, isNamespaced bool) *KubernetesResource {
@@ -37,11 +42,21 @@ func NewKubernetesResource(apiVersion string, kind string, isNamespaced bool) *K
 	}
 }
 
+func NewKubernetesResourceWithSchema(apiVersion string, kind string, isNamespaced bool, schema *sobek.Object) *KubernetesResource {
+	return &KubernetesResource{
+		ApiVersion:   apiVersion,
+		Kind:         kind,
+		IsNamespaced: isNamespaced,
+		Schema:       schema,
+	}
+}
+
 // Creates a new [KubernetesResourceInfo] instance.
 func NewKubernetesResourceInfo(version *semver.Version) *KubernetesResourceInfo {
 	info := KubernetesResourceInfo{
 		resources:      mapset.NewSet[*KubernetesResource](),
 		namespaceCache: make(map[string]bool),
+		version:        version,
 	}
 
 	info.populateBuiltInResources(version)
@@ -759,8 +774,10 @@ func registerKubernetesResourceInfo(jsRuntime *js.JsRuntime) {
 		js.Field("ApiVersion"),
 		js.Field("Kind"),
 		js.Field("IsNamespaced"),
+		js.Field("Schema"),
 	).Constructors(
 		js.Constructor(reflect.ValueOf(NewKubernetesResource)),
+		js.Constructor(reflect.ValueOf(NewKubernetesResourceWithSchema)),
 	)
 
 	jsRuntime.Type(reflect.TypeFor[KubernetesResourceInfo]()).JsModule(
@@ -770,6 +787,7 @@ func registerKubernetesResourceInfo(jsRuntime *js.JsRuntime) {
 		js.Method("AddKubernetesResource").JsName("addResource"),
 		js.Method("Contains"),
 		js.Method("ContainsKind"),
+		js.Method("GetApiDeprecation"),
 		js.Method("IsNamespaced"),
 	)
 }
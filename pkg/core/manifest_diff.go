@@ -0,0 +1,426 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ManifestChangeTypeAdded    ManifestChangeType = "added"
+	ManifestChangeTypeRemoved  ManifestChangeType = "removed"
+	ManifestChangeTypeModified ManifestChangeType = "modified"
+)
+
+type ManifestChangeType string
+
+// ResourceIdentity identifies a Kubernetes resource independent of the file it is written to.
+type ResourceIdentity struct {
+	ApiVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// Manifest is a single parsed YAML document and the file path it was read from or will be written to.
+type Manifest struct {
+	Path    string
+	Content map[string]any
+}
+
+// FieldChange is a single difference between two versions of a manifest.
+type FieldChange struct {
+	Path     string
+	Type     ManifestChangeType
+	OldValue any
+	NewValue any
+}
+
+// ManifestChange describes how a resource changed between two sets of manifests.
+type ManifestChange struct {
+	Identity     string
+	Type         ManifestChangeType
+	Path         string
+	PreviousPath string
+	FieldChanges []*FieldChange
+}
+
+// Returns the identity of the given manifest content. Returns nil if kind or name is missing.
+func GetResourceIdentity(content map[string]any) *ResourceIdentity {
+	getString := func(object map[string]any, key string) string {
+		value, _ := object[key].(string)
+		return value
+	}
+
+	metadata, _ := content["metadata"].(map[string]any)
+
+	identity := &ResourceIdentity{
+		ApiVersion: getString(content, "apiVersion"),
+		Kind:       getString(content, "kind"),
+		Namespace:  getString(metadata, "namespace"),
+		Name:       getString(metadata, "name"),
+	}
+
+	if identity.Kind == "" || identity.Name == "" {
+		return nil
+	}
+
+	return identity
+}
+
+func (identity *ResourceIdentity) String() string {
+	if identity.Namespace == "" {
+		return fmt.Sprintf("%s %s %s", identity.ApiVersion, identity.Kind, identity.Name)
+	}
+
+	return fmt.Sprintf("%s %s %s/%s", identity.ApiVersion, identity.Kind, identity.Namespace, identity.Name)
+}
+
+// Parses the given YAML text that may contain multiple documents into manifests. Empty documents and
+// documents that are not mappings, e.g. lists or scalars, are skipped.
+func ParseManifests(path string, data []byte) ([]*Manifest, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	manifests := []*Manifest{}
+
+	for {
+		var value any
+
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("can't parse manifest %s, %w", path, err)
+		}
+
+		content, ok := value.(map[string]any)
+		if !ok || len(content) == 0 {
+			continue
+		}
+
+		manifests = append(manifests, &Manifest{
+			Path:    path,
+			Content: content,
+		})
+	}
+
+	return manifests, nil
+}
+
+// Reads the manifests under the given directory. If the directory has a documents index, only the files in
+// the index are read, otherwise all YAML files are read recursively. Paths of the manifests are relative to
+// the directory and use forward slashes. Returns an empty slice if the directory doesn't exist.
+func ReadManifestsDirectory(directory string) ([]*Manifest, error) {
+	manifests := []*Manifest{}
+
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		return manifests, nil
+	}
+
+	paths, err := readDocumentsIndex(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	if paths == nil {
+		paths, err = findYamlFiles(directory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(directory, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("can't read manifest %s, %w", path, err)
+		}
+
+		parsed, err := ParseManifests(path, data)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, parsed...)
+	}
+
+	return manifests, nil
+}
+
+// Writes the documents index that lists the given document paths into the given documents directory.
+func WriteDocumentsIndex(directory string, paths []string) error {
+	paths = slices.Clone(paths)
+	sort.Strings(paths)
+
+	content := strings.Join(paths, "\n")
+	if content != "" {
+		content += "\n"
+	}
+
+	filePath := filepath.Join(directory, DocumentsIndexFile)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("can't write documents index %s, %w", filePath, err)
+	}
+
+	return nil
+}
+
+// Returns the paths in the documents index of the given directory. Returns nil if the directory doesn't
+// have an index, e.g. it is written by an older version.
+func readDocumentsIndex(directory string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(directory, DocumentsIndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read documents index, %w", err)
+	}
+
+	paths := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return paths, nil
+}
+
+// Returns the relative paths of the YAML files under the given directory using forward slashes.
+func findYamlFiles(directory string) ([]string, error) {
+	paths := []string{}
+
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		extension := filepath.Ext(path)
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml") {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, filepath.ToSlash(relativePath))
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// Serializes the given documents and converts them into manifests. Paths of the manifests are
+// the full paths of the documents.
+func DocumentsToManifests(context *BuildContext, documents []*Document) ([]*Manifest, error) {
+	manifests := []*Manifest{}
+
+	for _, document := range documents {
+		yamlText, err := SerializeSobekObjectToYaml(context.JsRuntime, document.Object)
+		if err != nil {
+			return nil, fmt.Errorf("can't serialize document %s, %w", document.FullPath(), err)
+		}
+
+		parsed, err := ParseManifests(document.FullPath(), []byte(yamlText))
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, parsed...)
+	}
+
+	return manifests, nil
+}
+
+// Compares two sets of manifests by their resource identities (apiVersion, kind, namespace and name)
+// rather than their file paths. Manifests without an identity are matched by their file paths.
+// Returned changes are sorted by the resource identity.
+func CompareManifests(previous []*Manifest, current []*Manifest) []*ManifestChange {
+	previousByKey := indexManifests(previous)
+	currentByKey := indexManifests(current)
+
+	changes := []*ManifestChange{}
+
+	for _, key := range SortedKeys(currentByKey) {
+		currentManifest := currentByKey[key]
+		previousManifest, ok := previousByKey[key]
+
+		if !ok {
+			changes = append(changes, &ManifestChange{
+				Identity: key,
+				Type:     ManifestChangeTypeAdded,
+				Path:     currentManifest.Path,
+			})
+
+			continue
+		}
+
+		fieldChanges := DiffValues("", previousManifest.Content, currentManifest.Content)
+		if len(fieldChanges) == 0 {
+			continue
+		}
+
+		changes = append(changes, &ManifestChange{
+			Identity:     key,
+			Type:         ManifestChangeTypeModified,
+			Path:         currentManifest.Path,
+			PreviousPath: previousManifest.Path,
+			FieldChanges: fieldChanges,
+		})
+	}
+
+	for _, key := range SortedKeys(previousByKey) {
+		if _, ok := currentByKey[key]; ok {
+			continue
+		}
+
+		changes = append(changes, &ManifestChange{
+			Identity:     key,
+			Type:         ManifestChangeTypeRemoved,
+			PreviousPath: previousByKey[key].Path,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Identity < changes[j].Identity
+	})
+
+	return changes
+}
+
+func indexManifests(manifests []*Manifest) map[string]*Manifest {
+	result := map[string]*Manifest{}
+
+	for _, manifest := range manifests {
+		key := fmt.Sprintf("file %s", manifest.Path)
+
+		identity := GetResourceIdentity(manifest.Content)
+		if identity != nil {
+			key = identity.String()
+		}
+
+		result[key] = manifest
+	}
+
+	return result
+}
+
+// Returns the field level differences between the given values. Maps are compared key by key and
+// lists are compared index by index. Paths are in the form of "spec.containers[0].image".
+func DiffValues(path string, oldValue any, newValue any) []*FieldChange {
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+
+	if oldIsMap && newIsMap {
+		keys := []string{}
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		slices.Sort(keys)
+
+		changes := []*FieldChange{}
+		for _, key := range keys {
+			childPath := joinFieldPath(path, key)
+			oldChild, oldOk := oldMap[key]
+			newChild, newOk := newMap[key]
+
+			switch {
+			case !oldOk:
+				changes = append(changes, &FieldChange{Path: childPath, Type: ManifestChangeTypeAdded, NewValue: newChild})
+			case !newOk:
+				changes = append(changes, &FieldChange{Path: childPath, Type: ManifestChangeTypeRemoved, OldValue: oldChild})
+			default:
+				changes = append(changes, DiffValues(childPath, oldChild, newChild)...)
+			}
+		}
+
+		return changes
+	}
+
+	oldList, oldIsList := oldValue.([]any)
+	newList, newIsList := newValue.([]any)
+
+	if oldIsList && newIsList {
+		changes := []*FieldChange{}
+		for i := 0; i < max(len(oldList), len(newList)); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= len(oldList):
+				changes = append(changes, &FieldChange{Path: childPath, Type: ManifestChangeTypeAdded, NewValue: newList[i]})
+			case i >= len(newList):
+				changes = append(changes, &FieldChange{Path: childPath, Type: ManifestChangeTypeRemoved, OldValue: oldList[i]})
+			default:
+				changes = append(changes, DiffValues(childPath, oldList[i], newList[i])...)
+			}
+		}
+
+		return changes
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	return []*FieldChange{{Path: path, Type: ManifestChangeTypeModified, OldValue: oldValue, NewValue: newValue}}
+}
+
+func joinFieldPath(path string, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// Formats the given field value as compact JSON to be used in change descriptions.
+func FormatFieldValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(data)
+}
+
+// Returns a single line description of the given field change, e.g. "spec.replicas: 1 -> 3".
+func (change *FieldChange) String() string {
+	switch change.Type {
+	case ManifestChangeTypeAdded:
+		return fmt.Sprintf("%s: added %s", change.Path, FormatFieldValue(change.NewValue))
+	case ManifestChangeTypeRemoved:
+		return fmt.Sprintf("%s: removed %s", change.Path, FormatFieldValue(change.OldValue))
+	default:
+		return fmt.Sprintf("%s: %s -> %s", change.Path, FormatFieldValue(change.OldValue), FormatFieldValue(change.NewValue))
+	}
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCompareManifests(t *testing.T) {
+	previous, err := ParseManifests("previous.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: unchanged
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := ParseManifests("current.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  labels:
+    app.kubernetes.io/name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:2.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: unchanged
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: added
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := CompareManifests(previous, current)
+
+	expected := []struct {
+		identity   string
+		changeType ManifestChangeType
+	}{
+		{"apps/v1 Deployment default/app", ManifestChangeTypeModified},
+		{"v1 ConfigMap removed", ManifestChangeTypeRemoved},
+		{"v1 Secret added", ManifestChangeTypeAdded},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(changes))
+	}
+
+	for i, e := range expected {
+		if changes[i].Identity != e.identity || changes[i].Type != e.changeType {
+			t.Errorf("expected change %s %s, got %s %s", e.changeType, e.identity, changes[i].Type, changes[i].Identity)
+		}
+	}
+
+	expectedFieldChanges := []string{
+		`metadata.labels: added {"app.kubernetes.io/name":"app"}`,
+		`spec.replicas: 1 -> 3`,
+		`spec.template.spec.containers[0].image: "app:1.0" -> "app:2.0"`,
+	}
+
+	fieldChanges := changes[0].FieldChanges
+	if len(fieldChanges) != len(expectedFieldChanges) {
+		t.Fatalf("expected %d field changes, got %d", len(expectedFieldChanges), len(fieldChanges))
+	}
+
+	for i, e := range expectedFieldChanges {
+		if fieldChanges[i].String() != e {
+			t.Errorf("expected field change %q, got %q", e, fieldChanges[i].String())
+		}
+	}
+}
+
+func TestDiffValuesEscapesKeys(t *testing.T) {
+	changes := DiffValues("metadata.labels", map[string]any{"app.kubernetes.io/name": "a"}, map[string]any{"app.kubernetes.io/name": "b"})
+
+	if len(changes) != 1 || changes[0].Path != `metadata.labels["app.kubernetes.io/name"]` {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+}
+
+func TestReadManifestsDirectory(t *testing.T) {
+	directory := t.TempDir()
+
+	files := map[string]string{
+		"app/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+		"app/values.yaml":     "replicas: 3\n",
+		"app/hosts.yaml":      "- app.example.com\n",
+	}
+
+	for path, content := range files {
+		filePath := filepath.Join(directory, filepath.FromSlash(path))
+
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertPaths := func(expected ...string) {
+		t.Helper()
+
+		manifests, err := ReadManifestsDirectory(directory)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paths := []string{}
+		for _, manifest := range manifests {
+			paths = append(paths, manifest.Path)
+		}
+
+		if !slices.Equal(paths, expected) {
+			t.Errorf("expected manifests %v, got %v", expected, paths)
+		}
+	}
+
+	// Without an index, all YAML mappings are read and the other content is skipped.
+	assertPaths("app/deployment.yaml", "app/values.yaml")
+
+	if err := WriteDocumentsIndex(directory, []string{"app/deployment.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPaths("app/deployment.yaml")
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// MutationLogEntry contains the changes a component action made to a single document.
+type MutationLogEntry struct {
+	// Component action that made the changes.
+	Component *ProvenanceEntry
+	// Path of the document at the time of the action.
+	DocumentPath string
+	// Changes as RFC 6902 JSON Patch operations that transform the document from its state before the action
+	// to its state after the action.
+	Operations []*JsonPatchOperation
+}
+
+// Returns the changes the component actions made to the documents in the order the actions ran. Returns an
+// empty slice if the mutation log is not enabled.
+func (builder *Builder) GetMutationLog() []*MutationLogEntry {
+	if builder.mutationLog == nil {
+		return []*MutationLogEntry{}
+	}
+
+	return builder.mutationLog
+}
+
+// Returns the changes the component actions made to the documents so far in the order the actions ran.
+// Returns an empty slice if the mutation log is not enabled.
+func (context *BuildContext) GetMutationLog() []*MutationLogEntry {
+	return context.builder.GetMutationLog()
+}
+
+func (tracker *provenanceTracker) recordMutation(context *BuildContext, entry *ProvenanceEntry, document *Document, previous any, current any) {
+	if !tracker.recordMutations {
+		return
+	}
+
+	context.builder.mutationLog = append(context.builder.mutationLog, &MutationLogEntry{
+		Component:    entry,
+		DocumentPath: document.FullPath(),
+		Operations:   CreateJsonPatch(previous, current),
+	})
+}
+
+func registerMutationLog(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[MutationLogEntry]()).JsModule(
+		"builder",
+	).Fields(
+		js.Field("Component"),
+		js.Field("DocumentPath"),
+		js.Field("Operations"),
+	)
+}
@@ -41,6 +41,35 @@ func ParseDocument(jsRuntime *js.JsRuntime, yaml string) (*Document, error) {
 }
 
 func Parse(jsRuntime *js.JsRuntime, yamlText string) (*sobek.Object, error) {
+	return parseWithLocations(jsRuntime, yamlText, nil)
+}
+
+// Parses given text as a [Document] and records where the document and its fields come from. The file is
+// used as the file of the field locations, e.g. the name of the Helm template the document was rendered from.
+func parseDocumentWithSource(jsRuntime *js.JsRuntime, yamlText string, file string) (*Document, error) {
+	locations := newYamlLocations(file, 1, yamlText)
+
+	object, err := parseWithLocations(jsRuntime, yamlText, locations)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return nil, nil
+	}
+
+	document := NewDocumentWithContent(object)
+	document.source = &DocumentSource{
+		CallSite: getScriptCallSite(jsRuntime),
+		Template: file,
+		fields:   locations.fields,
+	}
+
+	return document, nil
+}
+
+// Parses given text and adds the locations of the fields to the given collector if it is not nil.
+func parseWithLocations(jsRuntime *js.JsRuntime, yamlText string, locations *yamlLocations) (*sobek.Object, error) {
 	if yamlText == "" {
 		return nil, fmt.Errorf("can't parse empty yaml")
 	}
@@ -50,7 +79,7 @@ func Parse(jsRuntime *js.JsRuntime, yamlText string) (*sobek.Object, error) {
 		return nil, err
 	}
 
-	value, err := parseYamlNode(jsRuntime, &node)
+	value, err := parseYamlNode(jsRuntime, &node, locations, "")
 	if err != nil {
 		return nil, err
 	}
@@ -62,16 +91,18 @@ func Parse(jsRuntime *js.JsRuntime, yamlText string) (*sobek.Object, error) {
 	return value.ToObject(jsRuntime.Runtime), nil
 }
 
-func parseYamlNode(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, error) {
+func parseYamlNode(jsRuntime *js.JsRuntime, node *yaml.Node, locations *yamlLocations, fieldPath string) (sobek.Value, error) {
 	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
 		node = node.Content[0]
 	}
 
+	locations.add(fieldPath, node)
+
 	if scalar := tryParseScalar(jsRuntime, node); scalar != nil {
 		return scalar, nil
 	}
 
-	sequence, err := tryParseSequence(jsRuntime, node)
+	sequence, err := tryParseSequence(jsRuntime, node, locations, fieldPath)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +110,7 @@ func parseYamlNode(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, error
 		return sequence, nil
 	}
 
-	mapping, err := tryParseMapping(jsRuntime, node)
+	mapping, err := tryParseMapping(jsRuntime, node, locations, fieldPath)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +123,7 @@ func parseYamlNode(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, error
 	return nil, nil
 }
 
-func tryParseMapping(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, error) {
+func tryParseMapping(jsRuntime *js.JsRuntime, node *yaml.Node, locations *yamlLocations, fieldPath string) (sobek.Value, error) {
 	if node.Kind != yaml.MappingNode {
 		return nil, nil
 	}
@@ -103,7 +134,11 @@ func tryParseMapping(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, err
 		key := node.Content[i]
 		value := node.Content[i+1]
 
-		valueObject, err := parseYamlNode(jsRuntime, value)
+		// Point to the key instead of the value so that the location of the field is reported.
+		keyPath := locations.fieldPath(fieldPath, key.Value)
+		locations.add(keyPath, key)
+
+		valueObject, err := parseYamlNode(jsRuntime, value, locations, keyPath)
 		if err != nil {
 			return nil, err
 		}
@@ -114,7 +149,7 @@ func tryParseMapping(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, err
 	return object, nil
 }
 
-func tryParseSequence(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, error) {
+func tryParseSequence(jsRuntime *js.JsRuntime, node *yaml.Node, locations *yamlLocations, fieldPath string) (sobek.Value, error) {
 	if node.Kind != yaml.SequenceNode {
 		return nil, nil
 	}
@@ -122,7 +157,7 @@ func tryParseSequence(jsRuntime *js.JsRuntime, node *yaml.Node) (sobek.Value, er
 	array := jsRuntime.Runtime.NewArray()
 
 	for i, content := range node.Content {
-		valueObject, err := parseYamlNode(jsRuntime, content)
+		valueObject, err := parseYamlNode(jsRuntime, content, locations, locations.indexPath(fieldPath, i))
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,305 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+const (
+	ProfileCategoryAction        = "action"
+	ProfileCategoryHelm          = "helm"
+	ProfileCategorySerialization = "serialization"
+)
+
+// Profilers that are active during [Builder.Build], keyed by the JS runtime of the builder. Used by the functions
+// that only have access to the runtime, e.g. YAML serialization.
+var activeProfilers sync.Map
+
+// ProfileSpan is a measured section of the build, e.g. an action of a component or rendering a Helm chart.
+type ProfileSpan struct {
+	Category            string
+	Name                string
+	Step                string
+	ComponentType       string
+	ComponentIdentifier string
+	Start               time.Time
+	Duration            time.Duration
+	Args                map[string]string
+}
+
+// Profiler measures the time spent in each action callback and in expensive operations such as Helm rendering
+// and YAML serialization.
+type Profiler struct {
+	// Path of the Chrome trace event file that is written after the build. Nothing is written if empty.
+	TraceFilePath string
+
+	start time.Time
+	spans []*ProfileSpan
+	lock  sync.Mutex
+}
+
+// ProfileSummaryEntry is the total duration of the spans that share the same key.
+type ProfileSummaryEntry struct {
+	Name     string
+	Count    int
+	Duration time.Duration
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{
+		start: time.Now(),
+	}
+}
+
+// Starts a new span and returns the function that ends it. Safe to call on a nil profiler, in which case
+// nothing is measured.
+func (profiler *Profiler) StartSpan(category string, name string, args map[string]string) func() {
+	return profiler.startSpan(&ProfileSpan{
+		Category: category,
+		Name:     name,
+		Args:     args,
+	})
+}
+
+func (profiler *Profiler) startSpan(span *ProfileSpan) func() {
+	if profiler == nil {
+		return func() {}
+	}
+
+	span.Start = time.Now()
+
+	return func() {
+		span.Duration = time.Since(span.Start)
+
+		profiler.lock.Lock()
+		defer profiler.lock.Unlock()
+
+		profiler.spans = append(profiler.spans, span)
+	}
+}
+
+func (profiler *Profiler) startActionSpan(step Step, component *Component) func() {
+	span := &ProfileSpan{
+		Category: ProfileCategoryAction,
+		Name:     getProfileComponentName(component),
+		Step:     fmt.Sprintf("%s - %s", step.String(), step.Description),
+	}
+
+	if componentType := component.GetComponentType(); componentType != nil {
+		span.ComponentType = *componentType
+	}
+
+	if identifier := component.GetIdentifier(); identifier != nil {
+		span.ComponentIdentifier = *identifier
+	}
+
+	return profiler.startSpan(span)
+}
+
+// Returns the completed spans ordered by their start times.
+func (profiler *Profiler) Spans() []*ProfileSpan {
+	profiler.lock.Lock()
+	defer profiler.lock.Unlock()
+
+	spans := slices.Clone(profiler.spans)
+	slices.SortStableFunc(spans, func(a, b *ProfileSpan) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return spans
+}
+
+// Returns the total duration of the action spans grouped by step, ordered by the step order.
+func (profiler *Profiler) SummaryByStep() []*ProfileSummaryEntry {
+	return profiler.summarize(ProfileCategoryAction, false, func(span *ProfileSpan) string {
+		return span.Step
+	})
+}
+
+// Returns the total duration of the action spans grouped by component, longest first.
+func (profiler *Profiler) SummaryByComponent() []*ProfileSummaryEntry {
+	return profiler.summarize(ProfileCategoryAction, true, func(span *ProfileSpan) string {
+		return span.Name
+	})
+}
+
+// Returns the total duration of the spans in the given category grouped by their names, longest first.
+func (profiler *Profiler) SummaryByName(category string) []*ProfileSummaryEntry {
+	return profiler.summarize(category, true, func(span *ProfileSpan) string {
+		return span.Name
+	})
+}
+
+func (profiler *Profiler) summarize(category string, sortByDuration bool, key func(span *ProfileSpan) string) []*ProfileSummaryEntry {
+	entries := []*ProfileSummaryEntry{}
+	entriesByKey := map[string]*ProfileSummaryEntry{}
+
+	for _, span := range profiler.Spans() {
+		if span.Category != category {
+			continue
+		}
+
+		name := key(span)
+
+		entry, ok := entriesByKey[name]
+		if !ok {
+			entry = &ProfileSummaryEntry{Name: name}
+			entriesByKey[name] = entry
+			entries = append(entries, entry)
+		}
+
+		entry.Count++
+		entry.Duration += span.Duration
+	}
+
+	if sortByDuration {
+		slices.SortStableFunc(entries, func(a, b *ProfileSummaryEntry) int {
+			return int(b.Duration - a.Duration)
+		})
+	}
+
+	return entries
+}
+
+// Returns a markdown document that contains the durations grouped by step, component, Helm chart and
+// serialization.
+func (profiler *Profiler) MarkdownSummary() string {
+	builder := &strings.Builder{}
+
+	writeTable := func(title string, keyTitle string, entries []*ProfileSummaryEntry) {
+		if len(entries) == 0 {
+			return
+		}
+
+		fmt.Fprintf(builder, "## %s\n\n", title)
+		fmt.Fprintf(builder, "| %s | Count | Duration |\n", keyTitle)
+		fmt.Fprintf(builder, "| --- | ---: | ---: |\n")
+
+		for _, entry := range entries {
+			fmt.Fprintf(builder, "| %s | %d | %s |\n", entry.Name, entry.Count, formatProfileDuration(entry.Duration))
+		}
+
+		fmt.Fprintf(builder, "\n")
+	}
+
+	fmt.Fprintf(builder, "# Build Profile\n\n")
+	fmt.Fprintf(builder, "Total time elapsed: %s\n\n", formatProfileDuration(time.Since(profiler.start)))
+
+	writeTable("Steps", "Step", profiler.SummaryByStep())
+	writeTable("Components", "Component", profiler.SummaryByComponent())
+	writeTable("Helm Charts", "Chart", profiler.SummaryByName(ProfileCategoryHelm))
+	writeTable("Serialization", "Operation", profiler.SummaryByName(ProfileCategorySerialization))
+
+	return builder.String()
+}
+
+type chromeTraceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	ProcessId int               `json:"pid"`
+	ThreadId  int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// Writes the spans in the Chrome trace event format which can be opened with chrome://tracing or Perfetto.
+func (profiler *Profiler) WriteChromeTrace(path string) error {
+	events := []*chromeTraceEvent{}
+
+	for _, span := range profiler.Spans() {
+		args := map[string]string{}
+		for key, value := range span.Args {
+			args[key] = value
+		}
+
+		if span.Step != "" {
+			args["step"] = span.Step
+		}
+
+		if span.ComponentType != "" {
+			args["componentType"] = span.ComponentType
+		}
+
+		if span.ComponentIdentifier != "" {
+			args["identifier"] = span.ComponentIdentifier
+		}
+
+		events = append(events, &chromeTraceEvent{
+			Name:      span.Name,
+			Category:  span.Category,
+			Phase:     "X",
+			Timestamp: span.Start.Sub(profiler.start).Microseconds(),
+			Duration:  span.Duration.Microseconds(),
+			ProcessId: 1,
+			ThreadId:  1,
+			Args:      args,
+		})
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"traceEvents": events}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize trace events: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for trace file %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write trace file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Returns the profiler of the build that is running on the given runtime, nil if profiling is not enabled.
+func GetActiveProfiler(jsRuntime *js.JsRuntime) *Profiler {
+	profiler, ok := activeProfilers.Load(jsRuntime)
+	if !ok {
+		return nil
+	}
+
+	return profiler.(*Profiler)
+}
+
+func getProfileComponentName(component *Component) string {
+	componentType := component.GetComponentType()
+	identifier := component.GetIdentifier()
+
+	switch {
+	case identifier != nil && componentType != nil && *identifier != *componentType:
+		return fmt.Sprintf("%s (%s)", *identifier, *componentType)
+	case identifier != nil:
+		return *identifier
+	case componentType != nil:
+		return *componentType
+	default:
+		return "anonymous"
+	}
+}
+
+func formatProfileDuration(duration time.Duration) string {
+	return duration.Round(time.Microsecond * 100).String()
+}
+
+func registerProfiler(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[Profiler]()).JsModule(
+		"profiler",
+	).Fields(
+		js.Field("TraceFilePath"),
+	).Methods(
+		js.Method("MarkdownSummary"),
+		js.Method("WriteChromeTrace"),
+	).DisableObjectMapping()
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+// Annotations with this prefix are ignored while detecting modifications so that writing the provenance
+// into the documents is not recorded as a modification.
+const ProvenanceAnnotationPrefix = "provenance.anemos.sh/"
+
+// ProvenanceEntry identifies a component action that created or modified a document.
+type ProvenanceEntry struct {
+	ComponentType string
+	Identifier    string
+	Step          string
+	// Stack trace of the place where the component was added to the builder.
+	StackTrace string
+}
+
+// DocumentProvenance records which component created a document and which components modified it afterwards.
+type DocumentProvenance struct {
+	CreatedBy  *ProvenanceEntry
+	ModifiedBy []*ProvenanceEntry
+}
+
+// Returns the name of the component in the form of "identifier (type)".
+func (entry *ProvenanceEntry) ComponentName() string {
+	switch {
+	case entry.Identifier != "" && entry.ComponentType != "" && entry.Identifier != entry.ComponentType:
+		return entry.Identifier + " (" + entry.ComponentType + ")"
+	case entry.Identifier != "":
+		return entry.Identifier
+	case entry.ComponentType != "":
+		return entry.ComponentType
+	default:
+		return "anonymous"
+	}
+}
+
+// Returns the names of the components that modified the document, without duplicates.
+func (provenance *DocumentProvenance) ModifiedByComponentNames() []string {
+	names := []string{}
+
+	for _, entry := range provenance.ModifiedBy {
+		name := entry.ComponentName()
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func newProvenanceEntry(step Step, component *Component) *ProvenanceEntry {
+	entry := &ProvenanceEntry{
+		Step:       step.String() + " - " + step.Description,
+		StackTrace: cleanProvenanceStackTrace(component.stackTrace),
+	}
+
+	if componentType := component.GetComponentType(); componentType != nil {
+		entry.ComponentType = *componentType
+	}
+
+	if identifier := component.GetIdentifier(); identifier != nil {
+		entry.Identifier = *identifier
+	}
+
+	return entry
+}
+
+// Drops the native frames from the stack trace since they only point to the runtime internals.
+func cleanProvenanceStackTrace(stackTrace string) string {
+	lines := []string{}
+
+	for _, line := range strings.Split(stackTrace, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, "(native)") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Keeps the contents of the documents between actions to find out which actions modified them.
+type provenanceTracker struct {
+	snapshots map[*Document]any
+	// Records the differences between the snapshots into the mutation log of the builder.
+	recordMutations bool
+}
+
+func newProvenanceTracker(recordMutations bool) *provenanceTracker {
+	return &provenanceTracker{
+		snapshots:       map[*Document]any{},
+		recordMutations: recordMutations,
+	}
+}
+
+// Records the given component as the creator of the documents that don't have a creator yet. If detectModifications
+// is true, also records the component as a modifier of the documents whose contents changed since the last call. Safe
+// to call on a nil tracker, i.e. when provenance tracking is disabled, in which case nothing is recorded.
+func (tracker *provenanceTracker) recordAction(context *BuildContext, step Step, component *Component, detectModifications bool) {
+	if tracker == nil {
+		return
+	}
+
+	var entry *ProvenanceEntry
+	getEntry := func() *ProvenanceEntry {
+		if entry == nil {
+			entry = newProvenanceEntry(step, component)
+		}
+
+		return entry
+	}
+
+	documents := context.GetAllDocuments()
+
+	for _, document := range documents {
+		if document.provenance == nil {
+			document.provenance = &DocumentProvenance{
+				CreatedBy:  getEntry(),
+				ModifiedBy: []*ProvenanceEntry{},
+			}
+		}
+	}
+
+	if !detectModifications {
+		return
+	}
+
+	snapshots := make(map[*Document]any, len(documents))
+
+	for _, document := range documents {
+		snapshot := getProvenanceSnapshot(document)
+		snapshots[document] = snapshot
+
+		previous, ok := tracker.snapshots[document]
+		if ok && !reflect.DeepEqual(previous, snapshot) {
+			document.provenance.ModifiedBy = append(document.provenance.ModifiedBy, getEntry())
+			tracker.recordMutation(context, getEntry(), document, previous, snapshot)
+		}
+	}
+
+	tracker.snapshots = snapshots
+}
+
+func getProvenanceSnapshot(document *Document) any {
+	content, ok := document.Object.Export().(map[string]any)
+	if !ok {
+		return document.Object.Export()
+	}
+
+	metadata, ok := content["metadata"].(map[string]any)
+	if !ok {
+		return content
+	}
+
+	annotations, ok := metadata["annotations"].(map[string]any)
+	if !ok {
+		return content
+	}
+
+	filtered := map[string]any{}
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, ProvenanceAnnotationPrefix) {
+			filtered[key] = value
+		}
+	}
+
+	metadata["annotations"] = filtered
+
+	return content
+}
+
+// Returns the provenance of the document. Returns nil if the document hasn't been added to the build yet.
+func (document *Document) GetProvenance() *DocumentProvenance {
+	return document.provenance
+}
+
+func registerProvenance(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[DocumentProvenance]()).JsModule(
+		"document",
+	).Fields(
+		js.Field("CreatedBy"),
+		js.Field("ModifiedBy"),
+	).Methods(
+		js.Method("ModifiedByComponentNames"),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[ProvenanceEntry]()).JsModule(
+		"document",
+	).Fields(
+		js.Field("ComponentType"),
+		js.Field("Identifier"),
+		js.Field("Step"),
+		js.Field("StackTrace"),
+	).Methods(
+		js.Method("ComponentName"),
+	)
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/grafana/sobek"
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/k8sschema"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaValidationError describes a field of a document that doesn't conform to the schema of its kind.
+type SchemaValidationError struct {
+	// Path of the field, e.g. "spec.template.spec.containers[0].imagePullPolicy".
+	FieldPath string
+	// Type of the error: "unknown-field", "wrong-type", "missing-required", "invalid-value" or "rule-violation".
+	Type    string
+	Message string
+}
+
+// Validates the document against the OpenAPI schema of its kind for the Kubernetes version of the build.
+// Uses the embedded schemas, so it doesn't require access to a cluster. Returns nil if there is no schema
+// for the kind of the document, e.g. for custom resources.
+func (context *BuildContext) ValidateDocumentSchema(document *Document) []*SchemaValidationError {
+	apiVersion := SobekObjectGetString(document.Object, "apiVersion")
+	kind := SobekObjectGetString(document.Object, "kind")
+
+	if apiVersion == nil || kind == nil {
+		return nil
+	}
+
+	spec, err := k8sschema.GetSpec(context.BuilderOptions.KubernetesCluster.Version)
+	if err != nil {
+		js.Throw(err)
+	}
+
+	if spec.GetSchema(*apiVersion, *kind) == nil {
+		return nil
+	}
+
+	validationErrors, _ := spec.Validate(*apiVersion, *kind, context.DocumentToMap(document))
+
+	return newSchemaValidationErrors(validationErrors)
+}
+
+// Returns the version of the embedded OpenAPI schema that the documents are validated against if there is no
+// embedded schema for the Kubernetes version of the build, e.g. "1.34". Returns nil if the schema of the
+// Kubernetes version of the build is used, or if the version is not specified since the closest schema to the
+// default version is always used in that case.
+func (context *BuildContext) GetSchemaFallbackVersion() *string {
+	version := context.BuilderOptions.KubernetesCluster.Version
+
+	// Builder options use the default version itself when the version is not specified.
+	if version == DefaultKubernetesVersion {
+		return nil
+	}
+
+	specVersion := k8sschema.GetSpecVersion(version)
+
+	if version != nil && specVersion == fmt.Sprintf("%d.%d", version.Major(), version.Minor()) {
+		return nil
+	}
+
+	return &specVersion
+}
+
+// Validates the custom resource against the schema of its CRD, including the enum, pattern and required fields
+// and the x-kubernetes-validations rules. The schemas are collected from the CRDs in the build, the additional
+// resources of the Kubernetes cluster that have a schema and the schema files of the Kubernetes cluster.
+// Returns nil if there is no schema for the kind of the document.
+//
+// The schemas are collected the first time this method is called, i.e. the CRDs that are added to the build
+// afterwards are not taken into account.
+func (context *BuildContext) ValidateCustomResource(document *Document) []*SchemaValidationError {
+	apiVersion := SobekObjectGetString(document.Object, "apiVersion")
+	kind := SobekObjectGetString(document.Object, "kind")
+
+	if apiVersion == nil || kind == nil {
+		return nil
+	}
+
+	schemas := context.getCRDSchemas()
+	if !schemas.Contains(*apiVersion, *kind) {
+		return nil
+	}
+
+	validationErrors, _ := schemas.Validate(*apiVersion, *kind, context.DocumentToMap(document))
+
+	return newSchemaValidationErrors(validationErrors)
+}
+
+func (context *BuildContext) getCRDSchemas() *k8sschema.CRDSchemas {
+	if context.crdSchemas != nil {
+		return context.crdSchemas
+	}
+
+	schemas := k8sschema.NewCRDSchemas()
+	cluster := context.BuilderOptions.KubernetesCluster
+
+	for _, path := range cluster.SchemaFiles {
+		if err := addCRDSchemasFromFile(schemas, path); err != nil {
+			js.Throw(err)
+		}
+	}
+
+	for _, resource := range cluster.AdditionalResources {
+		if resource.Schema == nil {
+			continue
+		}
+
+		schema, err := sobekObjectToMap(context.JsRuntime, resource.Schema)
+		if err != nil {
+			js.Throw(fmt.Errorf("can't convert schema of %s/%s: %w", resource.ApiVersion, resource.Kind, err))
+		}
+
+		if err := schemas.AddSchema(resource.ApiVersion, resource.Kind, schema); err != nil {
+			js.Throw(err)
+		}
+	}
+
+	for _, document := range context.GetAllDocuments() {
+		if !isCRD(document) {
+			continue
+		}
+
+		// Invalid CRDs are rejected by the API server, don't stop the build because of them.
+		if err := schemas.AddCRD(context.DocumentToMap(document)); err != nil {
+			slog.Warn("Skipping schema of CRD ${path}: ${error}", slog.String("path", document.FullPath()), slog.String("error", err.Error()))
+		}
+	}
+
+	context.crdSchemas = schemas
+
+	return schemas
+}
+
+// Adds the CRDs in the given YAML file. The file may contain multiple CRD documents or a list of CRDs,
+// e.g. the output of "kubectl get crds -o yaml".
+func addCRDSchemasFromFile(schemas *k8sschema.CRDSchemas, path string) error {
+	objects, err := util.ReadYamlObjects(path)
+	if err != nil {
+		return fmt.Errorf("can't read schema file %s: %w", path, err)
+	}
+
+	for _, object := range objects {
+		if object["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+
+		if err := schemas.AddCRD(object); err != nil {
+			return fmt.Errorf("invalid CRD in schema file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func isCRD(document *Document) bool {
+	apiVersion := SobekObjectGetString(document.Object, "apiVersion")
+	kind := SobekObjectGetString(document.Object, "kind")
+
+	return apiVersion != nil && *apiVersion == "apiextensions.k8s.io/v1" && kind != nil && *kind == "CustomResourceDefinition"
+}
+
+// Converts the document into a map so that the values are the same as the ones written into the manifests,
+// e.g. for validating the document in Go.
+func (context *BuildContext) DocumentToMap(document *Document) map[string]any {
+	object, err := sobekObjectToMap(context.JsRuntime, document.Object)
+	if err != nil {
+		js.Throw(fmt.Errorf("can't convert document %s: %w", document.FullPath(), err))
+	}
+
+	return object
+}
+
+func sobekObjectToMap(jsRuntime *js.JsRuntime, sobekObject *sobek.Object) (map[string]any, error) {
+	content, err := SerializeSobekObjectToYaml(jsRuntime, sobekObject)
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]any{}
+	if err := yaml.Unmarshal([]byte(content), &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func newSchemaValidationErrors(validationErrors []*k8sschema.ValidationError) []*SchemaValidationError {
+	result := []*SchemaValidationError{}
+
+	for _, validationError := range validationErrors {
+		result = append(result, &SchemaValidationError{
+			FieldPath: formatFieldPath(validationError.Path),
+			Type:      string(validationError.Type),
+			Message:   validationError.Message,
+		})
+	}
+
+	return result
+}
+
+// Formats the given path segments as a field path, e.g. "spec.containers[0].image".
+func formatFieldPath(path []any) string {
+	result := ""
+
+	for _, segment := range path {
+		switch segment := segment.(type) {
+		case int:
+			result = fmt.Sprintf("%s[%d]", result, segment)
+		default:
+			result = joinFieldPath(result, fmt.Sprint(segment))
+		}
+	}
+
+	return result
+}
+
+func registerSchemaValidation(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[SchemaValidationError]()).JsModule(
+		"buildContext",
+	).Fields(
+		js.Field("FieldPath"),
+		js.Field("Type"),
+		js.Field("Message"),
+	)
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func TestGetSchemaFallbackVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  *semver.Version
+		expected string
+	}{
+		{name: "default version", version: nil, expected: ""},
+		{name: "embedded version", version: semver.MustParse("1.34.2"), expected: ""},
+		{name: "pinned version without schema", version: semver.MustParse("1.35"), expected: "1.34"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := &BuilderOptions{
+				KubernetesCluster:   NewKubernetesCluster(test.version, KubernetesDistributionUnknown),
+				OutputConfiguration: &OutputConfiguration{OutputPath: t.TempDir()},
+			}
+
+			NewEmptyBuilder(nil, js.NewJsRuntime()).sanitizeBuilderOptions(options)
+
+			context := &BuildContext{BuilderOptions: options}
+
+			fallbackVersion := ""
+			if version := context.GetSchemaFallbackVersion(); version != nil {
+				fallbackVersion = *version
+			}
+
+			if fallbackVersion != test.expected {
+				t.Errorf("expected fallback version %q, got %q", test.expected, fallbackVersion)
+			}
+		})
+	}
+}
@@ -13,6 +13,8 @@ import (
 )
 
 func SerializeSobekObjectToYaml(jsRuntime *js.JsRuntime, object *sobek.Object) (string, error) {
+	defer GetActiveProfiler(jsRuntime).StartSpan(ProfileCategorySerialization, "Serialize to YAML", nil)()
+
 	node, err := serializeSobekValueToYamlNode(jsRuntime, object)
 	if err != nil {
 		return "", err
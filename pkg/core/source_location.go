@@ -0,0 +1,233 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+	"github.com/ohayocorp/anemos/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceLocation points to a line and column in a source file. Line and column numbers start from 1,
+// zero values mean that the line or column is unknown.
+type SourceLocation struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// DocumentSource keeps where a document was created, i.e. the JavaScript call site that created it
+// and the locations of the YAML keys it was parsed from.
+type DocumentSource struct {
+	// Location of the JavaScript call that created the document.
+	CallSite *SourceLocation
+	// Name of the template the document was rendered from, e.g. "mychart/templates/deployment.yaml"
+	// for Helm charts. Empty for the documents that are created from the scripts.
+	Template string
+
+	fields map[string]*SourceLocation
+}
+
+// Returns the location in the form of "file:line:column", omitting the unknown parts.
+func (location *SourceLocation) String() string {
+	result := location.File
+
+	if location.Line > 0 {
+		result = fmt.Sprintf("%s:%d", result, location.Line)
+
+		if location.Column > 0 {
+			result = fmt.Sprintf("%s:%d", result, location.Column)
+		}
+	}
+
+	return result
+}
+
+// Returns a copy of the location whose file is relative to the given directory. Returns the location
+// itself if the file can't be made relative, e.g. for Helm templates.
+func (location *SourceLocation) RelativeTo(directory string) *SourceLocation {
+	if directory == "" || !filepath.IsAbs(location.File) {
+		return location
+	}
+
+	relativePath, err := filepath.Rel(directory, location.File)
+	if err != nil {
+		return location
+	}
+
+	return &SourceLocation{
+		File:   filepath.ToSlash(relativePath),
+		Line:   location.Line,
+		Column: location.Column,
+	}
+}
+
+// Returns the location of the given field path, e.g. "spec.template.spec.containers[0].image".
+// Falls back to the closest parent field that has a known location and then to the call site
+// if there is no such field. Returns nil if the location is unknown.
+func (source *DocumentSource) GetFieldLocation(fieldPath string) *SourceLocation {
+	for path := fieldPath; path != ""; path = parentFieldPath(path) {
+		if location, ok := source.fields[path]; ok {
+			return location
+		}
+	}
+
+	return source.CallSite
+}
+
+// Returns the paths of the fields that have a known location.
+func (source *DocumentSource) GetFieldPaths() []string {
+	return SortedKeys(source.fields)
+}
+
+// Returns the source of the document, i.e. where it was created. Returns nil if the document was
+// not created from YAML text or by a script, e.g. documents created by Go code.
+func (document *Document) GetSource() *DocumentSource {
+	return document.source
+}
+
+// Returns the path of the parent field by removing the last segment of the path,
+// e.g. "spec.containers[0]" for "spec.containers[0].image".
+func parentFieldPath(fieldPath string) string {
+	depth := 0
+
+	for i := len(fieldPath) - 1; i >= 0; i-- {
+		switch fieldPath[i] {
+		case ']':
+			depth++
+		case '[':
+			depth--
+			if depth == 0 {
+				return fieldPath[:i]
+			}
+		case '.':
+			if depth == 0 {
+				return fieldPath[:i]
+			}
+		}
+	}
+
+	return ""
+}
+
+// Returns the location of the first JavaScript frame in the call stack that belongs to a user script,
+// skipping the native frames and the Anemos library. Returns nil if there is no such frame.
+func getScriptCallSite(jsRuntime *js.JsRuntime) *SourceLocation {
+	if jsRuntime == nil {
+		return nil
+	}
+
+	for _, frame := range jsRuntime.GetStackTrace() {
+		fileName := frame.SrcName()
+		if fileName == "" || fileName == "<native>" {
+			continue
+		}
+
+		if strings.HasPrefix(fileName, js.PackageName) || strings.Contains(fileName, "node_modules") {
+			continue
+		}
+
+		position := frame.Position()
+
+		return &SourceLocation{
+			File:   filepath.Clean(fileName),
+			Line:   position.Line,
+			Column: position.Column,
+		}
+	}
+
+	return nil
+}
+
+// Collects the locations of the YAML keys while parsing a document. Locations are relative to the
+// original text, i.e. the offsets that are removed by dedenting and trimming are added back.
+type yamlLocations struct {
+	file         string
+	lineOffset   int
+	columnOffset int
+	fields       map[string]*SourceLocation
+}
+
+// Creates a collector for the given YAML text. The first line of the text is assumed to be on the
+// given line of the file, e.g. the line of the template literal in the script.
+func newYamlLocations(file string, firstLine int, yamlText string) *yamlLocations {
+	dedented := util.Dedent(yamlText)
+	trimmed := strings.TrimLeft(dedented, "\n")
+
+	locations := &yamlLocations{
+		file:       file,
+		lineOffset: firstLine - 1 + len(dedented) - len(trimmed),
+		fields:     map[string]*SourceLocation{},
+	}
+
+	// Dedent removes the same amount of indentation from each line, find it using the first non-empty line.
+	originalLines := strings.Split(yamlText, "\n")
+	dedentedLines := strings.Split(dedented, "\n")
+
+	for i, line := range dedentedLines {
+		if strings.TrimSpace(line) != "" && i < len(originalLines) {
+			locations.columnOffset = len(originalLines[i]) - len(line)
+			break
+		}
+	}
+
+	return locations
+}
+
+func (locations *yamlLocations) add(fieldPath string, node *yaml.Node) {
+	if locations == nil || fieldPath == "" {
+		return
+	}
+
+	// Keep the location of the key if the location of the value is added afterwards.
+	if _, ok := locations.fields[fieldPath]; ok {
+		return
+	}
+
+	locations.fields[fieldPath] = &SourceLocation{
+		File:   locations.file,
+		Line:   node.Line + locations.lineOffset,
+		Column: node.Column + locations.columnOffset,
+	}
+}
+
+func (locations *yamlLocations) fieldPath(parent string, key string) string {
+	if locations == nil {
+		return ""
+	}
+
+	return joinFieldPath(parent, key)
+}
+
+func (locations *yamlLocations) indexPath(parent string, index int) string {
+	if locations == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+func registerSourceLocation(jsRuntime *js.JsRuntime) {
+	jsRuntime.Type(reflect.TypeFor[SourceLocation]()).JsModule(
+		"document",
+	).Fields(
+		js.Field("File"),
+		js.Field("Line"),
+		js.Field("Column"),
+	).Methods(
+		js.Method("String").JsName("toString"),
+	)
+
+	jsRuntime.Type(reflect.TypeFor[DocumentSource]()).JsModule(
+		"document",
+	).Fields(
+		js.Field("CallSite"),
+		js.Field("Template"),
+	).Methods(
+		js.Method("GetFieldLocation"),
+		js.Method("GetFieldPaths"),
+	)
+}
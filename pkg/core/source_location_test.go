@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/js"
+)
+
+func TestParseDocumentWithSource(t *testing.T) {
+	yamlText := `
+		apiVersion: v1
+		kind: ConfigMap
+		metadata:
+		  name: config
+		data:
+		  items:
+		    - first
+		    - second
+		`
+
+	document, err := parseDocumentWithSource(js.NewJsRuntime(), yamlText, "chart/templates/configmap.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := document.GetSource()
+
+	tests := []struct {
+		fieldPath string
+		expected  string
+	}{
+		{fieldPath: "kind", expected: "chart/templates/configmap.yaml:3:3"},
+		{fieldPath: "metadata.name", expected: "chart/templates/configmap.yaml:5:5"},
+		{fieldPath: "data.items[1]", expected: "chart/templates/configmap.yaml:9:9"},
+		{fieldPath: "metadata.labels.app", expected: "chart/templates/configmap.yaml:4:3"},
+	}
+
+	for _, test := range tests {
+		location := source.GetFieldLocation(test.fieldPath)
+		if location == nil {
+			t.Errorf("expected location for %s", test.fieldPath)
+			continue
+		}
+
+		if location.String() != test.expected {
+			t.Errorf("expected location of %s to be %s, got %s", test.fieldPath, test.expected, location)
+		}
+	}
+}
+
+func TestParentFieldPath(t *testing.T) {
+	tests := map[string]string{
+		"spec.containers[0].image":  "spec.containers[0]",
+		"spec.containers[0]":        "spec.containers",
+		`metadata.labels["app.io"]`: "metadata.labels",
+		"kind":                      "",
+	}
+
+	for fieldPath, expected := range tests {
+		if actual := parentFieldPath(fieldPath); actual != expected {
+			t.Errorf("expected parent of %s to be %s, got %s", fieldPath, expected, actual)
+		}
+	}
+}
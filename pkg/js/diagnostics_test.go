@@ -0,0 +1,36 @@
+package js_test
+
+import (
+	"testing"
+
+	"github.com/ohayocorp/anemos/pkg/cmd"
+)
+
+// Runs the given script that builds documents and checks the diagnostics without writing any output.
+func runDiagnosticsScript(t *testing.T, path string) {
+	t.Helper()
+
+	jsRuntime, err := cmd.InitializeNewRuntime(&cmd.AnemosProgram{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsRuntime.BuilderDefaultsContext.Set("test", true)
+
+	err = jsRuntime.Run(ReadScript(t, path), nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPodSecurityDiagnostics(t *testing.T) {
+	runDiagnosticsScript(t, "tests/diagnostics-pod-security.js")
+}
+
+func TestMissingReferencesDiagnostics(t *testing.T) {
+	runDiagnosticsScript(t, "tests/diagnostics-missing-references.js")
+}
+
+func TestMissingNamespacesDiagnostics(t *testing.T) {
+	runDiagnosticsScript(t, "tests/diagnostics-missing-namespaces.js")
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -17,6 +16,7 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/grafana/sobek"
 	"github.com/ohayocorp/anemos/pkg"
+	"github.com/ohayocorp/anemos/pkg/remote"
 	"github.com/ohayocorp/anemos/pkg/util"
 	"github.com/ohayocorp/sobek_nodejs/console"
 	"github.com/ohayocorp/sobek_nodejs/process"
@@ -31,6 +31,9 @@ type JsRuntime struct {
 	Runtime                *sobek.Runtime
 	BuilderDefaultsContext *sobek.Object
 	EmbeddedModules        []*EmbeddedModule
+	// Fetches remote modules and charts through the cache and the lockfile. Remote resources are
+	// downloaded directly if it is nil.
+	Fetcher                *remote.Fetcher
 	variableRegistrations  []*VariableRegistration
 	functionRegistrations  []*FunctionRegistration
 	typeRegistrations      map[reflect.Type]*TypeRegistration
@@ -140,14 +143,14 @@ func pathResolver(jsRuntime *JsRuntime, base, path string) string {
 
 func SourceLoader(jsRuntime *JsRuntime, path string) ([]byte, error) {
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		// Download the file from the URL.
-		response, err := http.Get(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to download file from %s: %w", path, err)
-		}
-		defer response.Body.Close()
+		return FetchRemote(jsRuntime, &remote.LockEntry{Url: path, Kind: remote.KindModule}, func(resolved string) (string, []byte, error) {
+			if resolved == "" {
+				resolved = path
+			}
 
-		return io.ReadAll(response.Body)
+			data, err := remote.DownloadUrl(resolved)
+			return resolved, data, err
+		})
 	}
 
 	for _, module := range jsRuntime.EmbeddedModules {
@@ -209,6 +212,17 @@ func SourceLoader(jsRuntime *JsRuntime, path string) ([]byte, error) {
 	return require.DefaultSourceLoader(path)
 }
 
+// Fetches the given remote resource using the fetcher of the runtime, or downloads it directly if
+// the runtime doesn't have a fetcher.
+func FetchRemote(jsRuntime *JsRuntime, resource *remote.LockEntry, download remote.DownloadFunc) ([]byte, error) {
+	if jsRuntime.Fetcher == nil {
+		_, data, err := download("")
+		return data, err
+	}
+
+	return jsRuntime.Fetcher.Fetch(resource, download)
+}
+
 func NewJsRuntime() *JsRuntime {
 	runtime := sobek.New()
 
@@ -0,0 +1,53 @@
+'use strict';
+
+const assert = require("./assert.js");
+const anemos = require("@ohayocorp/anemos");
+
+const addDocuments = builder => {
+    builder.addDocument(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: apps
+`);
+
+    builder.addDocument(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster-settings
+  namespace: kube-system
+`);
+};
+
+// Namespace documents are not checked by default since the namespaces usually exist in the cluster.
+const defaultBuilder = new anemos.Builder();
+addDocuments(defaultBuilder);
+
+defaultBuilder.onStep(anemos.steps.report, context => {
+    const diagnostics = context.getAllDiagnostics().filter(diagnostic => diagnostic.metadata.id === "missing-namespace-documents");
+    assert.equal(diagnostics.length, 0);
+});
+
+defaultBuilder.build();
+
+const builder = new anemos.Builder();
+anemos.diagnostics.missingNamespaces.add(builder, { checkNamespaceDocuments: true });
+addDocuments(builder);
+
+builder.onStep(anemos.steps.report, context => {
+    const diagnostics = context.getAllDiagnostics().filter(diagnostic => diagnostic.metadata.id === "missing-namespace-documents");
+
+    // System namespaces are not reported.
+    assert.equal(diagnostics.length, 1);
+    assert.equal(diagnostics[0].document.metadata.name, "settings");
+    assert.ok(diagnostics[0].fix);
+
+    context.fixDiagnostic(diagnostics[0]);
+
+    const namespace = context.getDocument(document => document.isNamespace() && document.metadata.name === "apps");
+    assert.ok(namespace);
+});
+
+builder.build();
@@ -0,0 +1,112 @@
+'use strict';
+
+const assert = require("./assert.js");
+const anemos = require("@ohayocorp/anemos");
+
+const builder = new anemos.Builder();
+anemos.diagnostics.missingReferences.add(builder);
+
+builder.addDocument(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: apps
+data:
+  level: debug
+`);
+
+builder.addDocument(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: apps
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          image: api
+          env:
+            - name: LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: settings
+                  key: format
+            - name: REGION
+              valueFrom:
+                configMapKeyRef:
+                  name: region
+                  key: region
+            - name: OPTIONAL
+              valueFrom:
+                secretKeyRef:
+                  name: optional
+                  key: value
+                  optional: true
+          envFrom:
+            - secretRef:
+                name: credentials
+      volumes:
+        - name: certificates
+          secret:
+            secretName: certificates
+`);
+
+builder.addDocument(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: worker
+  namespace: apps
+spec:
+  imagePullSecrets:
+    - name: registry
+  containers:
+    - name: worker
+      image: worker
+`);
+
+builder.addDocument(`
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: apps
+spec:
+  schedule: "0 0 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              image: backup
+              env:
+                - name: BUCKET
+                  valueFrom:
+                    secretKeyRef:
+                      name: bucket
+                      key: name
+          volumes:
+            - name: settings
+              configMap:
+                name: settings
+`);
+
+builder.onStep(anemos.steps.report, context => {
+    const diagnostics = context.getAllDiagnostics().filter(diagnostic => diagnostic.metadata.id === "missing-config-reference");
+    const fieldPaths = diagnostics.map(diagnostic => `${diagnostic.document.metadata.name}:${diagnostic.fieldPath}`).sort();
+
+    assert.deepEqual(fieldPaths, [
+        "api:spec.template.spec.containers[0].env[0].valueFrom.configMapKeyRef.key",
+        "api:spec.template.spec.containers[0].env[1].valueFrom.configMapKeyRef.name",
+        "api:spec.template.spec.containers[0].envFrom[0].secretRef.name",
+        "api:spec.template.spec.volumes[0].secret.secretName",
+        "backup:spec.jobTemplate.spec.template.spec.containers[0].env[0].valueFrom.secretKeyRef.name",
+        "worker:spec.imagePullSecrets[0].name",
+    ]);
+});
+
+builder.build();
@@ -0,0 +1,116 @@
+'use strict';
+
+const assert = require("./assert.js");
+const anemos = require("@ohayocorp/anemos");
+
+const builder = new anemos.Builder();
+anemos.diagnostics.podSecurity.add(builder, { level: "restricted" });
+
+builder.addDocument(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: legacy
+  labels:
+    pod-security.kubernetes.io/enforce: privileged
+`);
+
+builder.addDocument(`
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cni
+  namespace: kube-system
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: agent
+          image: cni
+          securityContext:
+            privileged: true
+`);
+
+builder.addDocument(`
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: apps
+spec:
+  schedule: "0 0 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          hostPID: true
+          containers:
+            - name: backup
+              image: backup
+`);
+
+builder.addDocument(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: legacy
+  namespace: legacy
+spec:
+  template:
+    spec:
+      hostIPC: true
+      containers:
+        - name: legacy
+          image: legacy
+`);
+
+builder.onStep(anemos.steps.report, context => {
+    const diagnostics = context.getAllDiagnostics().filter(diagnostic => diagnostic.metadata.id.startsWith("pod-security-"));
+
+    const find = (id, name) => diagnostics.filter(diagnostic =>
+        diagnostic.metadata.id === id && diagnostic.document.metadata.name === name);
+
+    // Controls that change what the workload can do are only reported.
+    const hostNetwork = find("pod-security-host-namespaces", "cni");
+    assert.equal(hostNetwork.length, 1);
+    assert.equal(hostNetwork[0].fieldPath, "spec.template.spec.hostNetwork");
+    assert.notOk(hostNetwork[0].fix);
+
+    const privileged = find("pod-security-privileged", "cni");
+    assert.equal(privileged.length, 1);
+    assert.equal(privileged[0].fieldPath, "spec.template.spec.containers[0].securityContext.privileged");
+    assert.notOk(privileged[0].fix);
+
+    const privilegeEscalation = find("pod-security-privilege-escalation", "cni");
+    assert.equal(privilegeEscalation.length, 1);
+    assert.notOk(privilegeEscalation[0].fix);
+
+    // Pod templates of CronJobs are in the job templates.
+    const hostPID = find("pod-security-host-namespaces", "backup");
+    assert.equal(hostPID.length, 1);
+    assert.equal(hostPID[0].fieldPath, "spec.jobTemplate.spec.template.spec.hostPID");
+
+    // Namespaces with the enforce label are checked with the level in the label.
+    assert.equal(diagnostics.filter(diagnostic => diagnostic.document.metadata.name === "legacy").length, 0);
+
+    // Additive fixes are applied, the reported fields are kept as is.
+    for (const id of ["pod-security-seccomp-required", "pod-security-drop-capabilities", "pod-security-run-as-non-root"]) {
+        const fixable = find(id, "backup");
+        assert.equal(fixable.length, 1, id);
+        assert.ok(fixable[0].fix, id);
+
+        context.fixDiagnostic(fixable[0]);
+    }
+
+    const cronJob = context.getDocument(document => document.metadata.name === "backup");
+    const podSpec = cronJob.spec.jobTemplate.spec.template.spec;
+    const securityContext = podSpec.containers[0].securityContext;
+
+    assert.equal(securityContext.seccompProfile.type, "RuntimeDefault");
+    assert.deepEqual(securityContext.capabilities.drop, ["ALL"]);
+    assert.equal(securityContext.runAsNonRoot, true);
+    assert.equal(podSpec.hostPID, true);
+});
+
+builder.build();
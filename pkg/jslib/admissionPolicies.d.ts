@@ -0,0 +1,40 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that evaluates the ValidatingAdmissionPolicies and ValidatingAdmissionPolicyBindings
+         * in the build against the documents during the {@link steps.diagnose} step, the same way the API server
+         * evaluates them on admission. Violations are added as diagnostics with the policy category. The severity is
+         * derived from the validation actions of the binding: Deny is an error, Warn is a warning and Audit is info.
+         */
+        checkAdmissionPolicies(): Component;
+
+        /**
+         * Adds a {@link Component} that evaluates the ValidatingAdmissionPolicies and ValidatingAdmissionPolicyBindings
+         * in the build and in the given files against the documents during the {@link steps.diagnose} step, the same
+         * way the API server evaluates them on admission. Violations are added as diagnostics with the policy category.
+         * The severity is derived from the validation actions of the binding: Deny is an error, Warn is a warning and
+         * Audit is info.
+         * @param options Options for the admission policies.
+         */
+        checkAdmissionPolicies(options: admissionPolicies.Options): Component;
+    }
+}
+
+export declare namespace admissionPolicies {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+        constructor(files: string[]);
+
+        /**
+         * YAML files that contain ValidatingAdmissionPolicy and ValidatingAdmissionPolicyBinding objects, e.g. the
+         * policies that are already installed in the cluster. Policies in these files are used only for evaluation
+         * and are not added to the output.
+         */
+        files?: string[];
+    }
+}
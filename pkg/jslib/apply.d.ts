@@ -25,5 +25,23 @@ export declare namespace apply {
 
         /** Forcefully apply changes even if there are conflicts on server side apply. */
         forceConflicts?: boolean;
+
+        /**
+         * Maximum number of provisioners that run at the same time. Provisioners only run concurrently when
+         * they don't depend on each other and {@link skipConfirmation} is set. Defaults to 4.
+         */
+        maxConcurrency?: number;
+
+        /** Maximum number of applied revisions that are kept for each apply set. Defaults to 10. */
+        revisionHistoryLimit?: number;
+
+        /**
+         * Only compute and print the changes for each document group using server-side dry-run.
+         * Nothing is applied to the cluster.
+         */
+        diffOnly?: boolean;
+
+        /** Called with the total number of changes after the changes are computed when {@link diffOnly} is set. */
+        onDiffCompleted?: (numberOfChanges: number) => void;
     }
 }
\ No newline at end of file
@@ -0,0 +1,26 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that applies the fixes of the diagnostics to the documents right before the
+         * {@link steps.report} step and writes the applied fixes to the `fixes.md` report. Fixed diagnostics are
+         * neither reported nor fail the build.
+         * @param options Options for applying the fixes.
+         */
+        applyFixes(options?: applyFixes.Options): Component;
+    }
+}
+
+export declare namespace applyFixes {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+        constructor(ids: string[]);
+
+        /** Only applies the fixes of the diagnostics with these ids. Fixes of all diagnostics are applied if empty. */
+        ids?: string[];
+    }
+}
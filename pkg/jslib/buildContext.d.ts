@@ -1,8 +1,9 @@
 import { Document, NewDocumentOptions } from "./document";
 import { Component } from "./component";
 import { BuilderOptions } from "./builderOptions";
-import { Diagnostic } from "./diagnostic";
+import { Diagnostic, DiagnosticSuppression } from "./diagnostic";
 import { Report } from "./report";
+import { Builder, MutationLogEntry } from "./builder";
 import { KubernetesResourceInfo } from "./kubernetesResourceInfo";
 import { DocumentGroup, AdditionalFile } from "./documentGroup";
 
@@ -74,7 +75,10 @@ export declare class BuildContext {
     /** Returns the first document that has the given path. Returns null if no document is found. */
     getDocument(path: string): Document | null;
 
-    /** Adds a diagnostic to the build context. */
+    /**
+     * Adds a diagnostic to the build context. Diagnostics that match a suppression are added to the suppressed
+     * diagnostics instead.
+     */
     addDiagnostic(diagnostic: Diagnostic): void;
 
     /** Adds a report to the build context. */
@@ -83,12 +87,69 @@ export declare class BuildContext {
     /** Returns all diagnostics added to the build context. */
     getAllDiagnostics(): Diagnostic[];
 
+    /** Returns the diagnostics that are suppressed by annotations, builder suppressions or baselines. */
+    getSuppressedDiagnostics(): Diagnostic[];
+
+    /** Suppresses the given diagnostic, i.e. moves it from the diagnostics to the suppressed diagnostics. */
+    suppressDiagnostic(diagnostic: Diagnostic, suppression: DiagnosticSuppression): void;
+
+    /**
+     * Applies the fix of the given diagnostic and moves the diagnostic to the fixed diagnostics so that it is neither
+     * reported nor fails the build.
+     */
+    fixDiagnostic(diagnostic: Diagnostic): void;
+
+    /** Returns the diagnostics whose fixes are applied. */
+    getFixedDiagnostics(): Diagnostic[];
+
+    /**
+     * Validates the document against the OpenAPI schema of its kind for the Kubernetes version of the build. Uses the
+     * schemas that are embedded into Anemos, so it doesn't require access to a cluster. Returns null if there is no
+     * schema for the kind of the document.
+     */
+    validateDocumentSchema(document: Document): SchemaValidationError[] | null;
+
+    /**
+     * Returns the version of the embedded OpenAPI schema that the documents are validated against if there is no
+     * embedded schema for the Kubernetes version of the build, e.g. "1.34". Returns null if the schema of the
+     * Kubernetes version of the build is used or if the version is not specified in the builder options.
+     */
+    getSchemaFallbackVersion(): string | null;
+
+    /**
+     * Validates the custom resource against the schema of its CRD, including the enum, pattern and required fields
+     * and the `x-kubernetes-validations` rules. Schemas are collected from the CRDs in the build, the additional
+     * resources of the Kubernetes cluster that have a schema and the schema files of the Kubernetes cluster.
+     * Returns null if there is no schema for the kind of the document.
+     *
+     * The schemas are collected the first time this method is called, i.e. the CRDs that are added to the build
+     * afterwards are not taken into account.
+     */
+    validateCustomResource(document: Document): SchemaValidationError[] | null;
+
     /** Returns all reports added to the build context. */
     getAllReports(): Report[];
 
+    /**
+     * Returns the changes the component actions made to the documents so far in the order the actions ran.
+     * Returns an empty array if the mutation log is not enabled, see {@link Builder.logMutations}.
+     */
+    getMutationLog(): MutationLogEntry[];
+
     /** Returns true if the target environment is development. */
     isDevelopment(): boolean;
 
     /** Returns true if the target environment is production. */
     isProduction(): boolean;
 }
+
+/** Describes a field of a document that doesn't conform to the schema of its kind. */
+export declare class SchemaValidationError {
+    /** Path of the field, e.g. "spec.template.spec.containers[0].imagePullPolicy". */
+    fieldPath: string;
+
+    /** Type of the error: "unknown-field", "wrong-type", "missing-required", "invalid-value" or "rule-violation". */
+    type: string;
+
+    message: string;
+}
@@ -1,10 +1,12 @@
 import { Component } from "./component";
 import { BuildContext } from "./buildContext";
 import { BuilderOptions, Version } from "./builderOptions";
-import { Document, NewDocumentOptions } from "./document";
+import { DiagnosticSuppression, suppressDiagnosticsAnnotation } from "./diagnostic";
+import { Document, NewDocumentOptions, ProvenanceEntry } from "./document";
 import { DocumentGroup, AdditionalFile } from "./documentGroup";
 import { EnvironmentType } from "./environmentType";
 import { KubernetesDistribution } from "./kubernetesDistribution";
+import { Profiler } from "./profiler";
 import { Step } from "./step";
 import * as steps from "./steps";
 
@@ -24,6 +26,43 @@ export declare class Builder {
     /** Runs all the components that were added to the builder. */
     build(): void;
 
+    /**
+     * Enables measuring the time spent in each action of the components and returns the profiler.
+     * Calling it more than once returns the same profiler.
+     */
+    enableProfiling(): Profiler;
+
+    /** Returns the profiler of the builder, or null if profiling is not enabled. */
+    getProfiler(): Profiler | null;
+
+    /**
+     * Enables recording the components that modify each document. Components that create the documents are always
+     * recorded, but detecting modifications requires comparing the documents after every action.
+     */
+    enableProvenanceTracking(): void;
+
+    /**
+     * Enables recording the changes each component action makes to the documents as JSON Patch operations.
+     * Also enables provenance tracking since both require comparing the documents after every action.
+     */
+    enableMutationLog(): void;
+
+    /**
+     * Returns the changes the component actions made to the documents in the order the actions ran. Returns an
+     * empty array if the mutation log is not enabled.
+     */
+    getMutationLog(): MutationLogEntry[];
+
+    /**
+     * Suppresses the diagnostics that match the given suppression, e.g.
+     * `builder.suppressDiagnostics({ ids: ["run-as-root"], component: legacyChart, reason: "Will be fixed in v2" })`.
+     * A single document can also be suppressed with the {@link suppressDiagnosticsAnnotation} annotation.
+     */
+    suppressDiagnostics(suppression: DiagnosticSuppression): void;
+
+    /** Suppresses the diagnostics with the given ids in all documents. */
+    suppressDiagnostics(ids: string[]): void;
+
     /** Adds given component to the list of components. */
     addComponent(component: Component): void;
 
@@ -139,4 +178,28 @@ export declare class Builder {
      * and adds it to the list of components.
      */
     onSpecifyProvisionerDependencies(callback: (context: BuildContext) => void): Component;
-}
\ No newline at end of file
+}
+
+/** Contains the changes a component action made to a single document. */
+export declare class MutationLogEntry {
+    /** Component action that made the changes. */
+    component: ProvenanceEntry;
+
+    /** Path of the document at the time of the action. */
+    documentPath: string;
+
+    /** RFC 6902 JSON Patch operations that transform the document from its state before the action to its state after it. */
+    operations: JsonPatchOperation[];
+}
+
+/** A single RFC 6902 JSON Patch operation. */
+export declare class JsonPatchOperation {
+    /** One of "add", "remove" or "replace". */
+    op: string;
+
+    /** JSON Pointer to the changed value. */
+    path: string;
+
+    /** Value of the add and replace operations. */
+    value?: any;
+}
@@ -32,6 +32,12 @@ export declare class KubernetesCluster {
     distribution: KubernetesDistribution
     version: Version
     additionalResources?: KubernetesResource[]
+
+    /**
+     * Paths of the YAML files that contain the CRDs of the cluster, e.g. the output of `kubectl get crds -o yaml`.
+     * Custom resources are validated against the schemas of these CRDs.
+     */
+    schemaFiles?: string[]
 }
 
 /**
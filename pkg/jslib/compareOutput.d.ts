@@ -0,0 +1,25 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that compares the generated documents with the manifests of the previous build
+         * during the {@link steps.report} step. Resources are matched by their apiVersion, kind, namespace and name
+         * instead of their file paths. Added, removed and modified resources are printed and written as a report.
+         * @param options Options for comparing the output.
+         */
+        compareOutput(options?: compareOutput.Options): Component;
+    }
+}
+
+export declare namespace compareOutput {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+
+        /** Directory that contains the manifests of the previous build. Defaults to the manifests directory under the output path. */
+        previousManifestsPath?: string;
+    }
+}
@@ -1,6 +1,7 @@
 export * as collectCRDs from "./collectCRDs";
 export * as collectNamespaces from "./collectNamespaces";
 export * as createReferencedNamespaces from "./createReferencedNamespaces";
+export * as migrateApis from "./migrateApis";
 export * as overrideEnvironmentVariables from "./overrideEnvironmentVariables";
 export * as recreateOnImmutableFieldChange from "./recreateOnImmutableFieldChange";
 export * as setAnnotations from "./setAnnotations";
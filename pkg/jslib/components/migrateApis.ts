@@ -0,0 +1,367 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import { Document } from "@ohayocorp/anemos/document";
+import * as steps from "@ohayocorp/anemos/steps";
+
+export type Predicate = (context: BuildContext, document: Document) => boolean;
+
+export const componentType = "migrate-apis";
+
+/**
+ * Describes how to migrate the documents of the given kinds from a deprecated API version. The target API version
+ * is the replacement that is returned by `kubernetesResourceInfo.getApiDeprecation`.
+ */
+export type Conversion = {
+    apiVersion: string;
+    kinds: string[];
+
+    /** Converts the fields that are changed between the API versions. Only the API version is changed if not set. */
+    convert?: (document: Document) => void;
+};
+
+/** Known conversions from the deprecated API versions. */
+export const conversions: Conversion[] = [
+    {
+        apiVersion: "extensions/v1beta1",
+        kinds: ["DaemonSet", "Deployment", "ReplicaSet"],
+        convert: document => setDefaultSelector(document),
+    },
+    {
+        apiVersion: "apps/v1beta1",
+        kinds: ["Deployment", "StatefulSet"],
+        convert: document => setDefaultSelector(document),
+    },
+    {
+        apiVersion: "apps/v1beta2",
+        kinds: ["DaemonSet", "Deployment", "ReplicaSet", "StatefulSet"],
+    },
+    {
+        apiVersion: "extensions/v1beta1",
+        kinds: ["NetworkPolicy"],
+    },
+    {
+        apiVersion: "extensions/v1beta1",
+        kinds: ["Ingress"],
+        convert: document => convertIngress(document),
+    },
+    {
+        apiVersion: "networking.k8s.io/v1beta1",
+        kinds: ["Ingress"],
+        convert: document => convertIngress(document),
+    },
+    {
+        apiVersion: "networking.k8s.io/v1beta1",
+        kinds: ["IngressClass"],
+    },
+    {
+        apiVersion: "rbac.authorization.k8s.io/v1beta1",
+        kinds: ["ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"],
+    },
+    {
+        apiVersion: "scheduling.k8s.io/v1beta1",
+        kinds: ["PriorityClass"],
+    },
+    {
+        apiVersion: "storage.k8s.io/v1beta1",
+        kinds: ["CSIDriver", "CSINode", "CSIStorageCapacity", "StorageClass", "VolumeAttachment"],
+    },
+    {
+        apiVersion: "coordination.k8s.io/v1beta1",
+        kinds: ["Lease"],
+    },
+    {
+        apiVersion: "batch/v1beta1",
+        kinds: ["CronJob"],
+    },
+    {
+        apiVersion: "discovery.k8s.io/v1beta1",
+        kinds: ["EndpointSlice"],
+        convert: document => convertEndpointSlice(document),
+    },
+    {
+        apiVersion: "events.k8s.io/v1beta1",
+        kinds: ["Event"],
+    },
+    {
+        apiVersion: "autoscaling/v2beta1",
+        kinds: ["HorizontalPodAutoscaler"],
+        convert: document => convertHorizontalPodAutoscaler(document),
+    },
+    {
+        apiVersion: "autoscaling/v2beta2",
+        kinds: ["HorizontalPodAutoscaler"],
+    },
+    {
+        apiVersion: "policy/v1beta1",
+        kinds: ["PodDisruptionBudget"],
+    },
+    {
+        apiVersion: "node.k8s.io/v1beta1",
+        kinds: ["RuntimeClass"],
+    },
+    {
+        apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1",
+        kinds: ["FlowSchema", "PriorityLevelConfiguration"],
+        convert: document => convertPriorityLevelConfiguration(document),
+    },
+    {
+        apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2",
+        kinds: ["FlowSchema", "PriorityLevelConfiguration"],
+        convert: document => convertPriorityLevelConfiguration(document),
+    },
+    {
+        apiVersion: "flowcontrol.apiserver.k8s.io/v1beta3",
+        kinds: ["FlowSchema", "PriorityLevelConfiguration"],
+    },
+];
+
+export class Options {
+    /**
+     * Predicate to filter which documents to migrate. All documents will be migrated if not specified.
+     */
+    predicate?: Predicate;
+}
+
+export class Component extends AnemosComponent {
+    options: Options;
+
+    constructor(options?: Options) {
+        super();
+
+        this.options = options ?? {};
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.modify, this.modify);
+    }
+
+    modify = (context: BuildContext) => {
+        for (const document of context.getAllDocuments()) {
+            if (this.options.predicate && !this.options.predicate(context, document)) {
+                continue;
+            }
+
+            migrate(context, document);
+        }
+    }
+}
+
+/**
+ * Returns the conversion that migrates the given document to an API version that the target cluster serves,
+ * undefined if the API version of the document is not deprecated or there is no known conversion for it.
+ */
+export function getConversion(context: BuildContext, document: Document): Conversion | undefined {
+    const apiVersion = document.apiVersion;
+    const kind = document.kind;
+
+    if (!apiVersion || !kind || getTargetApiVersion(context, apiVersion, kind) === undefined) {
+        return undefined;
+    }
+
+    return conversions.find(conversion => conversion.apiVersion === apiVersion && conversion.kinds.includes(kind));
+}
+
+/**
+ * Migrates the given document to an API version that the target cluster serves if there is a known conversion
+ * for the API version of the document. Returns the new API version, undefined if the document is not migrated.
+ */
+export function migrate(context: BuildContext, document: Document): string | undefined {
+    const conversion = getConversion(context, document);
+    if (!conversion) {
+        return undefined;
+    }
+
+    const targetApiVersion = getTargetApiVersion(context, document.apiVersion!, document.kind!)!;
+
+    conversion.convert?.(document);
+    document.apiVersion = targetApiVersion;
+
+    return targetApiVersion;
+}
+
+// Returns the replacement of the deprecated API version if the target cluster serves it.
+function getTargetApiVersion(context: BuildContext, apiVersion: string, kind: string): string | undefined {
+    const replacement = context.kubernetesResourceInfo.getApiDeprecation(apiVersion, kind)?.replacement;
+    if (!replacement || !context.kubernetesResourceInfo.contains(replacement, kind)) {
+        return undefined;
+    }
+
+    return replacement;
+}
+
+// Older API versions defaulted the selector to the labels of the pod template, apps/v1 requires it.
+function setDefaultSelector(document: Document) {
+    const spec = document.spec;
+    const labels = spec?.template?.metadata?.labels;
+
+    if (!spec || spec.selector || !labels) {
+        return;
+    }
+
+    spec.selector = {
+        matchLabels: { ...labels },
+    };
+}
+
+function convertIngress(document: Document) {
+    const spec = document.spec;
+    if (!spec) {
+        return;
+    }
+
+    if (spec.backend) {
+        spec.defaultBackend = convertIngressBackend(spec.backend);
+        delete spec.backend;
+    }
+
+    for (const rule of spec.rules ?? []) {
+        for (const path of rule.http?.paths ?? []) {
+            path.pathType ??= "ImplementationSpecific";
+            path.backend = convertIngressBackend(path.backend);
+        }
+    }
+}
+
+function convertIngressBackend(backend: any): any {
+    if (!backend || backend.serviceName === undefined) {
+        return backend;
+    }
+
+    const { serviceName, servicePort, ...rest } = backend;
+
+    const port = typeof servicePort === "number" || /^\d+$/.test(`${servicePort}`)
+        ? { number: Number(servicePort) }
+        : { name: servicePort };
+
+    return {
+        ...rest,
+        service: {
+            name: serviceName,
+            port: port,
+        },
+    };
+}
+
+function convertEndpointSlice(document: Document) {
+    for (const endpoint of document.endpoints ?? []) {
+        if (endpoint.topology) {
+            endpoint.deprecatedTopology = endpoint.topology;
+            delete endpoint.topology;
+        }
+    }
+}
+
+function convertPriorityLevelConfiguration(document: Document) {
+    const limited = document.spec?.limited;
+
+    if (limited?.assuredConcurrencyShares !== undefined) {
+        limited.nominalConcurrencyShares ??= limited.assuredConcurrencyShares;
+        delete limited.assuredConcurrencyShares;
+    }
+}
+
+function convertHorizontalPodAutoscaler(document: Document) {
+    const metrics: any[] = document.spec?.metrics;
+    if (!metrics) {
+        return;
+    }
+
+    document.spec.metrics = metrics.map(metric => {
+        switch (metric.type) {
+            case "Resource":
+            case "ContainerResource": {
+                const key = metric.type === "Resource" ? "resource" : "containerResource";
+                const { targetAverageUtilization, targetAverageValue, ...rest } = metric[key] ?? {};
+
+                return {
+                    ...metric,
+                    [key]: {
+                        ...rest,
+                        target: targetAverageUtilization !== undefined
+                            ? { type: "Utilization", averageUtilization: targetAverageUtilization }
+                            : { type: "AverageValue", averageValue: targetAverageValue },
+                    },
+                };
+            }
+            case "Pods": {
+                const { metricName, selector, targetAverageValue } = metric.pods ?? {};
+
+                return {
+                    ...metric,
+                    pods: {
+                        metric: { name: metricName, selector: selector },
+                        target: { type: "AverageValue", averageValue: targetAverageValue },
+                    },
+                };
+            }
+            case "Object": {
+                const { target, metricName, selector, targetValue, averageValue } = metric.object ?? {};
+
+                return {
+                    ...metric,
+                    object: {
+                        describedObject: target,
+                        metric: { name: metricName, selector: selector },
+                        target: averageValue !== undefined
+                            ? { type: "AverageValue", averageValue: averageValue }
+                            : { type: "Value", value: targetValue },
+                    },
+                };
+            }
+            case "External": {
+                const { metricName, metricSelector, targetValue, targetAverageValue } = metric.external ?? {};
+
+                return {
+                    ...metric,
+                    external: {
+                        metric: { name: metricName, selector: metricSelector },
+                        target: targetAverageValue !== undefined
+                            ? { type: "AverageValue", averageValue: targetAverageValue }
+                            : { type: "Value", value: targetValue },
+                    },
+                };
+            }
+            default:
+                return metric;
+        }
+    });
+}
+
+export function add(builder: Builder, options?: Options): Component {
+    const component = new Component(options);
+    builder.addComponent(component);
+
+    return component;
+}
+
+declare module "@ohayocorp/anemos" {
+    export interface Builder {
+        /**
+         * Migrates the documents that use deprecated API versions to the API versions that the target cluster
+         * serves, e.g. autoscaling/v2beta2 HorizontalPodAutoscalers to autoscaling/v2, during {@link steps.modify}.
+         * Only the known conversions are applied. It is possible to filter which documents to migrate by
+         * specifying a predicate.
+         */
+        migrateApis(options?: Options): Component;
+
+        /**
+         * Migrates the documents that use deprecated API versions to the API versions that the target cluster
+         * serves, e.g. autoscaling/v2beta2 HorizontalPodAutoscalers to autoscaling/v2, during {@link steps.modify}.
+         * Only the known conversions are applied. It is possible to filter which documents to migrate by
+         * specifying a predicate.
+         */
+        migrateApis(predicate?: Predicate): Component;
+    }
+}
+
+Builder.prototype.migrateApis = function (this: Builder, first?: Options | Predicate): Component {
+    if (typeof first === "function") {
+        return add(this, {
+            predicate: first
+        });
+    }
+
+    return add(this, first as Options);
+}
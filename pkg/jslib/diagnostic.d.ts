@@ -1,4 +1,7 @@
-import { Document } from "./document";
+import { BuildContext } from "./buildContext";
+import { Builder } from "./builder";
+import { Component } from "./component";
+import { Document, SourceLocation } from "./document";
 
 export type Severity = string;
 export type Category = string;
@@ -10,6 +13,13 @@ export const error: Severity;
 export const linting: Category;
 export const security: Category;
 export const specs: Category;
+export const policy: Category;
+
+/**
+ * Annotation that suppresses the diagnostics with the given comma separated ids on a document,
+ * e.g. "run-as-root,missing-resource-requirements".
+ */
+export const suppressDiagnosticsAnnotation: string;
 
 export declare class DiagnosticMetadata {
     id: string;
@@ -18,6 +28,12 @@ export declare class DiagnosticMetadata {
     severity: Severity;
     categories: Category[];
 
+    /**
+     * Fails the build before the output step even if no severity to fail on is set, e.g. for the inputs that
+     * can't be rendered.
+     */
+    failsBuild?: boolean;
+
     constructor(id: string, name: string, description: string, severity: Severity, categories: Category[]);
 }
 
@@ -26,5 +42,66 @@ export declare class Diagnostic {
     message: string;
     document?: Document;
 
+    /** Path of the field in the document that caused the diagnostic, e.g. "spec.template.spec.containers[0].image". */
+    fieldPath?: string;
+
+    /** Location in the source files that caused the diagnostic. Resolved from the document and the field path if not set. */
+    location?: SourceLocation;
+
+    /** Optional change that resolves the diagnostic, applied with `anemos build --fix` or {@link Builder.applyFixes}. */
+    fix?: DiagnosticFix;
+
+    /** Suppression that matched the diagnostic, null if the diagnostic is not suppressed. */
+    readonly suppressedBy?: DiagnosticSuppression;
+
     constructor(metadata: DiagnosticMetadata, message: string, document?: Document);
-}
\ No newline at end of file
+    constructor(metadata: DiagnosticMetadata, message: string, document: Document, fieldPath: string);
+
+    /**
+     * Returns the location in the source files that caused the diagnostic. Uses the location of the field in the YAML
+     * text the document was parsed from if it is known, otherwise the location of the call that created the document.
+     */
+    getSourceLocation(): SourceLocation | null;
+}
+
+/** Changes the documents to resolve a diagnostic, e.g. adds the missing labels. */
+export declare class DiagnosticFix {
+    constructor(description: string, apply: (context: BuildContext) => void);
+
+    /** Short explanation of the change, e.g. "Set runAsNonRoot to true". */
+    description: string;
+
+    /** Changes the documents to resolve the diagnostic. */
+    apply: (context: BuildContext) => void;
+}
+
+/** How a diagnostic is suppressed: "annotation", "builder" or "baseline". */
+export type SuppressionKind = string;
+
+/**
+ * Suppresses the diagnostics with the given ids. Suppressions can be limited to the documents of a component or
+ * a document group, all diagnostics with the given ids are suppressed otherwise. Suppressed diagnostics are neither
+ * reported as problems nor fail the build, but they are still counted in the reports.
+ */
+export declare class DiagnosticSuppression {
+    constructor(ids: string[]);
+
+    ids: string[];
+
+    /**
+     * Limits the suppression to the diagnostics of the documents that are created by this component, e.g. a Helm chart,
+     * and to the diagnostics that are reported by this component.
+     */
+    component?: Component;
+
+    /** Limits the suppression to the diagnostics of the documents in the document group with this path. */
+    documentGroup?: string;
+
+    /** Explanation of why the diagnostics are suppressed. */
+    reason?: string;
+
+    kind?: SuppressionKind;
+
+    /** Returns true if the suppression applies to the given diagnostic. */
+    matches(diagnostic: Diagnostic): boolean;
+}
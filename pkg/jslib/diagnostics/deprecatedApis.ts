@@ -0,0 +1,85 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { Document } from "@ohayocorp/anemos/document";
+import { DiagnosticMetadata, specs, warning } from "@ohayocorp/anemos/diagnostic";
+import { ApiDeprecation } from "@ohayocorp/anemos/kubernetesResourceInfo";
+import * as migrateApis from "../components/migrateApis";
+
+export const componentType = "diagnostics/deprecated-apis";
+
+export const diagnosticMetadata: DiagnosticMetadata = {
+    id: "deprecated-api",
+    name: "Deprecated API",
+    description: `Deprecated API versions are removed in a later Kubernetes version and the documents that use them can't be applied after upgrading the cluster.`,
+    severity: warning,
+    categories: [specs]
+};
+
+export class Component extends AnemosComponent {
+    constructor() {
+        super();
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    diagnose = (context: BuildContext) => {
+        for (const document of context.getAllDocuments()) {
+            const apiVersion = document.apiVersion;
+            const kind = document.kind;
+
+            if (!apiVersion || !kind) {
+                continue;
+            }
+
+            const deprecation = context.kubernetesResourceInfo.getApiDeprecation(apiVersion, kind);
+            if (!deprecation || deprecation.removed) {
+                continue;
+            }
+
+            const removal = deprecation.removedIn ? ` and removed in ${deprecation.removedIn}` : "";
+
+            context.addDiagnostic({
+                metadata: diagnosticMetadata,
+                message: `${apiVersion} ${kind} is deprecated in Kubernetes ${deprecation.deprecatedIn}${removal}, ${formatReplacement(deprecation)}`,
+                document: document,
+                fieldPath: "apiVersion",
+                fix: getMigrationFix(context, document),
+            });
+        }
+    }
+}
+
+/** Returns the sentence that states the replacement of the deprecated API. */
+export function formatReplacement(deprecation: ApiDeprecation): string {
+    if (!deprecation.replacement) {
+        return "there is no replacement";
+    }
+
+    return `use ${deprecation.replacement} instead`;
+}
+
+/** Returns the fix that migrates the document if there is a known conversion for its API version. */
+export function getMigrationFix(context: BuildContext, document: Document) {
+    if (!migrateApis.getConversion(context, document)) {
+        return undefined;
+    }
+
+    return {
+        description: `Migrate ${document.apiVersion} ${document.kind} to a served API version`,
+        apply: () => {
+            migrateApis.migrate(context, document);
+        },
+    };
+}
+
+export function add(builder: Builder): Component {
+    const component = new Component();
+    builder.addComponent(component);
+
+    return component;
+}
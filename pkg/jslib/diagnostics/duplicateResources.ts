@@ -60,6 +60,7 @@ export class Component extends AnemosComponent {
                         metadata: diagnosticMetadata,
                         message: message,
                         document: document,
+                        fieldPath: "metadata.name",
                     });
                 }
             }
@@ -1,23 +1,41 @@
 import * as anemos from "@ohayocorp/anemos";
+import * as deprecatedApis from './deprecatedApis';
 import * as duplicateResources from './duplicateResources';
+import * as invalidCustomResource from './invalidCustomResource';
+import * as invalidSchema from './invalidSchema';
 import * as limitLowerThanRequest from './limitLowerThanRequest';
 import * as missingLabels from './missingLabels';
 import * as missingNamespaces from './missingNamespaces';
+import * as missingReferences from './missingReferences';
 import * as missingResourceRequirements from './missingResourceRequirements';
+import * as podSecurity from './podSecurity';
+import * as removedApis from './removedApis';
 import * as runAsRoot from './runAsRoot';
 
+export * as deprecatedApis from './deprecatedApis';
 export * as duplicateResources from './duplicateResources';
+export * as invalidCustomResource from './invalidCustomResource';
+export * as invalidSchema from './invalidSchema';
 export * as limitLowerThanRequest from './limitLowerThanRequest';
 export * as missingLabels from './missingLabels';
 export * as missingNamespaces from './missingNamespaces';
+export * as missingReferences from './missingReferences';
 export * as missingResourceRequirements from './missingResourceRequirements';
+export * as podSecurity from './podSecurity';
+export * as removedApis from './removedApis';
 export * as runAsRoot from './runAsRoot';
 
 export function addDefaultDiagnostics(builder: anemos.Builder) {
+    deprecatedApis.add(builder);
     duplicateResources.add(builder);
+    invalidCustomResource.add(builder);
+    invalidSchema.add(builder);
     limitLowerThanRequest.add(builder);
     missingLabels.add(builder);
     missingNamespaces.add(builder);
+    missingReferences.add(builder);
     missingResourceRequirements.add(builder);
+    podSecurity.add(builder);
+    removedApis.add(builder);
     runAsRoot.add(builder);
 }
\ No newline at end of file
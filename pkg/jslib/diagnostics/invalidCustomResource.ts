@@ -0,0 +1,51 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { DiagnosticMetadata, error, specs } from "@ohayocorp/anemos/diagnostic";
+
+export const componentType = "diagnostics/invalid-custom-resource";
+
+export const diagnosticMetadata: DiagnosticMetadata = {
+    id: "invalid-custom-resource",
+    name: "Invalid Custom Resource",
+    description: `Custom resources that don't conform to the schema of their CRD, including the \`x-kubernetes-validations\` rules, are rejected by the cluster or their unknown fields are silently dropped while applying.`,
+    severity: error,
+    categories: [specs]
+};
+
+export class Component extends AnemosComponent {
+    constructor() {
+        super();
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    diagnose = (context: BuildContext) => {
+        for (const document of context.getAllDocuments()) {
+            const errors = context.validateCustomResource(document);
+            if (!errors) {
+                continue;
+            }
+
+            for (const validationError of errors) {
+                context.addDiagnostic({
+                    metadata: diagnosticMetadata,
+                    message: `\`${validationError.fieldPath}\`: ${validationError.message}`,
+                    document: document,
+                    fieldPath: validationError.fieldPath,
+                });
+            }
+        }
+    }
+}
+
+export function add(builder: Builder): Component {
+    const component = new Component();
+    builder.addComponent(component);
+
+    return component;
+}
@@ -0,0 +1,67 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { DiagnosticMetadata, error, specs, warning } from "@ohayocorp/anemos/diagnostic";
+
+export const componentType = "diagnostics/invalid-schema";
+
+export const diagnosticMetadata: DiagnosticMetadata = {
+    id: "invalid-schema",
+    name: "Invalid Schema",
+    description: `Documents that don't conform to the OpenAPI schema of the target Kubernetes version are either rejected by the cluster or their unknown fields are silently dropped while applying.`,
+    severity: error,
+    categories: [specs]
+};
+
+export const schemaVersionDiagnosticMetadata: DiagnosticMetadata = {
+    id: "schema-version-fallback",
+    name: "Schema Version Fallback",
+    description: `There is no embedded OpenAPI schema for the target Kubernetes version, documents are validated against the schema of the closest embedded version. Fields that are added or removed in the target version may be reported incorrectly.`,
+    severity: warning,
+    categories: [specs]
+};
+
+export class Component extends AnemosComponent {
+    constructor() {
+        super();
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    diagnose = (context: BuildContext) => {
+        const fallbackVersion = context.getSchemaFallbackVersion();
+        if (fallbackVersion) {
+            context.addDiagnostic({
+                metadata: schemaVersionDiagnosticMetadata,
+                message: `Documents are validated against the schema of Kubernetes ${fallbackVersion}`,
+            });
+        }
+
+        for (const document of context.getAllDocuments()) {
+            const errors = context.validateDocumentSchema(document);
+            if (!errors) {
+                continue;
+            }
+
+            for (const validationError of errors) {
+                context.addDiagnostic({
+                    metadata: diagnosticMetadata,
+                    message: `\`${validationError.fieldPath}\`: ${validationError.message}`,
+                    document: document,
+                    fieldPath: validationError.fieldPath,
+                });
+            }
+        }
+    }
+}
+
+export function add(builder: Builder): Component {
+    const component = new Component();
+    builder.addComponent(component);
+
+    return component;
+}
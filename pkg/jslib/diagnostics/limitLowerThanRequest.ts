@@ -70,7 +70,13 @@ export class Component extends AnemosComponent {
                     context.addDiagnostic({
                         metadata: diagnosticMetadata,
                         message: `Limit for ${name} (${limitQuantity}) is lower than request (${requestQuantity}) in container ${container.name} (**${document.fullPath()}**)`,
-                        document: document
+                        document: document,
+                        fix: {
+                            description: `Set ${name} limit of ${container.name} to the request ${requestQuantity}`,
+                            apply: () => {
+                                limits[name] = requestValue;
+                            }
+                        },
                     })
                 }
             }
@@ -3,6 +3,7 @@ import { Builder } from "@ohayocorp/anemos/builder";
 import { BuildContext } from "@ohayocorp/anemos/buildContext";
 import * as steps from "@ohayocorp/anemos/steps";
 import { DiagnosticMetadata, linting, warning } from "@ohayocorp/anemos/diagnostic";
+import { Document } from "@ohayocorp/anemos/document";
 import { KubernetesResource } from "@ohayocorp/anemos/kubernetesResourceInfo";
 
 export const componentType = "diagnostics/missing-labels";
@@ -76,10 +77,17 @@ export class Component extends AnemosComponent {
             const missingLabels = checkLabels(labels);
 
             for (const missingLabel of missingLabels) {
+                const value = getDefaultLabelValue(document, missingLabel);
+
                 context.addDiagnostic({
                     metadata: diagnosticMetadata,
                     message: `${missingLabel}`,
                     document: document,
+                    fieldPath: "metadata.labels",
+                    fix: value === undefined ? undefined : {
+                        description: `Set label ${missingLabel} to ${value}`,
+                        apply: () => document.setLabel(missingLabel, value),
+                    },
                 });
             }
 
@@ -87,10 +95,18 @@ export class Component extends AnemosComponent {
                 const missingPodTemplateLabels = checkLabels(document.getWorkloadLabels());
 
                 for (const missingLabel of missingPodTemplateLabels) {
+                    const value = getDefaultLabelValue(document, missingLabel);
+
                     context.addDiagnostic({
                         metadata: diagnosticMetadata,
                         message: `${missingLabel} *(pod template)*`,
                         document: document,
+                        fix: value === undefined ? undefined : {
+                            description: `Set pod template label ${missingLabel} to ${value}`,
+                            apply: () => {
+                                document.ensureWorkloadLabels()[missingLabel] = value;
+                            },
+                        },
                     });
                 }
             }
@@ -98,6 +114,21 @@ export class Component extends AnemosComponent {
     }
 }
 
+/**
+ * Returns the value of the standard labels that can be derived from the document, i.e. the name of the document for
+ * "app.kubernetes.io/name" and the document group for "app.kubernetes.io/instance". Returns undefined for other labels.
+ */
+function getDefaultLabelValue(document: Document, label: string): string | undefined {
+    switch (label) {
+        case "app.kubernetes.io/name":
+            return document.metadata?.name;
+        case "app.kubernetes.io/instance":
+            return document.group?.path || document.metadata?.name;
+        default:
+            return undefined;
+    }
+}
+
 export function add(builder: Builder, options?: Options): Component {
     const component = new Component(options);
     builder.addComponent(component);
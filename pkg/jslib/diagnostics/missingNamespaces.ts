@@ -2,8 +2,10 @@ import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
 import { Builder } from "@ohayocorp/anemos/builder";
 import { BuildContext } from "@ohayocorp/anemos/buildContext";
 import * as steps from "@ohayocorp/anemos/steps";
-import { DiagnosticMetadata, error, linting, warning } from "@ohayocorp/anemos/diagnostic";
-import { KubernetesResource } from "@ohayocorp/anemos/kubernetesResourceInfo";
+import { DiagnosticMetadata, info, linting, warning } from "@ohayocorp/anemos/diagnostic";
+import { Document } from "@ohayocorp/anemos/document";
+import { DocumentGroup } from "@ohayocorp/anemos/documentGroup";
+import * as k8s from "@ohayocorp/anemos/k8s";
 
 export const componentType = "diagnostics/missing-namespaces";
 
@@ -15,10 +17,40 @@ export const diagnosticMetadata: DiagnosticMetadata = {
     categories: [linting]
 };
 
+export const namespaceDocumentDiagnosticMetadata: DiagnosticMetadata = {
+    id: "missing-namespace-documents",
+    name: "Missing Namespace Documents",
+    description: `Resources that are in a namespace which is not created by the build can't be applied to a cluster that doesn't already have the namespace.`,
+    severity: info,
+    categories: [linting]
+};
+
+const systemNamespaces = [
+    "kube-system",
+    "kube-public",
+    "kube-node-lease",
+    "default",
+];
+
+/** Path of the document group in which the namespaces created by the fixes belong. */
+const namespacesDocumentGroup = "namespaces";
+
+export class Options {
+    /**
+     * Reports the namespaces that are used by the documents but not created by the build, with a fix that adds
+     * the Namespace documents. Disabled by default since the namespaces usually exist in the cluster.
+     */
+    checkNamespaceDocuments?: boolean;
+}
+
 export class Component extends AnemosComponent {
-    constructor() {
+    private options: Options;
+
+    constructor(options?: Options) {
         super();
 
+        this.options = options ?? {};
+
         this.setComponentType(componentType);
         this.setIdentifier(componentType);
 
@@ -26,7 +58,14 @@ export class Component extends AnemosComponent {
     }
 
     diagnose = (context: BuildContext) => {
+        const existingNamespaces = new Set<string>();
+        const referencedNamespaces = new Map<string, Document>();
+
         for (const document of context.getAllDocuments()) {
+            if (document.isNamespace() && document.metadata?.name) {
+                existingNamespaces.add(document.metadata.name);
+            }
+
             const apiVersion = document.apiVersion;
             const kind = document.kind;
 
@@ -38,19 +77,70 @@ export class Component extends AnemosComponent {
                 continue;
             }
 
-            if (!document.metadata?.namespace) {
+            const namespace = document.metadata?.namespace;
+
+            if (!namespace) {
                 context.addDiagnostic({
                     metadata: diagnosticMetadata,
                     message: ``,
                     document: document,
+                    fieldPath: "metadata.namespace",
                 });
+
+                continue;
             }
+
+            if (this.options.checkNamespaceDocuments && !systemNamespaces.includes(namespace) && !referencedNamespaces.has(namespace)) {
+                referencedNamespaces.set(namespace, document);
+            }
+        }
+
+        for (const [namespace, document] of referencedNamespaces) {
+            if (existingNamespaces.has(namespace)) {
+                continue;
+            }
+
+            context.addDiagnostic({
+                metadata: namespaceDocumentDiagnosticMetadata,
+                message: `Namespace ${namespace} is not created by the build`,
+                document: document,
+                fieldPath: "metadata.namespace",
+                fix: {
+                    description: `Add a Namespace document for ${namespace}`,
+                    apply: () => addNamespace(context, namespace),
+                },
+            });
         }
     }
 }
 
-export function add(builder: Builder): Component {
-    const component = new Component();
+/** Adds a Namespace document with the given name to the namespaces document group if it doesn't exist. */
+function addNamespace(context: BuildContext, namespace: string) {
+    if (context.getDocument(document => document.isNamespace() && document.metadata?.name === namespace)) {
+        return;
+    }
+
+    let documentGroup = context.getDocumentGroup(namespacesDocumentGroup);
+    if (!documentGroup) {
+        documentGroup = new DocumentGroup(namespacesDocumentGroup);
+        context.addDocumentGroup(documentGroup);
+    }
+
+    documentGroup.addDocument(new k8s.Namespace({
+        metadata: {
+            name: namespace
+        }
+    }));
+}
+
+/**
+ * Adds the namespace diagnostics to the builder. Replaces the existing component, e.g. the one that is added with the
+ * default diagnostics, so that the options can be set with `add(builder, { checkNamespaceDocuments: true })`.
+ */
+export function add(builder: Builder, options?: Options): Component {
+    builder.removeComponent(componentType);
+
+    const component = new Component(options);
     builder.addComponent(component);
 
     return component;
@@ -0,0 +1,463 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { Document } from "@ohayocorp/anemos/document";
+import { DiagnosticMetadata, linting, warning } from "@ohayocorp/anemos/diagnostic";
+import { getPod } from "./podSecurity";
+
+export const componentType = "diagnostics/missing-references";
+
+export const unmatchedServiceSelectorMetadata: DiagnosticMetadata = {
+    id: "unmatched-service-selector",
+    name: "Unmatched Service Selector",
+    description: `Services whose selectors don't match the pod template labels of any workload don't have any endpoints, so the traffic to them fails.`,
+    severity: warning,
+    categories: [linting]
+};
+
+export const missingIngressBackendMetadata: DiagnosticMetadata = {
+    id: "missing-ingress-backend",
+    name: "Missing Ingress Backend",
+    description: `Ingress backends must point to existing Services and ports, otherwise the requests to them fail.`,
+    severity: warning,
+    categories: [linting]
+};
+
+export const missingConfigReferenceMetadata: DiagnosticMetadata = {
+    id: "missing-config-reference",
+    name: "Missing ConfigMap or Secret",
+    description: `Pods that reference missing ConfigMaps, Secrets or keys in them, without marking the references as optional, can't start.`,
+    severity: warning,
+    categories: [linting]
+};
+
+export const missingRbacReferenceMetadata: DiagnosticMetadata = {
+    id: "missing-rbac-reference",
+    name: "Missing RBAC Reference",
+    description: `RoleBindings and ClusterRoleBindings that reference missing Roles, ClusterRoles or ServiceAccounts don't grant the intended permissions.`,
+    severity: warning,
+    categories: [linting]
+};
+
+export const missingStorageClassMetadata: DiagnosticMetadata = {
+    id: "missing-storage-class",
+    name: "Missing StorageClass",
+    description: `PersistentVolumeClaims that reference unknown StorageClasses stay pending.`,
+    severity: warning,
+    categories: [linting]
+};
+
+/** Metadata of all the reference diagnostics. */
+export const diagnosticMetadatas: DiagnosticMetadata[] = [
+    unmatchedServiceSelectorMetadata,
+    missingIngressBackendMetadata,
+    missingConfigReferenceMetadata,
+    missingRbacReferenceMetadata,
+    missingStorageClassMetadata,
+];
+
+/**
+ * Declares objects that exist in the cluster but are not created by the build, e.g. Secrets created by an operator or
+ * StorageClasses of the cloud provider. References to these objects are not reported.
+ */
+export type ExternalObject = {
+    kind: string;
+    /** Name of the object. All objects of the kind are external if not set. */
+    name?: string;
+    /** Namespace of the object. Objects with the name in all namespaces are external if not set. */
+    namespace?: string;
+};
+
+/** Objects that are created by Kubernetes itself. */
+export const builtInObjects: ExternalObject[] = [
+    { kind: "ServiceAccount", name: "default" },
+    { kind: "ConfigMap", name: "kube-root-ca.crt" },
+    { kind: "ClusterRole", name: "cluster-admin" },
+    { kind: "ClusterRole", name: "admin" },
+    { kind: "ClusterRole", name: "edit" },
+    { kind: "ClusterRole", name: "view" },
+];
+
+export class Options {
+    /** Objects that are provided outside of the build. References to them are not reported. */
+    externalObjects?: ExternalObject[];
+}
+
+type ObjectKey = {
+    kind: string;
+    name: string;
+    namespace?: string;
+};
+
+export class Component extends AnemosComponent {
+    private options: Options;
+
+    constructor(options?: Options) {
+        super();
+
+        this.options = options ?? {};
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.sanitize, this.sanitize);
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    sanitize = (context: BuildContext) => {
+        this.options.externalObjects ??= [];
+    }
+
+    diagnose = (context: BuildContext) => {
+        const documents = context.getAllDocuments();
+        const objects = new References(documents, [...builtInObjects, ...this.options.externalObjects!]);
+
+        for (const document of documents) {
+            if (document.isService()) {
+                this.checkService(context, objects, document);
+            } else if (document.isIngress()) {
+                this.checkIngress(context, objects, document);
+            } else if (document.isRoleBinding() || document.isClusterRoleBinding()) {
+                this.checkRoleBinding(context, objects, document);
+            } else if (document.isPersistentVolumeClaim()) {
+                this.checkStorageClass(context, objects, document, document.spec?.storageClassName, "spec.storageClassName");
+            }
+
+            if (document.asWorkload()) {
+                this.checkConfigReferences(context, objects, document);
+            }
+
+            if (document.isStatefulSet()) {
+                (document.spec?.volumeClaimTemplates ?? []).forEach((template: any, index: number) => {
+                    this.checkStorageClass(
+                        context,
+                        objects,
+                        document,
+                        template.spec?.storageClassName,
+                        `spec.volumeClaimTemplates[${index}].spec.storageClassName`);
+                });
+            }
+        }
+    }
+
+    private checkService = (context: BuildContext, objects: References, document: Document) => {
+        const selector: Record<string, string> | undefined = document.spec?.selector;
+        if (!selector || Object.keys(selector).length === 0 || document.spec?.type === "ExternalName") {
+            return;
+        }
+
+        const namespace = document.metadata?.namespace;
+        const name = document.metadata?.name;
+
+        if (name && objects.isExternal({ kind: "Service", name: name, namespace: namespace })) {
+            return;
+        }
+
+        const matches = objects.documents.some(workload => {
+            if (!workload.asWorkload() || !namespaceMatches(namespace, workload.metadata?.namespace)) {
+                return false;
+            }
+
+            const labels = workload.getWorkloadLabels() ?? {};
+            return Object.entries(selector).every(([key, value]) => labels[key] === value);
+        });
+
+        if (!matches) {
+            const selectorText = Object.entries(selector).map(([key, value]) => `${key}=${value}`).join(", ");
+
+            context.addDiagnostic({
+                metadata: unmatchedServiceSelectorMetadata,
+                message: `selector **${selectorText}** doesn't match any workload`,
+                document: document,
+                fieldPath: "spec.selector",
+            });
+        }
+    }
+
+    private checkIngress = (context: BuildContext, objects: References, document: Document) => {
+        const namespace = document.metadata?.namespace;
+
+        const checkBackend = (backend: any, fieldPath: string) => {
+            const serviceName: string | undefined = backend?.service?.name;
+            if (!serviceName) {
+                return;
+            }
+
+            const key = { kind: "Service", name: serviceName, namespace: namespace };
+            if (objects.isExternal(key)) {
+                return;
+            }
+
+            const service = objects.find(key);
+            if (!service) {
+                context.addDiagnostic({
+                    metadata: missingIngressBackendMetadata,
+                    message: `Service **${serviceName}** doesn't exist`,
+                    document: document,
+                    fieldPath: `${fieldPath}.service.name`,
+                });
+
+                return;
+            }
+
+            const port = backend.service.port;
+            const ports: any[] = service.spec?.ports ?? [];
+
+            if (port?.number !== undefined && !ports.some(servicePort => servicePort.port === port.number)) {
+                context.addDiagnostic({
+                    metadata: missingIngressBackendMetadata,
+                    message: `Service **${serviceName}** doesn't have port ${port.number}`,
+                    document: document,
+                    fieldPath: `${fieldPath}.service.port.number`,
+                });
+            }
+
+            if (port?.name !== undefined && !ports.some(servicePort => servicePort.name === port.name)) {
+                context.addDiagnostic({
+                    metadata: missingIngressBackendMetadata,
+                    message: `Service **${serviceName}** doesn't have port ${port.name}`,
+                    document: document,
+                    fieldPath: `${fieldPath}.service.port.name`,
+                });
+            }
+        };
+
+        checkBackend(document.spec?.defaultBackend, "spec.defaultBackend");
+
+        (document.spec?.rules ?? []).forEach((rule: any, ruleIndex: number) => {
+            (rule.http?.paths ?? []).forEach((path: any, pathIndex: number) => {
+                checkBackend(path.backend, `spec.rules[${ruleIndex}].http.paths[${pathIndex}].backend`);
+            });
+        });
+    }
+
+    private checkConfigReferences = (context: BuildContext, objects: References, document: Document) => {
+        // Pod specs of CronJobs are in their job templates.
+        const pod = getPod(document);
+        if (!pod) {
+            return;
+        }
+
+        const { spec, specFieldPath, containers } = pod;
+        const namespace = document.metadata?.namespace;
+
+        const check = (
+            kind: "ConfigMap" | "Secret",
+            name: string | undefined,
+            key: string | undefined,
+            optional: boolean | undefined,
+            location: string,
+            fieldPath: string,
+            nameField: string = "name",
+        ) => {
+            if (!name || optional) {
+                return;
+            }
+
+            const objectKey = { kind: kind, name: name, namespace: namespace };
+            if (objects.isExternal(objectKey)) {
+                return;
+            }
+
+            const object = objects.find(objectKey);
+            if (!object) {
+                context.addDiagnostic({
+                    metadata: missingConfigReferenceMetadata,
+                    message: `${kind} **${name}** referenced by ${location} doesn't exist`,
+                    document: document,
+                    fieldPath: `${fieldPath}.${nameField}`,
+                });
+
+                return;
+            }
+
+            if (key !== undefined && !hasKey(object, key)) {
+                context.addDiagnostic({
+                    metadata: missingConfigReferenceMetadata,
+                    message: `${kind} **${name}** referenced by ${location} doesn't have key ${key}`,
+                    document: document,
+                    fieldPath: `${fieldPath}.key`,
+                });
+            }
+        };
+
+        for (const { container, fieldPath } of containers) {
+            const location = `container **${container.name}**`;
+
+            (container.env ?? []).forEach((env, index) => {
+                const envFieldPath = `${fieldPath}.env[${index}].valueFrom`;
+                const configMapKeyRef = env.valueFrom?.configMapKeyRef;
+                const secretKeyRef = env.valueFrom?.secretKeyRef;
+
+                check("ConfigMap", configMapKeyRef?.name, configMapKeyRef?.key, configMapKeyRef?.optional, location, `${envFieldPath}.configMapKeyRef`);
+                check("Secret", secretKeyRef?.name, secretKeyRef?.key, secretKeyRef?.optional, location, `${envFieldPath}.secretKeyRef`);
+            });
+
+            (container.envFrom ?? []).forEach((envFrom, index) => {
+                const envFromFieldPath = `${fieldPath}.envFrom[${index}]`;
+
+                check("ConfigMap", envFrom.configMapRef?.name, undefined, envFrom.configMapRef?.optional, location, `${envFromFieldPath}.configMapRef`);
+                check("Secret", envFrom.secretRef?.name, undefined, envFrom.secretRef?.optional, location, `${envFromFieldPath}.secretRef`);
+            });
+        }
+
+        (spec.volumes ?? []).forEach((volume, volumeIndex) => {
+            const location = `volume **${volume.name}**`;
+            const volumeFieldPath = `${specFieldPath}.volumes[${volumeIndex}]`;
+
+            check("ConfigMap", volume.configMap?.name, undefined, volume.configMap?.optional, location, `${volumeFieldPath}.configMap`);
+
+            check("Secret", volume.secret?.secretName, undefined, volume.secret?.optional, location, `${volumeFieldPath}.secret`, "secretName");
+
+            (volume.projected?.sources ?? []).forEach((source, sourceIndex) => {
+                const sourceFieldPath = `${volumeFieldPath}.projected.sources[${sourceIndex}]`;
+
+                check("ConfigMap", source.configMap?.name, undefined, source.configMap?.optional, location, `${sourceFieldPath}.configMap`);
+                check("Secret", source.secret?.name, undefined, source.secret?.optional, location, `${sourceFieldPath}.secret`);
+            });
+        });
+
+        (spec.imagePullSecrets ?? []).forEach((imagePullSecret, index) => {
+            check("Secret", imagePullSecret.name, undefined, undefined, "imagePullSecrets", `${specFieldPath}.imagePullSecrets[${index}]`);
+        });
+    }
+
+    private checkRoleBinding = (context: BuildContext, objects: References, document: Document) => {
+        const namespace = document.metadata?.namespace;
+        const roleRef = document.roleRef;
+
+        if (roleRef?.name && (roleRef.kind === "Role" || roleRef.kind === "ClusterRole")) {
+            const key = {
+                kind: roleRef.kind,
+                name: roleRef.name,
+                namespace: roleRef.kind === "Role" ? namespace : undefined,
+            };
+
+            // Roles that start with "system:" are created by Kubernetes.
+            if (!roleRef.name.startsWith("system:") && !objects.isExternal(key) && !objects.find(key)) {
+                context.addDiagnostic({
+                    metadata: missingRbacReferenceMetadata,
+                    message: `${roleRef.kind} **${roleRef.name}** doesn't exist`,
+                    document: document,
+                    fieldPath: "roleRef.name",
+                });
+            }
+        }
+
+        (document.subjects ?? []).forEach((subject: any, index: number) => {
+            if (subject.kind !== "ServiceAccount" || !subject.name) {
+                return;
+            }
+
+            const key = { kind: "ServiceAccount", name: subject.name, namespace: subject.namespace ?? namespace };
+
+            if (!objects.isExternal(key) && !objects.find(key)) {
+                context.addDiagnostic({
+                    metadata: missingRbacReferenceMetadata,
+                    message: `ServiceAccount **${subject.name}** doesn't exist`,
+                    document: document,
+                    fieldPath: `subjects[${index}].name`,
+                });
+            }
+        });
+    }
+
+    private checkStorageClass = (context: BuildContext, objects: References, document: Document, storageClassName: string | undefined, fieldPath: string) => {
+        // Claims without a class use the default class and the empty class disables dynamic provisioning.
+        if (!storageClassName) {
+            return;
+        }
+
+        const key = { kind: "StorageClass", name: storageClassName };
+
+        if (!objects.isExternal(key) && !objects.find(key)) {
+            context.addDiagnostic({
+                metadata: missingStorageClassMetadata,
+                message: `StorageClass **${storageClassName}** doesn't exist`,
+                document: document,
+                fieldPath: fieldPath,
+            });
+        }
+    }
+}
+
+/** Finds the referenced objects in the documents of the build and the declared external objects. */
+class References {
+    documents: Document[];
+    private externalObjects: ExternalObject[];
+    // Secrets that are created from the custom resources in the build, e.g. by cert-manager.
+    private generatedSecrets: ObjectKey[];
+
+    constructor(documents: Document[], externalObjects: ExternalObject[]) {
+        this.documents = documents;
+        this.externalObjects = externalObjects;
+        this.generatedSecrets = getGeneratedSecrets(documents);
+    }
+
+    find(key: ObjectKey): Document | undefined {
+        return this.documents.find(document =>
+            document.kind === key.kind &&
+            document.metadata?.name === key.name &&
+            namespaceMatches(key.namespace, document.metadata?.namespace));
+    }
+
+    isExternal(key: ObjectKey): boolean {
+        const matches = (object: ExternalObject) =>
+            object.kind === key.kind &&
+            (object.name === undefined || object.name === key.name) &&
+            (object.namespace === undefined || namespaceMatches(key.namespace, object.namespace));
+
+        return this.externalObjects.some(matches) || (key.kind === "Secret" && this.generatedSecrets.some(matches));
+    }
+}
+
+/**
+ * Returns the Secrets that are created by the well known operators from the custom resources in the build,
+ * i.e. cert-manager Certificates, External Secrets and Sealed Secrets.
+ */
+function getGeneratedSecrets(documents: Document[]): ObjectKey[] {
+    const result: ObjectKey[] = [];
+
+    for (const document of documents) {
+        const apiVersion = document.apiVersion ?? "";
+        const namespace = document.metadata?.namespace;
+        let name: string | undefined;
+
+        if (apiVersion.startsWith("cert-manager.io/") && document.kind === "Certificate") {
+            name = document.spec?.secretName;
+        } else if (apiVersion.startsWith("external-secrets.io/") && document.kind === "ExternalSecret") {
+            name = document.spec?.target?.name ?? document.metadata?.name;
+        } else if (apiVersion.startsWith("bitnami.com/") && document.kind === "SealedSecret") {
+            name = document.spec?.template?.metadata?.name ?? document.metadata?.name;
+        }
+
+        if (name) {
+            result.push({ kind: "Secret", name: name, namespace: namespace });
+        }
+    }
+
+    return result;
+}
+
+function hasKey(document: Document, key: string): boolean {
+    return [document.data, document.binaryData, document.stringData].some(data => data && key in data);
+}
+
+// Documents without a namespace are applied to the namespace of the current context, so they can match any namespace.
+function namespaceMatches(first?: string, second?: string): boolean {
+    return first === undefined || second === undefined || first === second;
+}
+
+/**
+ * Adds the reference diagnostics to the builder. Replaces the existing component, e.g. the one that is added with the
+ * default diagnostics, so that the external objects can be declared with `add(builder, { externalObjects: [...] })`.
+ */
+export function add(builder: Builder, options?: Options): Component {
+    builder.removeComponent(componentType);
+
+    const component = new Component(options);
+    builder.addComponent(component);
+
+    return component;
+}
@@ -0,0 +1,582 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { Document } from "@ohayocorp/anemos/document";
+import { DiagnosticMetadata, security, warning } from "@ohayocorp/anemos/diagnostic";
+import { Container, PodSpec } from "@ohayocorp/anemos/k8s/core/v1";
+import { ObjectMeta } from "@ohayocorp/anemos/k8s/apimachinery/meta/v1";
+
+export const componentType = "diagnostics/pod-security";
+
+/** Pod Security Standards profiles from the least to the most restrictive. */
+export type Level = "privileged" | "baseline" | "restricted";
+
+/** Namespace label that sets the Pod Security Standards level that is enforced by the Pod Security admission. */
+export const enforceLabel = "pod-security.kubernetes.io/enforce";
+
+const levels: Level[] = ["privileged", "baseline", "restricted"];
+
+/** Describes a container of a pod together with the path of the container in the document. */
+export type PodContainer = {
+    container: Container;
+    fieldPath: string;
+};
+
+/** Pod spec of a workload together with the paths that are used to report the violations. */
+export type Pod = {
+    document: Document;
+    spec: PodSpec;
+    metadata?: ObjectMeta;
+    specFieldPath: string;
+    containers: PodContainer[];
+};
+
+export type Violation = {
+    message: string;
+    fieldPath?: string;
+    fix?: {
+        description: string;
+        apply: () => void;
+    };
+};
+
+/** A control of the Pod Security Standards that is checked for the workloads in the namespaces with the given level. */
+export type Control = {
+    metadata: DiagnosticMetadata;
+    level: Level;
+    check: (pod: Pod) => Violation[];
+};
+
+function newMetadata(id: string, name: string, description: string): DiagnosticMetadata {
+    return {
+        id: id,
+        name: name,
+        description: description,
+        severity: warning,
+        categories: [security]
+    };
+}
+
+const allowedBaselineCapabilities = [
+    "AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP",
+    "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+];
+
+const allowedSELinuxTypes = ["", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"];
+
+const safeSysctls = [
+    "kernel.shm_rmid_forced",
+    "net.ipv4.ip_local_port_range",
+    "net.ipv4.ip_local_reserved_ports",
+    "net.ipv4.ip_unprivileged_port_start",
+    "net.ipv4.ping_group_range",
+    "net.ipv4.tcp_fin_timeout",
+    "net.ipv4.tcp_keepalive_intvl",
+    "net.ipv4.tcp_keepalive_probes",
+    "net.ipv4.tcp_keepalive_time",
+    "net.ipv4.tcp_syncookies",
+];
+
+const allowedRestrictedVolumeTypes = [
+    "configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+];
+
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/";
+
+/** Controls of the Pod Security Standards, see https://kubernetes.io/docs/concepts/security/pod-security-standards. */
+export const controls: Control[] = [
+    {
+        metadata: newMetadata(
+            "pod-security-host-process",
+            "Pod Security: HostProcess",
+            "Windows pods offer the ability to run HostProcess containers which enables privileged access to the Windows host machine."),
+        level: "baseline",
+        check: pod => [
+            ...(pod.spec.securityContext?.windowsOptions?.hostProcess
+                ? [{ message: `hostProcess is true`, fieldPath: `${pod.specFieldPath}.securityContext.windowsOptions.hostProcess` }]
+                : []),
+            ...pod.containers
+                .filter(({ container }) => container.securityContext?.windowsOptions?.hostProcess)
+                .map(({ container, fieldPath }) => ({
+                    message: `**${container.name}** hostProcess is true`,
+                    fieldPath: `${fieldPath}.securityContext.windowsOptions.hostProcess`,
+                })),
+        ],
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-host-namespaces",
+            "Pod Security: Host Namespaces",
+            "Sharing the host namespaces must be disallowed."),
+        level: "baseline",
+        check: pod => (["hostNetwork", "hostPID", "hostIPC"] as const)
+            .filter(field => pod.spec[field])
+            .map(field => ({
+                message: `${field} is true`,
+                fieldPath: `${pod.specFieldPath}.${field}`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-privileged",
+            "Pod Security: Privileged Containers",
+            "Privileged containers disable most security mechanisms and must be disallowed."),
+        level: "baseline",
+        check: pod => pod.containers
+            .filter(({ container }) => container.securityContext?.privileged)
+            .map(({ container, fieldPath }) => ({
+                message: `**${container.name}** is privileged`,
+                fieldPath: `${fieldPath}.securityContext.privileged`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-capabilities",
+            "Pod Security: Capabilities",
+            "Adding capabilities beyond the default set must be disallowed."),
+        level: "baseline",
+        check: pod => pod.containers.flatMap(({ container, fieldPath }) =>
+            (container.securityContext?.capabilities?.add ?? [])
+                .filter(capability => !allowedBaselineCapabilities.includes(capability))
+                .map(capability => ({
+                    message: `**${container.name}** adds capability ${capability}`,
+                    fieldPath: `${fieldPath}.securityContext.capabilities.add`,
+                }))),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-host-path",
+            "Pod Security: HostPath Volumes",
+            "HostPath volumes must be forbidden."),
+        level: "baseline",
+        check: pod => (pod.spec.volumes ?? [])
+            .map((volume, index) => ({ volume, index }))
+            .filter(({ volume }) => volume.hostPath)
+            .map(({ volume, index }) => ({
+                message: `volume **${volume.name}** is a hostPath volume`,
+                fieldPath: `${pod.specFieldPath}.volumes[${index}].hostPath`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-host-ports",
+            "Pod Security: Host Ports",
+            "HostPorts should be disallowed entirely or restricted to a known list."),
+        level: "baseline",
+        check: pod => pod.containers.flatMap(({ container, fieldPath }) =>
+            (container.ports ?? [])
+                .map((port, index) => ({ port, index }))
+                .filter(({ port }) => port.hostPort)
+                .map(({ port, index }) => ({
+                    message: `**${container.name}** uses host port ${port.hostPort}`,
+                    fieldPath: `${fieldPath}.ports[${index}].hostPort`,
+                }))),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-apparmor",
+            "Pod Security: AppArmor",
+            "On supported hosts, the RuntimeDefault AppArmor profile is applied by default. The baseline policy should prevent overriding or disabling the default AppArmor profile, or restrict overrides to an allowed set of profiles."),
+        level: "baseline",
+        check: pod => {
+            const violations: Violation[] = [];
+            const isAllowedType = (type?: string) => type === undefined || type === "RuntimeDefault" || type === "Localhost";
+
+            const podType = pod.spec.securityContext?.appArmorProfile?.type;
+            if (!isAllowedType(podType)) {
+                violations.push({
+                    message: `AppArmor profile type is ${podType}`,
+                    fieldPath: `${pod.specFieldPath}.securityContext.appArmorProfile.type`,
+                });
+            }
+
+            for (const { container, fieldPath } of pod.containers) {
+                const containerType = container.securityContext?.appArmorProfile?.type;
+                if (!isAllowedType(containerType)) {
+                    violations.push({
+                        message: `**${container.name}** AppArmor profile type is ${containerType}`,
+                        fieldPath: `${fieldPath}.securityContext.appArmorProfile.type`,
+                    });
+                }
+            }
+
+            for (const [key, value] of Object.entries(pod.metadata?.annotations ?? {})) {
+                if (!key.startsWith(appArmorAnnotationPrefix)) {
+                    continue;
+                }
+
+                if (value !== "runtime/default" && !value.startsWith("localhost/")) {
+                    violations.push({
+                        message: `AppArmor annotation of **${key.substring(appArmorAnnotationPrefix.length)}** is ${value}`,
+                    });
+                }
+            }
+
+            return violations;
+        },
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-selinux",
+            "Pod Security: SELinux",
+            "Setting the SELinux type is restricted, and setting a custom SELinux user or role option is forbidden."),
+        level: "baseline",
+        check: pod => {
+            const violations: Violation[] = [];
+
+            const check = (options: any, fieldPath: string, prefix: string) => {
+                if (!options) {
+                    return;
+                }
+
+                if (!allowedSELinuxTypes.includes(options.type ?? "")) {
+                    violations.push({ message: `${prefix}SELinux type is ${options.type}`, fieldPath: `${fieldPath}.type` });
+                }
+
+                if (options.user) {
+                    violations.push({ message: `${prefix}SELinux user is set`, fieldPath: `${fieldPath}.user` });
+                }
+
+                if (options.role) {
+                    violations.push({ message: `${prefix}SELinux role is set`, fieldPath: `${fieldPath}.role` });
+                }
+            };
+
+            check(pod.spec.securityContext?.seLinuxOptions, `${pod.specFieldPath}.securityContext.seLinuxOptions`, "");
+
+            for (const { container, fieldPath } of pod.containers) {
+                check(container.securityContext?.seLinuxOptions, `${fieldPath}.securityContext.seLinuxOptions`, `**${container.name}** `);
+            }
+
+            return violations;
+        },
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-proc-mount",
+            "Pod Security: /proc Mount Type",
+            "The default /proc masks are set up to reduce attack surface, and should be required."),
+        level: "baseline",
+        check: pod => pod.containers
+            .filter(({ container }) => (container.securityContext?.procMount ?? "Default") !== "Default")
+            .map(({ container, fieldPath }) => ({
+                message: `**${container.name}** procMount is ${container.securityContext!.procMount}`,
+                fieldPath: `${fieldPath}.securityContext.procMount`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-seccomp",
+            "Pod Security: Seccomp",
+            "Seccomp profile must not be explicitly set to Unconfined."),
+        level: "baseline",
+        check: pod => [
+            ...(pod.spec.securityContext?.seccompProfile?.type === "Unconfined"
+                ? [{ message: `seccomp profile type is Unconfined`, fieldPath: `${pod.specFieldPath}.securityContext.seccompProfile.type` }]
+                : []),
+            ...pod.containers
+                .filter(({ container }) => container.securityContext?.seccompProfile?.type === "Unconfined")
+                .map(({ container, fieldPath }) => ({
+                    message: `**${container.name}** seccomp profile type is Unconfined`,
+                    fieldPath: `${fieldPath}.securityContext.seccompProfile.type`,
+                })),
+        ],
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-sysctls",
+            "Pod Security: Sysctls",
+            "Sysctls can disable security mechanisms or affect all containers on a host, and should be disallowed except for an allowed \"safe\" subset."),
+        level: "baseline",
+        check: pod => (pod.spec.securityContext?.sysctls ?? [])
+            .map((sysctl, index) => ({ sysctl, index }))
+            .filter(({ sysctl }) => !safeSysctls.includes(sysctl.name))
+            .map(({ sysctl, index }) => ({
+                message: `sysctl ${sysctl.name} is not allowed`,
+                fieldPath: `${pod.specFieldPath}.securityContext.sysctls[${index}]`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-volume-types",
+            "Pod Security: Volume Types",
+            "The restricted policy only permits the configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected and secret volume types."),
+        level: "restricted",
+        check: pod => (pod.spec.volumes ?? []).flatMap((volume, index) => {
+            // HostPath volumes are already reported by the baseline control.
+            const types = Object.keys(volume).filter(key =>
+                key !== "name" && key !== "hostPath" && !allowedRestrictedVolumeTypes.includes(key));
+
+            return types.map(type => ({
+                message: `volume **${volume.name}** has type ${type}`,
+                fieldPath: `${pod.specFieldPath}.volumes[${index}].${type}`,
+            }));
+        }),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-privilege-escalation",
+            "Pod Security: Privilege Escalation",
+            "Privilege escalation (such as via set-user-ID or set-group-ID file mode) should not be allowed."),
+        level: "restricted",
+        check: pod => isWindows(pod) ? [] : pod.containers
+            .filter(({ container }) => container.securityContext?.allowPrivilegeEscalation !== false)
+            .map(({ container, fieldPath }) => ({
+                message: `**${container.name}** allowPrivilegeEscalation is not set to false`,
+                fieldPath: `${fieldPath}.securityContext.allowPrivilegeEscalation`,
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-run-as-non-root",
+            "Pod Security: Running as Non-root",
+            "Containers must be required to run as non-root users."),
+        level: "restricted",
+        check: pod => pod.containers
+            .filter(({ container }) => (container.securityContext?.runAsNonRoot ?? pod.spec.securityContext?.runAsNonRoot) !== true)
+            .map(({ container, fieldPath }) => ({
+                message: `**${container.name}** runAsNonRoot is not set to true`,
+                fieldPath: `${fieldPath}.securityContext.runAsNonRoot`,
+                fix: {
+                    description: `Set runAsNonRoot to true in the security context of ${container.name}`,
+                    apply: () => {
+                        container.securityContext ??= {};
+                        container.securityContext.runAsNonRoot = true;
+                    },
+                },
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-run-as-user",
+            "Pod Security: Running as Non-root User",
+            "Containers must not set runAsUser to 0."),
+        level: "restricted",
+        check: pod => [
+            ...(pod.spec.securityContext?.runAsUser === 0
+                ? [{ message: `runAsUser is 0`, fieldPath: `${pod.specFieldPath}.securityContext.runAsUser` }]
+                : []),
+            ...pod.containers
+                .filter(({ container }) => container.securityContext?.runAsUser === 0)
+                .map(({ container, fieldPath }) => ({
+                    message: `**${container.name}** runAsUser is 0`,
+                    fieldPath: `${fieldPath}.securityContext.runAsUser`,
+                })),
+        ],
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-seccomp-required",
+            "Pod Security: Seccomp Profile",
+            "Seccomp profile must be explicitly set to one of the allowed values, RuntimeDefault or Localhost."),
+        level: "restricted",
+        check: pod => isWindows(pod) ? [] : pod.containers
+            .filter(({ container }) => (container.securityContext?.seccompProfile?.type ?? pod.spec.securityContext?.seccompProfile?.type) === undefined)
+            .map(({ container, fieldPath }) => ({
+                message: `**${container.name}** seccomp profile is not set`,
+                fieldPath: `${fieldPath}.securityContext.seccompProfile`,
+                fix: {
+                    description: `Set seccomp profile type to RuntimeDefault in the security context of ${container.name}`,
+                    apply: () => {
+                        container.securityContext ??= {};
+                        container.securityContext.seccompProfile = { type: "RuntimeDefault" };
+                    },
+                },
+            })),
+    },
+    {
+        metadata: newMetadata(
+            "pod-security-drop-capabilities",
+            "Pod Security: Dropped Capabilities",
+            "Containers must drop ALL capabilities, and are only permitted to add back the NET_BIND_SERVICE capability."),
+        level: "restricted",
+        check: pod => isWindows(pod) ? [] : pod.containers.flatMap(({ container, fieldPath }) => {
+            const violations: Violation[] = [];
+            const capabilities = container.securityContext?.capabilities;
+
+            if (!capabilities?.drop?.includes("ALL")) {
+                violations.push({
+                    message: `**${container.name}** doesn't drop ALL capabilities`,
+                    fieldPath: `${fieldPath}.securityContext.capabilities.drop`,
+                    fix: {
+                        description: `Drop ALL capabilities in the security context of ${container.name}`,
+                        apply: () => {
+                            container.securityContext ??= {};
+                            container.securityContext.capabilities ??= {};
+                            container.securityContext.capabilities.drop = [...(container.securityContext.capabilities.drop ?? []), "ALL"];
+                        },
+                    },
+                });
+            }
+
+            // Capabilities that are not allowed by the baseline level are already reported by its control.
+            for (const capability of capabilities?.add ?? []) {
+                if (capability !== "NET_BIND_SERVICE" && allowedBaselineCapabilities.includes(capability)) {
+                    violations.push({
+                        message: `**${container.name}** adds capability ${capability}`,
+                        fieldPath: `${fieldPath}.securityContext.capabilities.add`,
+                    });
+                }
+            }
+
+            return violations;
+        }),
+    },
+];
+
+/** Metadata of all the controls, e.g. to suppress the diagnostics of the pod security controls. */
+export const diagnosticMetadatas: DiagnosticMetadata[] = controls.map(control => control.metadata);
+
+export class Options {
+    /**
+     * Level that is checked for the namespaces that don't have a level in {@link namespaceLevels} or
+     * a {@link enforceLabel} label on the Namespace document in the build. Defaults to baseline.
+     */
+    level?: Level;
+
+    /** Levels of the namespaces by the namespace name. Takes precedence over the namespace labels. */
+    namespaceLevels?: Record<string, Level>;
+}
+
+export class Component extends AnemosComponent {
+    private options: Options;
+
+    constructor(options?: Options) {
+        super();
+
+        this.options = options ?? {};
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.sanitize, this.sanitize);
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    sanitize = (context: BuildContext) => {
+        this.options.level ??= "baseline";
+        this.options.namespaceLevels ??= {};
+
+        for (const level of [this.options.level, ...Object.values(this.options.namespaceLevels)]) {
+            if (!levels.includes(level)) {
+                throw new Error(`Invalid pod security level ${level}, must be one of: ${levels.join(", ")}`);
+            }
+        }
+    }
+
+    diagnose = (context: BuildContext) => {
+        const namespaceLabelLevels = getNamespaceLabelLevels(context);
+
+        for (const document of context.getAllDocuments()) {
+            const pod = getPod(document);
+            if (!pod) {
+                continue;
+            }
+
+            const namespace = document.metadata?.namespace;
+            const level = (namespace !== undefined
+                ? this.options.namespaceLevels![namespace] ?? namespaceLabelLevels.get(namespace)
+                : undefined) ?? this.options.level!;
+
+            for (const control of controls) {
+                if (!isLevelEnforced(level, control.level)) {
+                    continue;
+                }
+
+                for (const violation of control.check(pod)) {
+                    context.addDiagnostic({
+                        metadata: control.metadata,
+                        message: `${violation.message} *(${level})*`,
+                        document: document,
+                        fieldPath: violation.fieldPath,
+                        fix: violation.fix,
+                    });
+                }
+            }
+        }
+    }
+}
+
+/** Returns true if the controls of the given level are checked for the namespaces with the enforced level. */
+export function isLevelEnforced(enforcedLevel: Level, controlLevel: Level): boolean {
+    return levels.indexOf(enforcedLevel) >= levels.indexOf(controlLevel);
+}
+
+/** Returns the levels in the {@link enforceLabel} labels of the Namespace documents in the build. */
+function getNamespaceLabelLevels(context: BuildContext): Map<string, Level> {
+    const result = new Map<string, Level>();
+
+    for (const document of context.getAllDocuments()) {
+        if (!document.isNamespace()) {
+            continue;
+        }
+
+        const name = document.metadata?.name;
+        const level = document.metadata?.labels?.[enforceLabel] as Level | undefined;
+
+        if (name && level && levels.includes(level)) {
+            result.set(name, level);
+        }
+    }
+
+    return result;
+}
+
+/** Returns the pod spec of the workload with the paths of its containers, undefined if the document is not a workload. */
+export function getPod(document: Document): Pod | undefined {
+    if (!document.asWorkload()) {
+        return undefined;
+    }
+
+    // CronJobs have the pod template in the job template.
+    const cronJobTemplate = document.isCronJob() ? document.spec?.jobTemplate?.spec?.template : undefined;
+
+    const spec: PodSpec | undefined = document.isCronJob() ? cronJobTemplate?.spec : document.getWorkloadSpec();
+    if (!spec) {
+        return undefined;
+    }
+
+    const specFieldPath = document.isPod()
+        ? "spec"
+        : document.isCronJob()
+            ? "spec.jobTemplate.spec.template.spec"
+            : "spec.template.spec";
+
+    const containers: PodContainer[] = [];
+    const addContainers = (field: string, list?: Container[]) => {
+        (list ?? []).forEach((container, index) => containers.push({
+            container: container,
+            fieldPath: `${specFieldPath}.${field}[${index}]`,
+        }));
+    };
+
+    addContainers("initContainers", spec.initContainers);
+    addContainers("containers", spec.containers);
+    addContainers("ephemeralContainers", (spec as any).ephemeralContainers);
+
+    return {
+        document: document,
+        spec: spec,
+        metadata: document.isCronJob() ? cronJobTemplate?.metadata : document.getWorkloadMetadata(),
+        specFieldPath: specFieldPath,
+        containers: containers,
+    };
+}
+
+function isWindows(pod: Pod): boolean {
+    return pod.spec.os?.name === "windows";
+}
+
+/**
+ * Adds the pod security diagnostics to the builder. Replaces the existing pod security component, e.g. the one that is
+ * added with the default diagnostics, so that the levels can be configured with `add(builder, { level: "restricted" })`.
+ */
+export function add(builder: Builder, options?: Options): Component {
+    builder.removeComponent(componentType);
+
+    const component = new Component(options);
+    builder.addComponent(component);
+
+    return component;
+}
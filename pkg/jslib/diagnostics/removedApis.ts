@@ -0,0 +1,91 @@
+import { Component as AnemosComponent } from "@ohayocorp/anemos/component";
+import { Builder } from "@ohayocorp/anemos/builder";
+import { BuildContext } from "@ohayocorp/anemos/buildContext";
+import * as steps from "@ohayocorp/anemos/steps";
+import { DiagnosticMetadata, error, specs } from "@ohayocorp/anemos/diagnostic";
+import { formatReplacement, getMigrationFix } from "./deprecatedApis";
+
+export const componentType = "diagnostics/removed-apis";
+
+export const diagnosticMetadata: DiagnosticMetadata = {
+    id: "removed-api",
+    name: "Removed API",
+    description: `Documents that use API versions which are not served by the target Kubernetes version are rejected by the cluster.`,
+    severity: error,
+    categories: [specs]
+};
+
+export class Component extends AnemosComponent {
+    constructor() {
+        super();
+
+        this.setComponentType(componentType);
+        this.setIdentifier(componentType);
+
+        this.addAction(steps.diagnose, this.diagnose);
+    }
+
+    diagnose = (context: BuildContext) => {
+        const resourceInfo = context.kubernetesResourceInfo;
+
+        for (const document of context.getAllDocuments()) {
+            const apiVersion = document.apiVersion;
+            const kind = document.kind;
+
+            if (!apiVersion || !kind) {
+                continue;
+            }
+
+            let message: string | undefined;
+
+            const deprecation = resourceInfo.getApiDeprecation(apiVersion, kind);
+            if (deprecation) {
+                if (!deprecation.removed) {
+                    continue;
+                }
+
+                message = `${apiVersion} ${kind} is removed in Kubernetes ${deprecation.removedIn}, ${formatReplacement(deprecation)}`;
+            } else if (isBuiltInApiVersion(apiVersion) && !resourceInfo.contains(apiVersion, kind) && resourceInfo.containsKind(kind)) {
+                // API versions that are not in the known deprecations, e.g. alpha versions that are removed.
+                const servedApiVersions = resourceInfo.allResources()
+                    .filter(resource => resource.kind === kind && isBuiltInApiVersion(resource.apiVersion))
+                    .map(resource => resource.apiVersion)
+                    .sort();
+
+                if (servedApiVersions.length === 0) {
+                    continue;
+                }
+
+                message = `${apiVersion} ${kind} is not served by the target Kubernetes version, use ${servedApiVersions.join(" or ")} instead`;
+            } else {
+                continue;
+            }
+
+            context.addDiagnostic({
+                metadata: diagnosticMetadata,
+                message: message,
+                document: document,
+                fieldPath: "apiVersion",
+                fix: getMigrationFix(context, document),
+            });
+        }
+    }
+}
+
+// Only the API groups of Kubernetes are checked, custom resources may use the same kinds.
+function isBuiltInApiVersion(apiVersion: string): boolean {
+    const parts = apiVersion.split("/");
+    if (parts.length === 1) {
+        return true;
+    }
+
+    const group = parts[0];
+    return !group.includes(".") || group.endsWith(".k8s.io");
+}
+
+export function add(builder: Builder): Component {
+    const component = new Component();
+    builder.addComponent(component);
+
+    return component;
+}
@@ -65,6 +65,13 @@ export class Component extends AnemosComponent {
                 metadata: diagnosticMetadata,
                 message: `**${container.name}** runAsNonRoot is not set to true`,
                 document: document,
+                fix: {
+                    description: `Set runAsNonRoot to true in the security context of ${container.name}`,
+                    apply: () => {
+                        container.securityContext ??= {};
+                        container.securityContext.runAsNonRoot = true;
+                    }
+                },
             });
         }
     }
@@ -0,0 +1,35 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that suppresses the diagnostics that are accepted in the given baseline file right
+         * before the {@link steps.report} step, so that only the new diagnostics are reported or fail the build.
+         * @param path Path of the baseline file.
+         */
+        useDiagnosticsBaseline(path: string): Component;
+
+        /**
+         * Adds a {@link Component} that suppresses the diagnostics that are accepted in the given baseline file right
+         * before the {@link steps.report} step, so that only the new diagnostics are reported or fail the build.
+         * @param options Options for the diagnostics baseline.
+         */
+        useDiagnosticsBaseline(options: diagnosticsBaseline.Options): Component;
+    }
+}
+
+export declare namespace diagnosticsBaseline {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+        constructor(path: string);
+
+        /** Path of the baseline file that contains the accepted diagnostics. */
+        path: string;
+
+        /** Writes the current diagnostics into the baseline file before applying it, e.g. to accept the existing diagnostics. */
+        update?: boolean;
+    }
+}
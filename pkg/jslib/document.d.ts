@@ -1,3 +1,4 @@
+import { Builder } from "./builder";
 import { DocumentGroup } from "./documentGroup"
 import { ObjectMeta } from "./k8s/apimachinery/meta/v1";
 
@@ -36,6 +37,18 @@ export declare class Document {
     /** Apply and wait for this document before the given document. Documents must be in the same group. */
     provisionBefore(other: Document): void;
 
+    /**
+     * Returns the component that created this document and the components that modified it. Returns null if the
+     * document hasn't been added to the build yet or provenance tracking is not enabled, see {@link Builder.trackProvenance}.
+     */
+    getProvenance(): DocumentProvenance | null;
+
+    /**
+     * Returns where the document was created, i.e. the script call that created it and the locations of the YAML keys
+     * it was parsed from. Returns null for the documents that are not created by a script or from YAML text.
+     */
+    getSource(): DocumentSource | null;
+
     /**
      * The API version of the document.
      */
@@ -57,3 +70,61 @@ export declare class NewDocumentOptions {
     path?: string;
     documentGroup?: string;
 }
+
+/** Records which component created a document and which components modified it afterwards. */
+export declare class DocumentProvenance {
+    /** Component action that created the document. */
+    createdBy: ProvenanceEntry;
+
+    /** Component actions that modified the document, in the order they ran. */
+    modifiedBy: ProvenanceEntry[];
+
+    /** Returns the names of the components that modified the document, without duplicates. */
+    modifiedByComponentNames(): string[];
+}
+
+/** Points to a line and column in a source file. Line and column numbers start from 1, 0 means unknown. */
+export declare class SourceLocation {
+    file: string;
+    line: number;
+    column: number;
+
+    /** Returns the location in the form of "file:line:column". */
+    toString(): string;
+}
+
+/** Keeps where a document was created and where its fields come from. */
+export declare class DocumentSource {
+    /** Location of the script call that created the document. */
+    callSite?: SourceLocation;
+
+    /**
+     * Name of the template the document was rendered from, e.g. "mychart/templates/deployment.yaml" for Helm charts.
+     * Empty for the documents that are created by the scripts.
+     */
+    template: string;
+
+    /**
+     * Returns the location of the given field path, e.g. "spec.template.spec.containers[0].image". Falls back to the
+     * closest parent field with a known location and then to the call site.
+     */
+    getFieldLocation(fieldPath: string): SourceLocation | null;
+
+    /** Returns the paths of the fields that have a known location. */
+    getFieldPaths(): string[];
+}
+
+/** Identifies a component action that created or modified a document. */
+export declare class ProvenanceEntry {
+    componentType: string;
+    identifier: string;
+
+    /** Step of the action, e.g. "6 - Modify". */
+    step: string;
+
+    /** Stack trace of the place where the component was added to the builder. */
+    stackTrace: string;
+
+    /** Returns the name of the component in the form of "identifier (type)". */
+    componentName(): string;
+}
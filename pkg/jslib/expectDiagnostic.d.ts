@@ -0,0 +1,40 @@
+import { Component } from "./component";
+import { Severity } from "./diagnostic";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that fails the build during the {@link steps.report} step if the diagnostics don't
+         * match the given expectation. Useful for testing diagnostics with the `anemos test` command.
+         * @param options Conditions that the expected diagnostics match.
+         */
+        expectDiagnostic(options: expectDiagnostic.Options): Component;
+    }
+}
+
+export declare namespace expectDiagnostic {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+
+        /** Only matches the diagnostics with this id if set. */
+        id?: string;
+
+        /** Only matches the diagnostics with this severity if set. */
+        severity?: Severity;
+
+        /** Only matches the diagnostics of the document with this full path if set. */
+        documentPath?: string;
+
+        /** Only matches the diagnostics whose messages contain this text if set. */
+        messageContains?: string;
+
+        /** Exact number of matching diagnostics that are expected. At least one matching diagnostic is expected if not set. */
+        count?: number;
+
+        /** Expects no matching diagnostics. */
+        absent?: boolean;
+    }
+}
@@ -6,14 +6,61 @@ declare module "./builder" {
     export interface Builder {
         /**
          * Creates a document group from the Helm chart using the given values on
-         * {@link steps.generateResources} step. Chart identifier can be a local path or a URL.
+         * {@link steps.generateResources} step. Chart identifier can be a local path, a URL of a chart archive,
+         * an OCI reference such as `oci://registry.example.com/charts/chart:1.2.3`, or a `repo/chart` reference
+         * for the repositories that are added with `helm repo add`.
          */
         addHelmChart(chartIdentifier: string, releaseName: string, values?: string | object): void;
+
+        /**
+         * Creates a document group from the Helm chart that is downloaded from a Helm repository or an OCI
+         * registry using the given values on {@link steps.generateResources} step.
+         */
+        addHelmChart(chart: HelmChartReference, releaseName: string, values?: string | object): void;
+
+        /**
+         * Creates a document group from the Helm chart using the given options on {@link steps.generateResources} step,
+         * e.g. to set the namespace of the release or to report the unknown values.
+         */
+        addHelmChart(chart: string | HelmChartReference, options: HelmOptions): void;
     }
 }
 
 /**
- * Options for generating Helm charts.
+ * Identifies a chart that is stored in a classic Helm repository or in an OCI registry. Charts are
+ * resolved using the same rules as the Helm CLI. Repositories, the repository cache and the registry
+ * credentials are read from the Helm configuration, falling back to the Docker configuration for
+ * the registry credentials.
+ * @param repoUrl URL of the Helm repository, e.g. `https://charts.example.com` or `oci://registry.example.com/charts`.
+ * @param chart Name of the chart in the repository, or an OCI reference if the repository URL is empty.
+ * @param version Exact version or a semver range such as `^1.2.0`. Latest version is used if it is empty.
+ */
+export class HelmChartReference {
+    constructor(repoUrl: string, chart: string, version: string);
+
+    /**
+     * URL of the Helm repository. Leave empty for OCI references and for the charts in the repositories
+     * that are added with `helm repo add`.
+     */
+    repoUrl?: string;
+
+    /**
+     * Name of the chart in the repository given by {@link repoUrl}, a `repo/chart` reference, or an OCI
+     * reference such as `oci://registry.example.com/charts/chart`.
+     */
+    chart: string;
+
+    /** Exact version or a semver range such as `^1.2.0`. Latest version is used if it is empty. */
+    version?: string;
+
+    /** Connects to the OCI registry using plain HTTP instead of HTTPS, e.g. for local registries. */
+    plainHttp?: boolean;
+}
+
+/**
+ * Options for generating Helm charts. Values are validated against the `values.schema.json` files of the chart
+ * and its subcharts before rendering. Each violation is reported as an `invalid-helm-values` diagnostic, the chart
+ * is not rendered and the build fails before the output step if there are any violations.
  * @param releaseName The name of the Helm release.
  * @param namespace The namespace to use for the Helm release.
  * @param values Optional values file to use for the Helm chart.
@@ -29,6 +76,12 @@ export class HelmOptions {
 
     /** Optional values file to use for the Helm chart. */
     values?: string | object;
+
+    /**
+     * Reports the values that don't exist in the `values.yaml` or the `values.schema.json` of the chart as
+     * `unknown-helm-value` diagnostics, which are usually typos. Defaults to false.
+     */
+    checkUnknownValues?: boolean;
 }
 
 /**
@@ -41,6 +94,10 @@ export class HelmChart {
     constructor(path: string);
     constructor(data: Uint8Array);
 
-    /** Creates a document group from the Helm chart using the provided options. */
+    /**
+     * Creates a document group from the Helm chart using the provided options. Install and upgrade hooks are
+     * added to the context as separate document groups for each hook weight, which are provisioned before or
+     * after the returned document group.
+     */
     generate(context: BuildContext, options: HelmOptions): DocumentGroup;
 }
\ No newline at end of file
@@ -1,20 +1,29 @@
+export * from '@ohayocorp/anemos/admissionPolicies';
 export * from '@ohayocorp/anemos/apply';
+export * from '@ohayocorp/anemos/applyFixes';
 export * from '@ohayocorp/anemos/buildContext';
 export * from '@ohayocorp/anemos/builder';
 export * from '@ohayocorp/anemos/builderOptions';
+export * from '@ohayocorp/anemos/compareOutput';
 export * from '@ohayocorp/anemos/component';
 export * from '@ohayocorp/anemos/deleteOutputDirectory';
 export * from '@ohayocorp/anemos/diagnostic';
+export * from '@ohayocorp/anemos/diagnosticsBaseline';
 export * as diagnostics from '@ohayocorp/anemos/diagnostics';
 export * from '@ohayocorp/anemos/document';
 export * from '@ohayocorp/anemos/documentGroup';
 export * as environmentType from '@ohayocorp/anemos/environmentType';
+export * from '@ohayocorp/anemos/expectDiagnostic';
 export * from '@ohayocorp/anemos/file';
 export * from '@ohayocorp/anemos/helm';
 export * as k8s from '@ohayocorp/anemos/k8s';
 export * as kubernetesDistribution from '@ohayocorp/anemos/kubernetesDistribution';
 export * from '@ohayocorp/anemos/kubernetesResourceInfo';
+export * from '@ohayocorp/anemos/mutationLog';
 export * from '@ohayocorp/anemos/parsing';
+export * from '@ohayocorp/anemos/profile';
+export * from '@ohayocorp/anemos/profiler';
+export * from '@ohayocorp/anemos/provenance';
 export * from '@ohayocorp/anemos/provisioner';
 export * from '@ohayocorp/anemos/quantity';
 export * from '@ohayocorp/anemos/report';
@@ -24,6 +33,7 @@ export * from '@ohayocorp/anemos/serialization';
 export * from '@ohayocorp/anemos/step';
 export * as steps from '@ohayocorp/anemos/steps';
 export * from '@ohayocorp/anemos/stringExtensions';
+export * from '@ohayocorp/anemos/writeDiagnostics';
 export * from '@ohayocorp/anemos/writeDocuments';
 export * from '@ohayocorp/anemos/writeReports';
 
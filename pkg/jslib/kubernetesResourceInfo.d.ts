@@ -3,7 +3,7 @@
  * It contains the API version, kind, and whether the resource is namespaced.
  */
 export declare class KubernetesResource {
-    constructor(apiVersion: string, kind: string, isNamespaced: boolean);
+    constructor(apiVersion: string, kind: string, isNamespaced: boolean, schema?: Record<string, any>);
     
     /** The API version of the resource, e.g. "v1", "apps/v1". */
     apiVersion: string;
@@ -13,6 +13,12 @@ export declare class KubernetesResource {
 
     /** Indicates whether the resource is namespaced (true) or cluster-scoped (false). */
     isNamespaced: boolean;
+
+    /**
+     * OpenAPI v3 schema of the resource, i.e. the `openAPIV3Schema` field of a CRD version. Custom resources
+     * of this kind are validated against the schema if it is set.
+     */
+    schema?: Record<string, any>;
 }
 
 /**
@@ -34,9 +40,38 @@ export declare class KubernetesResourceInfo {
     /** Returns true if the given kind exists in the target cluster. This ignores the apiVersion field. */
     containsKind(kind: string): boolean;
 
+    /**
+     * Returns the deprecation of the given API version of the kind if it is deprecated or removed in the target
+     * cluster, null otherwise. E.g. returns the deprecation of policy/v1beta1 PodDisruptionBudget for Kubernetes 1.21
+     * and later versions.
+     */
+    getApiDeprecation(apiVersion: string, kind: string): ApiDeprecation | null;
+
     /**
      * Returns true if the given API resource is namespaced. E.g. returns true for v1/Pod,
      * false for rbac.authorization.k8s.io/v1/ClusterRole.
      */
     isNamespaced(apiVersion: string, kind: string): boolean;
-}
\ No newline at end of file
+}
+
+/**
+ * Describes an API version of a kind that is deprecated or removed in the target cluster.
+ */
+export declare class ApiDeprecation {
+    private constructor();
+
+    apiVersion: string;
+    kind: string;
+
+    /** Kubernetes version in which the API version is deprecated, e.g. "1.21". */
+    deprecatedIn: string;
+
+    /** Kubernetes version in which the API version is removed, e.g. "1.25". Empty if the removal is not scheduled. */
+    removedIn: string;
+
+    /** API version that replaces the deprecated one, e.g. "policy/v1". Empty if there is no replacement. */
+    replacement: string;
+
+    /** True if the API version is not served by the target cluster anymore. */
+    removed: boolean;
+}
@@ -0,0 +1,29 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Enables the mutation log and adds a {@link Component} that writes the changes each component made to
+         * the documents as RFC 6902 JSON Patch operations right before the {@link steps.output} step. The log can
+         * also be queried with {@link BuildContext.getMutationLog}.
+         * @param options Options for the mutation log.
+         */
+        logMutations(options?: mutationLog.Options): Component;
+    }
+}
+
+export declare namespace mutationLog {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+        constructor(componentTypes: string[]);
+
+        /**
+         * Only includes the changes made by the components with these types in the report, e.g. ["set-labels"].
+         * Changes made by all components are included if empty.
+         */
+        componentTypes?: string[];
+    }
+}
@@ -0,0 +1,25 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Enables profiling and adds a {@link Component} that writes the time spent in each step and component
+         * as a report right before the {@link steps.output} step. A Chrome trace event file that also contains
+         * the output and apply steps, Helm chart rendering and YAML serialization is written after the build.
+         * @param options Options for profiling.
+         */
+        profile(options?: profile.Options): Component;
+    }
+}
+
+export declare namespace profile {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+
+        /** Path of the Chrome trace event file. Defaults to profile/trace.json under the output path. */
+        traceFilePath?: string;
+    }
+}
@@ -0,0 +1,14 @@
+/**
+ * Measures the time spent in each action of the components, Helm chart rendering and YAML serialization
+ * during the build.
+ */
+export declare class Profiler {
+    /** Path of the Chrome trace event file that is written after the build. Nothing is written if empty. */
+    traceFilePath: string;
+
+    /** Returns a markdown document that contains the durations grouped by step, component, Helm chart and serialization. */
+    markdownSummary(): string;
+
+    /** Writes the measured spans in the Chrome trace event format which can be opened with chrome://tracing or Perfetto. */
+    writeChromeTrace(path: string): void;
+}
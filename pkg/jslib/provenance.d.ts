@@ -0,0 +1,31 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Enables recording the components that create and modify each document and adds a {@link Component} that
+         * writes the provenance of the documents as a report during the {@link steps.report} step. Provenance of a
+         * document can be queried with {@link Document.getProvenance}.
+         * @param options Options for provenance tracking.
+         */
+        trackProvenance(options?: provenance.Options): Component;
+    }
+}
+
+export declare namespace provenance {
+    export const componentType: string;
+
+    export class Options {
+        constructor();
+
+        /**
+         * Writes the components that created and modified each document as annotations with the
+         * "provenance.anemos.sh/" prefix right before the {@link steps.output} step.
+         */
+        writeAnnotations?: boolean;
+
+        /** Skips writing the provenance report. */
+        disableReport?: boolean;
+    }
+}
@@ -1,4 +1,5 @@
 import { Component } from "./component";
+import { Category, DiagnosticMetadata, Severity } from "./diagnostic";
 import * as steps from "./steps";
 
 declare module "./builder" {
@@ -6,7 +7,9 @@ declare module "./builder" {
         /**
          * Adds a {@link Component} that reports diagnostics.
          * This component is used to generate diagnostic reports during the build process.
-         * It is executed during the {@link steps.report} step.
+         * It is executed during the {@link steps.report} step. If {@link reportDiagnostics.Options.failOn} is set,
+         * the build fails before the {@link steps.output} step when there is a diagnostic with that severity or above.
+         * Diagnostics whose metadata sets {@link DiagnosticMetadata.failsBuild} always fail the build.
          * @param options Options for reporting diagnostics.
          */
         reportDiagnostics(options?: reportDiagnostics.Options): Component;
@@ -17,5 +20,13 @@ export declare namespace reportDiagnostics {
     export const componentType: string;
     
     export class Options {
+        /**
+         * Fails the build before the output step if there is a diagnostic with this severity or a more severe one.
+         * Diagnostics don't fail the build if not set.
+         */
+        failOn?: Severity;
+
+        /** Only the diagnostics that have at least one of these categories fail the build, e.g. ["security"]. */
+        failOnCategories?: Category[];
     }
 }
\ No newline at end of file
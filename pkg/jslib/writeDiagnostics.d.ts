@@ -0,0 +1,28 @@
+import { Component } from "./component";
+import * as steps from "./steps";
+
+declare module "./builder" {
+    export interface Builder {
+        /**
+         * Adds a {@link Component} that writes the diagnostics to the diagnostics directory of the output as SARIF 2.1.0
+         * and JUnit XML files during the {@link steps.output} step. Results link to the output files of the documents,
+         * e.g. output/manifests/app/deployment.yaml, so that they can be shown in code scanning dashboards and CI test tabs.
+         * @param options Options for writing diagnostics.
+         */
+        writeDiagnostics(options?: writeDiagnostics.Options): Component;
+    }
+}
+
+export type DiagnosticsOutputType = string;
+
+export const sarif: DiagnosticsOutputType;
+export const junit: DiagnosticsOutputType;
+
+export declare namespace writeDiagnostics {
+    export const componentType: string;
+
+    export class Options {
+        /** File formats the diagnostics are written in. Defaults to both SARIF and JUnit. */
+        outputTypes?: DiagnosticsOutputType[];
+    }
+}
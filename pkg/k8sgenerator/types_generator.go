@@ -1,7 +1,6 @@
 package main
 
 import (
-	_ "embed"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/ohayocorp/anemos/pkg/core"
+	"github.com/ohayocorp/anemos/pkg/k8sschema"
 	"github.com/ohayocorp/anemos/pkg/util"
 )
 
@@ -18,8 +18,7 @@ const packageBase = "github.com/ohayocorp/anemos/pkg/k8s"
 //go:generate go run .
 
 var (
-	//go:embed k8s-openapi-spec-1.34.json
-	openAPISpec1_34 []byte
+	openAPISpec1_34 = k8sschema.OpenAPISpec1_34
 	typeMappings    map[string]*typeInfo
 
 	// Output directories for generated Go and TypeScript files.
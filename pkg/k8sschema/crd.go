@@ -0,0 +1,181 @@
+package k8sschema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/ohayocorp/anemos/pkg/util"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+)
+
+// CRDSchemas contains the compiled schemas of custom resources, e.g. the schemas of the CRDs in a build.
+type CRDSchemas struct {
+	schemas map[string]*crdSchema
+}
+
+type crdSchema struct {
+	schema *Schema
+	// Validator of the x-kubernetes-validations rules, nil if the schema doesn't have any rules.
+	celValidator *cel.Validator
+	structural   *structuralschema.Structural
+}
+
+func NewCRDSchemas() *CRDSchemas {
+	return &CRDSchemas{
+		schemas: map[string]*crdSchema{},
+	}
+}
+
+// Adds the schemas of all the versions of the given CustomResourceDefinition object. Versions without
+// a schema are skipped.
+func (schemas *CRDSchemas) AddCRD(crd map[string]any) error {
+	definition := &apiextensionsv1.CustomResourceDefinition{}
+	if err := util.ConvertWithJson(crd, definition); err != nil {
+		return fmt.Errorf("can't parse CRD: %w", err)
+	}
+
+	for _, version := range definition.Spec.Versions {
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+
+		apiVersion := definition.Spec.Group + "/" + version.Name
+		kind := definition.Spec.Names.Kind
+
+		if err := schemas.addSchema(apiVersion, kind, version.Schema.OpenAPIV3Schema); err != nil {
+			return fmt.Errorf("can't compile schema of CRD %s: %w", definition.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Adds the given OpenAPI v3 schema, i.e. the openAPIV3Schema field of a CRD version, for the given kind.
+func (schemas *CRDSchemas) AddSchema(apiVersion string, kind string, openAPIV3Schema map[string]any) error {
+	props := &apiextensionsv1.JSONSchemaProps{}
+	if err := util.ConvertWithJson(openAPIV3Schema, props); err != nil {
+		return fmt.Errorf("can't parse schema of %s/%s: %w", apiVersion, kind, err)
+	}
+
+	if err := schemas.addSchema(apiVersion, kind, props); err != nil {
+		return fmt.Errorf("can't compile schema of %s/%s: %w", apiVersion, kind, err)
+	}
+
+	return nil
+}
+
+// Returns true if there is a schema for the given kind.
+func (schemas *CRDSchemas) Contains(apiVersion string, kind string) bool {
+	return schemas.schemas[getKindKey(apiVersion, kind)] != nil
+}
+
+// Validates the given custom resource against the schema of its kind, including the enum, pattern and required
+// fields, and the x-kubernetes-validations rules. Returns false if there is no schema for the kind of the object.
+func (schemas *CRDSchemas) Validate(apiVersion string, kind string, object map[string]any) ([]*ValidationError, bool) {
+	schema := schemas.schemas[getKindKey(apiVersion, kind)]
+	if schema == nil {
+		return nil, false
+	}
+
+	errors := ValidateWithResolver(schema.schema, object, nil)
+
+	if schema.celValidator != nil {
+		// CEL rules expect the values that are decoded from JSON, e.g. int64 instead of int.
+		unstructured := map[string]any{}
+		if err := util.ConvertWithJson(object, &unstructured); err != nil {
+			errors = append(errors, &ValidationError{
+				Path:    []any{},
+				Type:    ValidationErrorTypeWrongType,
+				Message: fmt.Sprintf("can't convert object for rule validation: %v", err),
+			})
+
+			return errors, true
+		}
+
+		fieldErrors, _ := schema.celValidator.Validate(
+			context.Background(), nil, schema.structural, unstructured, nil, celconfig.RuntimeCELCostBudget)
+
+		for _, fieldError := range fieldErrors {
+			message := fieldError.Detail
+			if message == "" {
+				message = fieldError.Error()
+			}
+
+			errors = append(errors, &ValidationError{
+				Path:    parseFieldPath(fieldError.Field),
+				Type:    ValidationErrorTypeRuleViolation,
+				Message: message,
+			})
+		}
+	}
+
+	return errors, true
+}
+
+func (schemas *CRDSchemas) addSchema(apiVersion string, kind string, props *apiextensionsv1.JSONSchemaProps) error {
+	schema := &Schema{}
+	if err := util.ConvertWithJson(props, schema); err != nil {
+		return err
+	}
+
+	// Type meta and object meta fields are accepted even if the schema doesn't list them.
+	if schema.Properties == nil {
+		schema.Properties = map[string]*Schema{}
+	}
+
+	for _, field := range []string{"apiVersion", "kind"} {
+		if schema.Properties[field] == nil {
+			schema.Properties[field] = &Schema{Type: "string"}
+		}
+	}
+
+	if schema.Properties["metadata"] == nil {
+		schema.Properties["metadata"] = &Schema{Type: "object"}
+	}
+
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(props, internal, nil); err != nil {
+		return err
+	}
+
+	structural, err := structuralschema.NewStructural(internal)
+	if err != nil {
+		return fmt.Errorf("schema is not structural: %w", err)
+	}
+
+	schemas.schemas[getKindKey(apiVersion, kind)] = &crdSchema{
+		schema:       schema,
+		celValidator: cel.NewValidator(structural, true, celconfig.PerCallLimit),
+		structural:   structural,
+	}
+
+	return nil
+}
+
+var fieldPathSegmentRegex = regexp.MustCompile(`([^.\[\]]+)|\[([^\]]*)\]`)
+
+// Parses the field paths of the API server errors, e.g. "spec.containers[0]" or "metadata.labels[app]".
+func parseFieldPath(fieldPath string) []any {
+	path := []any{}
+
+	for _, match := range fieldPathSegmentRegex.FindAllStringSubmatch(fieldPath, -1) {
+		if match[1] != "" {
+			path = append(path, match[1])
+			continue
+		}
+
+		if index, err := strconv.Atoi(match[2]); err == nil {
+			path = append(path, index)
+		} else {
+			path = append(path, match[2])
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,88 @@
+package k8sschema
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateCustomResource(t *testing.T) {
+	crd := map[string]any{}
+	err := yaml.Unmarshal([]byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+spec:
+  group: example.com
+  names:
+    kind: Database
+    plural: databases
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required:
+                - engine
+              x-kubernetes-validations:
+                - rule: self.minReplicas <= self.maxReplicas
+                  message: minReplicas must not be greater than maxReplicas
+              properties:
+                engine:
+                  type: string
+                  enum:
+                    - postgres
+                    - mysql
+                name:
+                  type: string
+                  pattern: ^[a-z]+$
+                minReplicas:
+                  type: integer
+                maxReplicas:
+                  type: integer
+`), &crd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := NewCRDSchemas()
+	if err := schemas.AddCRD(crd); err != nil {
+		t.Fatal(err)
+	}
+
+	document := map[string]any{}
+	err = yaml.Unmarshal([]byte(`
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+spec:
+  engine: oracle
+  name: Invalid
+  minReplicas: 3
+  maxReplicas: 1
+  size: 10
+`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errors, ok := schemas.Validate("example.com/v1", "Database", document)
+	if !ok {
+		t.Fatal("expected schema for example.com/v1 Database")
+	}
+
+	assertValidationErrors(t, errors, []expectedError{
+		{path: []any{"spec", "engine"}, errorType: ValidationErrorTypeInvalidValue},
+		{path: []any{"spec", "name"}, errorType: ValidationErrorTypeInvalidValue},
+		{path: []any{"spec", "size"}, errorType: ValidationErrorTypeUnknownField},
+		{path: []any{"spec"}, errorType: ValidationErrorTypeRuleViolation},
+	})
+}
@@ -0,0 +1,203 @@
+// Package k8sschema validates Kubernetes objects against the OpenAPI schemas of the Kubernetes API.
+// Schemas are embedded into the binary so that the validation works offline.
+package k8sschema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	//go:embed k8s-openapi-spec-1.34.json
+	OpenAPISpec1_34 []byte
+
+	// Embedded OpenAPI specs for each Kubernetes minor version.
+	openAPISpecs = map[string][]byte{
+		"1.34": OpenAPISpec1_34,
+	}
+
+	specCache     = map[string]*Spec{}
+	specCacheLock sync.Mutex
+)
+
+// Definitions that are serialized as strings but also accept numbers, e.g. "cpu: 1" for quantities.
+var intOrStringDefinitions = []string{
+	"io.k8s.apimachinery.pkg.api.resource.Quantity",
+	"io.k8s.apimachinery.pkg.util.intstr.IntOrString",
+}
+
+// Schema is a subset of the OpenAPI v2 and v3 schema objects that is enough to validate Kubernetes objects.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *SchemaOrBool      `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	PreserveUnknownFields bool                `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	IntOrString           bool                `json:"x-kubernetes-int-or-string,omitempty"`
+	EmbeddedResource      bool                `json:"x-kubernetes-embedded-resource,omitempty"`
+	GroupVersionKinds     []*GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// SchemaOrBool is the value of additionalProperties which is either a schema or a boolean.
+type SchemaOrBool struct {
+	Allows bool
+	Schema *Schema
+}
+
+func (value *SchemaOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &value.Allows); err == nil {
+		return nil
+	}
+
+	value.Allows = true
+	value.Schema = &Schema{}
+
+	return json.Unmarshal(data, value.Schema)
+}
+
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Returns the API version of the group version kind, e.g. "apps/v1" or "v1" for the core group.
+func (gvk *GroupVersionKind) ApiVersion() string {
+	if gvk.Group == "" {
+		return gvk.Version
+	}
+
+	return gvk.Group + "/" + gvk.Version
+}
+
+// Spec contains the schemas of the Kubernetes objects of a Kubernetes version.
+type Spec struct {
+	Version string
+
+	definitions map[string]*Schema
+	kinds       map[string]*Schema
+}
+
+type openAPISpec struct {
+	Definitions map[string]*Schema `json:"definitions"`
+}
+
+// Returns the spec of the given Kubernetes version. Uses the spec of the closest embedded version if there is no
+// spec for the exact minor version, i.e. the latest version before the given version or the earliest version.
+func GetSpec(version *semver.Version) (*Spec, error) {
+	specVersion := GetSpecVersion(version)
+
+	specCacheLock.Lock()
+	defer specCacheLock.Unlock()
+
+	if spec, ok := specCache[specVersion]; ok {
+		return spec, nil
+	}
+
+	spec, err := ParseSpec(specVersion, openAPISpecs[specVersion])
+	if err != nil {
+		return nil, err
+	}
+
+	specCache[specVersion] = spec
+
+	return spec, nil
+}
+
+// Parses the given OpenAPI v2 spec that is returned from the "/openapi/v2" endpoint of the Kubernetes API server.
+func ParseSpec(version string, data []byte) (*Spec, error) {
+	openAPI := &openAPISpec{}
+	if err := json.Unmarshal(data, openAPI); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec of Kubernetes %s: %w", version, err)
+	}
+
+	spec := &Spec{
+		Version:     version,
+		definitions: openAPI.Definitions,
+		kinds:       map[string]*Schema{},
+	}
+
+	for _, name := range sortedKeys(openAPI.Definitions) {
+		schema := openAPI.Definitions[name]
+
+		if slices.Contains(intOrStringDefinitions, name) {
+			schema.IntOrString = true
+		}
+
+		// Some definitions such as DeleteOptions are shared by many groups, only keep the definitions
+		// that belong to a single kind since the shared ones are not stored as objects.
+		if len(schema.GroupVersionKinds) != 1 {
+			continue
+		}
+
+		gvk := schema.GroupVersionKinds[0]
+		spec.kinds[getKindKey(gvk.ApiVersion(), gvk.Kind)] = schema
+	}
+
+	return spec, nil
+}
+
+// Returns the schema of the object with the given API version and kind, nil if the kind is unknown.
+func (spec *Spec) GetSchema(apiVersion string, kind string) *Schema {
+	return spec.kinds[getKindKey(apiVersion, kind)]
+}
+
+// Returns the schema that the given reference points to, e.g. "#/definitions/io.k8s.api.core.v1.PodSpec".
+// Returns nil if there is no such definition.
+func (spec *Spec) resolve(ref string) *Schema {
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	return spec.definitions[name]
+}
+
+func getKindKey(apiVersion string, kind string) string {
+	return apiVersion + "/" + kind
+}
+
+// Returns the embedded spec version that is used for the given Kubernetes version, e.g. "1.34". It is the same as
+// the minor version of the given version if there is an embedded spec for it.
+func GetSpecVersion(version *semver.Version) string {
+	versions := []*semver.Version{}
+	for v := range openAPISpecs {
+		versions = append(versions, semver.MustParse(v))
+	}
+
+	slices.SortFunc(versions, func(a, b *semver.Version) int {
+		return a.Compare(b)
+	})
+
+	closest := versions[0]
+
+	if version != nil {
+		for _, v := range versions {
+			if v.Major() < version.Major() || (v.Major() == version.Major() && v.Minor() <= version.Minor()) {
+				closest = v
+			}
+		}
+	}
+
+	return fmt.Sprintf("%d.%d", closest.Major(), closest.Minor())
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
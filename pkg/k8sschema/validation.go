@@ -0,0 +1,268 @@
+package k8sschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type ValidationErrorType string
+
+const (
+	ValidationErrorTypeUnknownField    ValidationErrorType = "unknown-field"
+	ValidationErrorTypeWrongType       ValidationErrorType = "wrong-type"
+	ValidationErrorTypeMissingRequired ValidationErrorType = "missing-required"
+	ValidationErrorTypeInvalidValue    ValidationErrorType = "invalid-value"
+	ValidationErrorTypeRuleViolation   ValidationErrorType = "rule-violation"
+)
+
+// ValidationError describes a field that doesn't conform to the schema.
+type ValidationError struct {
+	// Path of the field, each segment is either a string key or an int index.
+	Path    []any
+	Type    ValidationErrorType
+	Message string
+}
+
+// Validates the given object, e.g. a document unmarshaled from YAML, against the schema of its kind.
+// Returns false if there is no schema for the kind of the object.
+func (spec *Spec) Validate(apiVersion string, kind string, object map[string]any) ([]*ValidationError, bool) {
+	schema := spec.GetSchema(apiVersion, kind)
+	if schema == nil {
+		return nil, false
+	}
+
+	return ValidateWithResolver(schema, object, spec.resolve), true
+}
+
+// Validates the given value against the given schema. References are resolved using the given function,
+// fields that refer to unknown definitions are not validated.
+func ValidateWithResolver(schema *Schema, value any, resolve func(ref string) *Schema) []*ValidationError {
+	validator := &validator{
+		resolve:  resolve,
+		errors:   []*ValidationError{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+
+	validator.validate(schema, value, []any{})
+
+	return validator.errors
+}
+
+type validator struct {
+	resolve  func(ref string) *Schema
+	errors   []*ValidationError
+	patterns map[string]*regexp.Regexp
+}
+
+func (validator *validator) addError(path []any, errorType ValidationErrorType, format string, args ...any) {
+	validator.errors = append(validator.errors, &ValidationError{
+		Path:    slices.Clone(path),
+		Type:    errorType,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (validator *validator) validate(schema *Schema, value any, path []any) {
+	schema = validator.resolveSchema(schema)
+	if schema == nil || value == nil {
+		return
+	}
+
+	for _, subSchema := range schema.AllOf {
+		validator.validate(subSchema, value, path)
+	}
+
+	if schema.IntOrString || schema.Format == "int-or-string" {
+		if !isString(value) && !isInteger(value) && !isNumber(value) {
+			validator.addError(path, ValidationErrorTypeWrongType, "expected integer or string, got %s", getTypeName(value))
+		}
+
+		return
+	}
+
+	schemaType := schema.Type
+	if schemaType == "" && schema.Properties != nil {
+		schemaType = "object"
+	}
+
+	switch schemaType {
+	case "object":
+		validator.validateObject(schema, value, path)
+	case "array":
+		validator.validateArray(schema, value, path)
+	case "string":
+		if !isString(value) {
+			validator.addError(path, ValidationErrorTypeWrongType, "expected string, got %s", getTypeName(value))
+		}
+	case "integer":
+		if !isInteger(value) {
+			validator.addError(path, ValidationErrorTypeWrongType, "expected integer, got %s", getTypeName(value))
+		}
+	case "number":
+		if !isNumber(value) {
+			validator.addError(path, ValidationErrorTypeWrongType, "expected number, got %s", getTypeName(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			validator.addError(path, ValidationErrorTypeWrongType, "expected boolean, got %s", getTypeName(value))
+		}
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(item any) bool { return fmt.Sprint(item) == fmt.Sprint(value) }) {
+		values := []string{}
+		for _, item := range schema.Enum {
+			values = append(values, fmt.Sprint(item))
+		}
+
+		validator.addError(path, ValidationErrorTypeInvalidValue, "unsupported value %v, must be one of %s", value, strings.Join(values, ", "))
+	}
+
+	if schema.Pattern != "" && isString(value) {
+		validator.validatePattern(schema.Pattern, value.(string), path)
+	}
+}
+
+func (validator *validator) validatePattern(pattern string, value string, path []any) {
+	expression, ok := validator.patterns[pattern]
+	if !ok {
+		// Invalid patterns are rejected by the API server when the CRD is applied, ignore them here.
+		expression, _ = regexp.Compile(pattern)
+		validator.patterns[pattern] = expression
+	}
+
+	if expression != nil && !expression.MatchString(value) {
+		validator.addError(path, ValidationErrorTypeInvalidValue, "value %q doesn't match the pattern %s", value, pattern)
+	}
+}
+
+func (validator *validator) validateObject(schema *Schema, value any, path []any) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		validator.addError(path, ValidationErrorTypeWrongType, "expected object, got %s", getTypeName(value))
+		return
+	}
+
+	for _, required := range schema.Required {
+		if object[required] == nil {
+			validator.addError(append(path, required), ValidationErrorTypeMissingRequired, "missing required field %s", required)
+		}
+	}
+
+	// Objects without properties are free form, e.g. RawExtension.
+	if schema.Properties == nil && schema.AdditionalProperties == nil {
+		return
+	}
+
+	for _, key := range sortedKeys(object) {
+		fieldPath := append(path, key)
+		fieldValue := object[key]
+
+		if property, ok := schema.Properties[key]; ok {
+			validator.validate(property, fieldValue, fieldPath)
+			continue
+		}
+
+		if schema.AdditionalProperties != nil {
+			if schema.AdditionalProperties.Schema != nil {
+				validator.validate(schema.AdditionalProperties.Schema, fieldValue, fieldPath)
+				continue
+			}
+
+			if schema.AdditionalProperties.Allows {
+				continue
+			}
+		}
+
+		if schema.PreserveUnknownFields || isEmbeddedResourceField(schema, key) {
+			continue
+		}
+
+		validator.addError(fieldPath, ValidationErrorTypeUnknownField, "unknown field %s", key)
+	}
+}
+
+func (validator *validator) validateArray(schema *Schema, value any, path []any) {
+	array, ok := value.([]any)
+	if !ok {
+		validator.addError(path, ValidationErrorTypeWrongType, "expected array, got %s", getTypeName(value))
+		return
+	}
+
+	if schema.Items == nil {
+		return
+	}
+
+	for i, item := range array {
+		validator.validate(schema.Items, item, append(path, i))
+	}
+}
+
+// Follows the references until a schema with a type is found. Returns nil if a reference can't be resolved.
+func (validator *validator) resolveSchema(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != ""; depth++ {
+		if depth > 32 || validator.resolve == nil {
+			return nil
+		}
+
+		schema = validator.resolve(schema.Ref)
+	}
+
+	return schema
+}
+
+// Embedded resources always accept the type meta fields even if they are not listed in the properties.
+func isEmbeddedResourceField(schema *Schema, key string) bool {
+	return schema.EmbeddedResource && (key == "apiVersion" || key == "kind" || key == "metadata")
+}
+
+func isString(value any) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+func isInteger(value any) bool {
+	switch value := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float32:
+		return float64(value) == math.Trunc(float64(value))
+	case float64:
+		return value == math.Trunc(value)
+	default:
+		return false
+	}
+}
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func getTypeName(value any) string {
+	switch {
+	case isString(value):
+		return "string"
+	case isInteger(value):
+		return "integer"
+	case isNumber(value):
+		return "number"
+	}
+
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,116 @@
+package k8sschema
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateDeployment(t *testing.T) {
+	spec, err := GetSpec(semver.MustParse("1.34"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := map[string]any{}
+	err = yaml.Unmarshal([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+spec:
+  replicas: "2"
+  selector:
+    matchLabels:
+      app: app
+  template:
+    spec:
+      containers:
+        - image: nginx
+          imagePullPolcy: Always
+          ports:
+            - containerPort: 80
+          resources:
+            requests:
+              cpu: 1
+              memory: 128Mi
+`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errors, ok := spec.Validate("apps/v1", "Deployment", document)
+	if !ok {
+		t.Fatal("expected schema for apps/v1 Deployment")
+	}
+
+	assertValidationErrors(t, errors, []expectedError{
+		{path: []any{"spec", "replicas"}, errorType: ValidationErrorTypeWrongType},
+		{path: []any{"spec", "template", "spec", "containers", 0, "name"}, errorType: ValidationErrorTypeMissingRequired},
+		{path: []any{"spec", "template", "spec", "containers", 0, "imagePullPolcy"}, errorType: ValidationErrorTypeUnknownField},
+	})
+}
+
+func TestUnknownKind(t *testing.T) {
+	spec, err := GetSpec(semver.MustParse("1.20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := spec.Validate("example.com/v1", "Custom", map[string]any{}); ok {
+		t.Error("expected no schema for a custom resource")
+	}
+}
+
+func TestGetSpecVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.34.2": "1.34",
+		"1.36.0": "1.34",
+		"1.20.0": "1.34",
+	}
+
+	for version, expected := range tests {
+		if actual := GetSpecVersion(semver.MustParse(version)); actual != expected {
+			t.Errorf("expected spec version %s for %s, got %s", expected, version, actual)
+		}
+	}
+
+	if actual := GetSpecVersion(nil); actual != "1.34" {
+		t.Errorf("expected the earliest spec version without a version, got %s", actual)
+	}
+}
+
+type expectedError struct {
+	path      []any
+	errorType ValidationErrorType
+}
+
+func assertValidationErrors(t *testing.T, errors []*ValidationError, expected []expectedError) {
+	t.Helper()
+
+	if len(errors) != len(expected) {
+		for _, e := range errors {
+			t.Logf("%v %s %s", e.Path, e.Type, e.Message)
+		}
+
+		t.Fatalf("expected %d errors, got %d", len(expected), len(errors))
+	}
+
+	for i, e := range expected {
+		actual := errors[i]
+		if actual.Type != e.errorType || len(actual.Path) != len(e.path) {
+			t.Errorf("expected %s at %v, got %s at %v", e.errorType, e.path, actual.Type, actual.Path)
+			continue
+		}
+
+		for j := range e.path {
+			if actual.Path[j] != e.path[j] {
+				t.Errorf("expected %s at %v, got %s at %v", e.errorType, e.path, actual.Type, actual.Path)
+				break
+			}
+		}
+	}
+}
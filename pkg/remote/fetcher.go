@@ -0,0 +1,305 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Directory in the project that contains the vendored resources.
+const VendorDirectoryName = "vendor"
+
+// Environment variable that overrides the location of the cache.
+const CacheDirectoryEnv = "ANEMOS_CACHE_DIR"
+
+const digestAlgorithm = "sha256"
+
+// Downloads a resource. Resolved is the URL in the lockfile, or empty if the resource is not locked yet.
+// Returns the URL that the resource is downloaded from and its contents.
+type DownloadFunc func(resolved string) (string, []byte, error)
+
+// Fetches remote resources through a cache that is keyed by the digests of the contents, and records
+// the resolved URLs and the digests in the lockfile. Contents that don't match the lockfile are rejected.
+type Fetcher struct {
+	LockFile        *LockFile
+	CacheDirectory  string
+	VendorDirectory string
+
+	// Only uses the vendor directory and the cache, fails for the resources that are not there.
+	Offline bool
+
+	mutex sync.Mutex
+}
+
+// Returns the cache directory, which is shared by all projects of the user.
+func DefaultCacheDirectory() (string, error) {
+	if directory := os.Getenv(CacheDirectoryEnv); directory != "" {
+		return directory, nil
+	}
+
+	directory, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("can't determine the cache directory, set %s: %w", CacheDirectoryEnv, err)
+	}
+
+	return filepath.Join(directory, "anemos", "remote"), nil
+}
+
+// Creates a fetcher that uses the lockfile and the vendor directory in the given project directory.
+func NewFetcher(projectDirectory string, offline bool) (*Fetcher, error) {
+	cacheDirectory, err := DefaultCacheDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	lockFile, err := ReadLockFile(filepath.Join(projectDirectory, LockFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fetcher{
+		LockFile:        lockFile,
+		CacheDirectory:  cacheDirectory,
+		VendorDirectory: filepath.Join(projectDirectory, VendorDirectoryName),
+		Offline:         offline,
+	}, nil
+}
+
+// Returns the contents of the given resource. Locked resources are read from the vendor directory or
+// the cache if possible, otherwise they are downloaded from their resolved URLs and verified against
+// the lockfile. Resources that are not locked are downloaded and added to the lockfile.
+func (fetcher *Fetcher) Fetch(resource *LockEntry, download DownloadFunc) ([]byte, error) {
+	fetcher.mutex.Lock()
+	defer fetcher.mutex.Unlock()
+
+	entry := fetcher.LockFile.Get(resource.Url)
+
+	if entry != nil {
+		if data, ok := fetcher.readBlob(entry.Digest); ok {
+			return data, nil
+		}
+
+		if fetcher.Offline {
+			return nil, fmt.Errorf("%s is not in the cache, run the build without --offline to download it", resource.Url)
+		}
+
+		_, data, err := download(entry.Resolved)
+		if err != nil {
+			return nil, err
+		}
+
+		if digest := Digest(data); digest != entry.Digest {
+			return nil, fmt.Errorf(
+				"digest mismatch for %s downloaded from %s: %s expects %s, got %s",
+				resource.Url, entry.Resolved, LockFileName, entry.Digest, digest)
+		}
+
+		fetcher.writeBlob(entry.Digest, data)
+
+		return data, nil
+	}
+
+	if fetcher.Offline {
+		return nil, fmt.Errorf("%s is not in %s, run the build without --offline to download and lock it", resource.Url, LockFileName)
+	}
+
+	resolved, data, err := download("")
+	if err != nil {
+		return nil, err
+	}
+
+	digest := Digest(data)
+	fetcher.writeBlob(digest, data)
+
+	fetcher.LockFile.Set(&LockEntry{
+		Url:       resource.Url,
+		Kind:      resource.Kind,
+		Resolved:  resolved,
+		Digest:    digest,
+		PlainHttp: resource.PlainHttp,
+	})
+
+	return data, nil
+}
+
+// Writes the lockfile if there are new resources.
+func (fetcher *Fetcher) Save() error {
+	fetcher.mutex.Lock()
+	defer fetcher.mutex.Unlock()
+
+	return fetcher.LockFile.Save()
+}
+
+// Copies the contents of all resources in the lockfile into the vendor directory and removes the vendored
+// files that are no longer in the lockfile. Resources that are not in the cache are downloaded using the
+// given function.
+func (fetcher *Fetcher) Vendor(download func(entry *LockEntry) ([]byte, error)) error {
+	fetcher.mutex.Lock()
+	defer fetcher.mutex.Unlock()
+
+	vendored := map[string]bool{}
+
+	for _, entry := range fetcher.LockFile.Entries() {
+		data, ok := fetcher.readBlob(entry.Digest)
+		if !ok {
+			if fetcher.Offline {
+				return fmt.Errorf("%s is not in the cache, run the command without --offline to download it", entry.Url)
+			}
+
+			var err error
+			data, err = download(entry)
+			if err != nil {
+				return fmt.Errorf("can't download %s: %w", entry.Url, err)
+			}
+
+			if digest := Digest(data); digest != entry.Digest {
+				return fmt.Errorf(
+					"digest mismatch for %s downloaded from %s: %s expects %s, got %s",
+					entry.Url, entry.Resolved, LockFileName, entry.Digest, digest)
+			}
+		}
+
+		path, err := blobPath(fetcher.VendorDirectory, entry.Digest)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFileAtomically(path, data); err != nil {
+			return fmt.Errorf("can't write vendored file %s: %w", path, err)
+		}
+
+		vendored[path] = true
+	}
+
+	blobDirectory := filepath.Join(fetcher.VendorDirectory, digestAlgorithm)
+
+	files, err := os.ReadDir(blobDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't read vendor directory %s: %w", blobDirectory, err)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(blobDirectory, file.Name())
+		if vendored[path] {
+			continue
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("can't remove vendored file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the digest of the given contents in the form of sha256:<hex>.
+func Digest(data []byte) string {
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf("%s:%s", digestAlgorithm, hex.EncodeToString(hash[:]))
+}
+
+// Downloads the contents of the given HTTP URL.
+func DownloadUrl(url string) ([]byte, error) {
+	response, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("can't download %s: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't download %s, status code: %d", url, response.StatusCode)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't read the contents of %s: %w", url, err)
+	}
+
+	return data, nil
+}
+
+// Reads the contents with the given digest from the vendor directory or the cache. Files that are
+// modified after they are written are ignored.
+func (fetcher *Fetcher) readBlob(digest string) ([]byte, bool) {
+	for _, directory := range []string{fetcher.VendorDirectory, fetcher.CacheDirectory} {
+		if directory == "" {
+			continue
+		}
+
+		path, err := blobPath(directory, digest)
+		if err != nil {
+			return nil, false
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		if Digest(data) != digest {
+			slog.Warn("Ignoring ${path}, its contents don't match the digest", slog.String("path", path))
+			continue
+		}
+
+		return data, true
+	}
+
+	return nil, false
+}
+
+// Writes the contents into the cache. Failing to write to the cache doesn't fail the build, the contents
+// are downloaded again next time.
+func (fetcher *Fetcher) writeBlob(digest string, data []byte) {
+	path, err := blobPath(fetcher.CacheDirectory, digest)
+	if err == nil {
+		err = writeFileAtomically(path, data)
+	}
+
+	if err != nil {
+		slog.Warn("Can't write to the cache: ${error}", slog.String("error", err.Error()))
+	}
+}
+
+func blobPath(directory string, digest string) (string, error) {
+	hash, found := strings.CutPrefix(digest, digestAlgorithm+":")
+	if !found || len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid digest %s", digest)
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", fmt.Errorf("invalid digest %s", digest)
+	}
+
+	return filepath.Join(directory, digestAlgorithm, hash), nil
+}
+
+// Writes to a temporary file first, so that concurrent builds never read partially written files.
+func writeFileAtomically(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,137 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	t.Setenv(CacheDirectoryEnv, t.TempDir())
+
+	contents := "module.exports = 1;"
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		writer.Write([]byte(contents))
+	}))
+	defer server.Close()
+
+	url := server.URL + "/module.js"
+	resource := &LockEntry{Url: url, Kind: KindModule}
+	download := func(resolved string) (string, []byte, error) {
+		if resolved == "" {
+			resolved = url
+		}
+
+		data, err := DownloadUrl(resolved)
+		return resolved, data, err
+	}
+
+	projectDirectory := t.TempDir()
+
+	fetch := func(offline bool) (string, error) {
+		fetcher, err := NewFetcher(projectDirectory, offline)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := fetcher.Fetch(resource, download)
+		if err != nil {
+			return "", err
+		}
+
+		if err := fetcher.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		return string(data), nil
+	}
+
+	if _, err := fetch(true); err == nil || !strings.Contains(err.Error(), "is not in anemos.lock") {
+		t.Fatalf("expected offline fetch of an unlocked resource to fail, got %v", err)
+	}
+
+	if data, err := fetch(false); err != nil || data != contents {
+		t.Fatalf("expected %q, got %q, %v", contents, data, err)
+	}
+
+	lockFile, err := ReadLockFile(filepath.Join(projectDirectory, LockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := lockFile.Get(url)
+	if entry == nil || entry.Resolved != url || entry.Digest != Digest([]byte(contents)) {
+		t.Fatalf("unexpected lockfile entry %+v", entry)
+	}
+
+	if data, err := fetch(true); err != nil || data != contents {
+		t.Fatalf("expected %q from the cache, got %q, %v", contents, data, err)
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected the locked resource to be read from the cache, got %d requests", requests)
+	}
+
+	// Remote contents change after the resource is locked.
+	contents = "module.exports = 2;"
+	t.Setenv(CacheDirectoryEnv, t.TempDir())
+
+	if _, err := fetch(false); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+}
+
+func TestVendor(t *testing.T) {
+	t.Setenv(CacheDirectoryEnv, t.TempDir())
+
+	projectDirectory := t.TempDir()
+	contents := []byte("module.exports = 1;")
+	url := "https://example.com/module.js"
+
+	fetcher, err := NewFetcher(projectDirectory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher.LockFile.Set(&LockEntry{Url: url, Kind: KindModule, Resolved: url, Digest: Digest(contents)})
+
+	staleFile := filepath.Join(fetcher.VendorDirectory, digestAlgorithm, "stale")
+	if err := writeFileAtomically(staleFile, []byte("stale")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = fetcher.Vendor(func(entry *LockEntry) ([]byte, error) {
+		return contents, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(staleFile); !os.IsNotExist(err) {
+		t.Fatalf("expected stale vendored file to be removed, got %v", err)
+	}
+
+	// Vendored files are used even if the cache is empty.
+	t.Setenv(CacheDirectoryEnv, t.TempDir())
+
+	offlineFetcher, err := NewFetcher(projectDirectory, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offlineFetcher.LockFile = fetcher.LockFile
+
+	data, err := offlineFetcher.Fetch(&LockEntry{Url: url, Kind: KindModule}, func(string) (string, []byte, error) {
+		t.Fatal("vendored resource must not be downloaded")
+		return "", nil, nil
+	})
+	if err != nil || string(data) != string(contents) {
+		t.Fatalf("expected %q from the vendor directory, got %q, %v", contents, data, err)
+	}
+}
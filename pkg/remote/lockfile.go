@@ -0,0 +1,134 @@
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Name of the lockfile that is written next to the main script of the project.
+const LockFileName = "anemos.lock"
+
+const lockFileVersion = 1
+
+const lockFileHeader = "# This file is generated by anemos. It records the remote charts and modules that are used by the\n" +
+	"# build. Commit it to make the builds reproducible.\n"
+
+const (
+	KindModule    = "module"
+	KindHelmChart = "helm-chart"
+)
+
+// Records the resolved URL and the digest of a remote resource.
+type LockEntry struct {
+	// URL or the reference that is used in the scripts, e.g. oci://registry.example.com/charts/app@^1.2.0.
+	Url string `yaml:"url"`
+
+	// Kind of the resource, module or helm-chart.
+	Kind string `yaml:"kind"`
+
+	// URL that the resource is downloaded from. It is the same as Url unless the resource is resolved,
+	// e.g. a semver range that is resolved to a specific version.
+	Resolved string `yaml:"resolved"`
+
+	// Digest of the contents in the form of sha256:<hex>.
+	Digest string `yaml:"digest"`
+
+	// Set for the OCI registries that are accessed using plain HTTP.
+	PlainHttp bool `yaml:"plainHttp,omitempty"`
+}
+
+type LockFile struct {
+	Path    string
+	entries map[string]*LockEntry
+	changed bool
+}
+
+type lockFileContents struct {
+	Version   int          `yaml:"version"`
+	Resources []*LockEntry `yaml:"resources"`
+}
+
+// Reads the lockfile from the given path. Returns an empty lockfile if the file doesn't exist.
+func ReadLockFile(path string) (*LockFile, error) {
+	lockFile := &LockFile{
+		Path:    path,
+		entries: map[string]*LockEntry{},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lockFile, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read lockfile %s: %w", path, err)
+	}
+
+	contents := &lockFileContents{}
+	if err := yaml.Unmarshal(data, contents); err != nil {
+		return nil, fmt.Errorf("can't parse lockfile %s: %w", path, err)
+	}
+
+	if contents.Version != lockFileVersion {
+		return nil, fmt.Errorf("unsupported lockfile version %d in %s", contents.Version, path)
+	}
+
+	for _, entry := range contents.Resources {
+		lockFile.entries[entry.Url] = entry
+	}
+
+	return lockFile, nil
+}
+
+func (lockFile *LockFile) Get(url string) *LockEntry {
+	return lockFile.entries[url]
+}
+
+func (lockFile *LockFile) Set(entry *LockEntry) {
+	lockFile.entries[entry.Url] = entry
+	lockFile.changed = true
+}
+
+// Returns the entries sorted by their URLs.
+func (lockFile *LockFile) Entries() []*LockEntry {
+	entries := make([]*LockEntry, 0, len(lockFile.entries))
+	for _, entry := range lockFile.entries {
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(x, y *LockEntry) int {
+		return strings.Compare(x.Url, y.Url)
+	})
+
+	return entries
+}
+
+// Writes the lockfile if an entry is added or updated since it is read.
+func (lockFile *LockFile) Save() error {
+	if !lockFile.changed {
+		return nil
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(lockFileHeader)
+
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&lockFileContents{Version: lockFileVersion, Resources: lockFile.Entries()}); err != nil {
+		return fmt.Errorf("can't serialize lockfile: %w", err)
+	}
+
+	if err := os.WriteFile(lockFile.Path, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("can't write lockfile %s: %w", lockFile.Path, err)
+	}
+
+	lockFile.changed = false
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*JUnitTestCase `xml:"testcase"`
+
+	// Total duration of the test cases, written into the time attribute.
+	Duration time.Duration `xml:"-"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+type JUnitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// Adds the given test case to the suite and updates the counts of the suite.
+func (suite *JUnitTestSuite) AddTestCase(testCase *JUnitTestCase) {
+	suite.Tests++
+	if testCase.Failure != nil {
+		suite.Failures++
+	}
+
+	if testCase.Skipped != nil {
+		suite.Skipped++
+	}
+
+	suite.TestCases = append(suite.TestCases, testCase)
+}
+
+// Formats the given duration in seconds as expected by the time attributes.
+func FormatJUnitDuration(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// Writes the given suites into the file at the given path, creates the parent directories if necessary.
+func WriteJUnitReport(path string, suites ...*JUnitTestSuite) error {
+	report := &JUnitTestSuites{
+		Suites: suites,
+	}
+
+	var total time.Duration
+
+	for _, suite := range suites {
+		suite.Time = FormatJUnitDuration(suite.Duration)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		total += suite.Duration
+	}
+
+	report.Time = FormatJUnitDuration(total)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize JUnit report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory for JUnit report %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, append([]byte(xml.Header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report %s: %w", path, err)
+	}
+
+	return nil
+}
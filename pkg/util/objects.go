@@ -0,0 +1,59 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// Converts the given value to the given type by serializing it to JSON.
+func ConvertWithJson(value any, result any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return utiljson.Unmarshal(data, result)
+}
+
+// Reads the objects in the given YAML file. The file may contain multiple documents or lists whose items are
+// returned instead of the list, e.g. the output of "kubectl get crds -o yaml".
+func ReadYamlObjects(path string) ([]map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	objects := []map[string]any{}
+
+	for {
+		object := map[string]any{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		items, ok := object["items"].([]any)
+		if !ok {
+			objects = append(objects, object)
+			continue
+		}
+
+		for _, item := range items {
+			if itemObject, ok := item.(map[string]any); ok {
+				objects = append(objects, itemObject)
+			}
+		}
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadYamlObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "objects.yaml")
+	contents := `
+kind: ConfigMap
+metadata:
+  name: first
+---
+kind: List
+items:
+  - kind: ConfigMap
+    metadata:
+      name: second
+  - kind: Secret
+    metadata:
+      name: third
+`
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := ReadYamlObjects(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, object := range objects {
+		metadata, _ := object["metadata"].(map[string]any)
+		names = append(names, metadata["name"].(string))
+	}
+
+	if len(names) != 3 || names[0] != "first" || names[1] != "second" || names[2] != "third" {
+		t.Errorf("expected the documents and the items of the list, got %v", names)
+	}
+}
+
+func TestConvertWithJson(t *testing.T) {
+	result := struct {
+		Name     string `json:"name"`
+		Replicas int    `json:"replicas"`
+	}{}
+
+	if err := ConvertWithJson(map[string]any{"name": "app", "replicas": 2}, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Name != "app" || result.Replicas != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}